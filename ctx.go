@@ -32,6 +32,7 @@ import (
 	"mime/multipart"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -59,6 +60,7 @@ type Ctx struct {
 	Headers        map[string][]string // Map of request headers
 	Params         map[string]string   // Map of URL parameters
 	Query          map[string]string   // Map of query parameters
+	queryValues    url.Values          // Parsed query string, cached by QueryValues
 	uploadFileSize int64               // Maximum allowed file upload size (bytes)
 	App            *Quick              // Reference to the Quick application instance
 
@@ -396,6 +398,11 @@ func (c *Ctx) File(filePath string) error {
 			return c.Status(StatusNotFound).SendString("File not found")
 		}
 
+		c.SetETag(GenerateETag(data, false))
+		if c.CheckPreconditions() {
+			return nil
+		}
+
 		contentType := http.DetectContentType(data)
 		c.Response.Header().Set("Content-Type", contentType)
 		c.Response.Write(data)
@@ -415,14 +422,19 @@ func (c *Ctx) File(filePath string) error {
 //
 // This function extracts and maps the request body content to the given struct (v).
 // It supports various content types and ensures proper deserialization.
+// After decoding, the struct is checked against its `validate` tags (see Validate).
 //
 // Parameters:
 //   - v: A pointer to the structure where the request body will be bound.
 //
 // Returns:
-//   - error: An error if parsing fails or if the structure is incompatible with the request body.
+//   - error: A 400 *Problem if the body cannot be decoded, a 415 *Problem if the
+//     content type is unsupported, or ValidationErrors (rendered as 422) if validation fails.
 func (c *Ctx) Bind(v interface{}) (err error) {
-	return extractParamsBind(c, v)
+	if err := extractParamsBind(c, v); err != nil {
+		return bindError(err)
+	}
+	return Validate(v)
 }
 
 // BodyParser efficiently unmarshals the request body into the provided struct (v) based on the Content-Type header.
@@ -430,26 +442,27 @@ func (c *Ctx) Bind(v interface{}) (err error) {
 // Supported content-types:
 // - application/json
 // - application/xml, text/xml
+// - any media type registered with Quick.RegisterCodec
 //
 // Parameters:
 //   - v: The target structure to decode the request body into.
 //
+// After decoding, the struct is checked against its `validate` tags (see Validate).
+//
 // Returns:
-//   - error: An error if decoding fails or if the content-type is unsupported.
+//   - error: A 400 *Problem if decoding fails, a 415 *Problem if the content-type is
+//     unsupported, or ValidationErrors (rendered as 422) if validation fails.
 func (c *Ctx) BodyParser(v interface{}) error {
 	contentType := strings.ToLower(c.Request.Header.Get("Content-Type"))
 
-	switch {
-	case strings.HasPrefix(contentType, ContentTypeAppJSON):
-		return json.Unmarshal(c.bodyByte, v)
-
-	case strings.Contains(contentType, ContentTypeAppXML),
-		strings.Contains(contentType, ContentTypeTextXML):
-		return xml.Unmarshal(c.bodyByte, v)
-
-	default:
-		return fmt.Errorf("unsupported content-type: %s", contentType)
+	codec, _ := c.codecs().lookup(contentType)
+	if codec == nil {
+		return bindError(fmt.Errorf("%w: %s", errUnsupportedContentType, contentType))
+	}
+	if err := codec.Unmarshal(c.bodyByte, v); err != nil {
+		return bindError(err)
 	}
+	return Validate(v)
 }
 
 // Param retrieves the value of a URL parameter corresponding to the given key.
@@ -493,12 +506,22 @@ func (c *Ctx) BodyString() string {
 // JSON encodes the provided interface (v) as JSON, sets the Content-Type header,
 // and writes the response efficiently using buffer pooling.
 //
+// When RegisterCodec replaces the application/json codec, it is used instead of encoding/json.
+//
 // Parameters:
 //   - v: The data structure to encode as JSON.
 //
 // Returns:
 //   - error: An error if JSON encoding fails or if writing the response fails.
 func (c *Ctx) JSON(v interface{}) error {
+	if codec := c.customCodec(ContentTypeAppJSON); codec != nil {
+		b, err := codec.Marshal(v)
+		if err != nil {
+			return err
+		}
+		return c.writeResponse(b)
+	}
+
 	buf := acquireJSONBuffer()
 	defer releaseJSONBuffer(buf)
 
@@ -544,6 +567,18 @@ func (c *Ctx) JSONIN(v interface{}, params ...string) error {
 	buf := acquireJSONBuffer()
 	defer releaseJSONBuffer(buf)
 
+	// A custom JSON codec encodes, encoding/json only indents its output
+	if codec := c.customCodec(ContentTypeAppJSON); codec != nil {
+		b, err := codec.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if err := json.Indent(buf, b, prefix, indent); err != nil {
+			return err
+		}
+		return c.writeResponse(buf.Bytes())
+	}
+
 	// Exemplo com JSON:
 	enc := json.NewEncoder(buf)
 	enc.SetIndent(prefix, indent)
@@ -563,12 +598,22 @@ func (c *Ctx) JSONIN(v interface{}, params ...string) error {
 // XML serializes the given value to XML and writes it to the HTTP response.
 // It avoids unnecessary memory allocations by using buffer pooling and ensures that no extra newline is appended.
 //
+// When RegisterCodec replaces the application/xml codec, it is used instead of encoding/xml.
+//
 // Parameters:
 //   - v: The data structure to encode as XML.
 //
 // Returns:
 //   - error: An error if XML encoding fails or if writing to the ResponseWriter fails.
 func (c *Ctx) XML(v interface{}) error {
+	if codec := c.customCodec(ContentTypeAppXML); codec != nil {
+		b, err := codec.Marshal(v)
+		if err != nil {
+			return err
+		}
+		return c.writeResponse(b)
+	}
+
 	buf := acquireXMLBuffer()
 	defer releaseXMLBuffer(buf)
 
@@ -715,20 +760,6 @@ func (c *Ctx) Append(key, value string) {
 	c.Response.Header().Add(key, value)
 }
 
-// Accepts sets the "Accept" header in the HTTP response.
-//
-// This function assigns a specific accept type to the HTTP response header "Accept."
-//
-// Parameters:
-//   - acceptType: The MIME type to set in the "Accept" header.
-//
-// Returns:
-//   - *Ctx: The current context instance for method chaining.
-func (c *Ctx) Accepts(acceptType string) *Ctx {
-	c.Response.Header().Set("Accept", acceptType)
-	return c
-}
-
 // Status sets the HTTP status code of the response.
 //
 // This function assigns a specific HTTP status code to the response.
@@ -858,41 +889,56 @@ func (c *Ctx) FormFiles(fieldName string) ([]*UploadedFile, error) {
 	var uploadedFiles []*UploadedFile
 
 	for _, handler := range files {
-		// Open file
-		file, err := handler.Open()
+		file, err := newUploadedFile(handler)
 		if err != nil {
-			return nil, errors.New("failed to open file: " + err.Error())
-		}
-		defer file.Close()
-
-		// Read file content into memory
-		var buf bytes.Buffer
-		if _, err := io.Copy(&buf, file); err != nil {
-			return nil, errors.New("failed to read file into buffer")
+			return nil, err
 		}
+		uploadedFiles = append(uploadedFiles, file)
+	}
 
-		// reset  multipart.File
-		// Create a reusable copy of the file
-		// that implements multipart.File correctly
-		fileCopy := &fileWrapper{bytes.NewReader(buf.Bytes())}
+	return uploadedFiles, nil
+}
 
-		// Detect content type
-		fileContentType := detectUploadedFileContentType(handler, buf.Bytes())
+// newUploadedFile reads a multipart file into memory and describes it as an UploadedFile.
+//
+// Parameters:
+//   - handler: The multipart file header of the uploaded part.
+//
+// Returns:
+//   - *UploadedFile: The file content and its metadata.
+//   - error: An error if the file cannot be opened or read.
+func newUploadedFile(handler *multipart.FileHeader) (*UploadedFile, error) {
+	// Open file
+	file, err := handler.Open()
+	if err != nil {
+		return nil, errors.New("failed to open file: " + err.Error())
+	}
+	defer file.Close()
 
-		// Append file details
-		uploadedFiles = append(uploadedFiles, &UploadedFile{
-			File:      fileCopy,
-			Multipart: handler,
-			Info: FileInfo{
-				Filename:    handler.Filename,
-				Size:        handler.Size,
-				ContentType: fileContentType,
-				Bytes:       buf.Bytes(),
-			},
-		})
+	// Read file content into memory
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, file); err != nil {
+		return nil, errors.New("failed to read file into buffer")
 	}
 
-	return uploadedFiles, nil
+	// reset  multipart.File
+	// Create a reusable copy of the file
+	// that implements multipart.File correctly
+	fileCopy := &fileWrapper{bytes.NewReader(buf.Bytes())}
+
+	// Detect content type
+	fileContentType := detectUploadedFileContentType(handler, buf.Bytes())
+
+	return &UploadedFile{
+		File:      fileCopy,
+		Multipart: handler,
+		Info: FileInfo{
+			Filename:    handler.Filename,
+			Size:        handler.Size,
+			ContentType: fileContentType,
+			Bytes:       buf.Bytes(),
+		},
+	}, nil
 }
 
 func detectUploadedFileContentType(handler *multipart.FileHeader, fileBytes []byte) string {
@@ -1254,7 +1300,11 @@ func (c *Ctx) Next() error {
 
 	// No more handlers — if no response has been written, send 404
 	if !c.responseWritten() {
-		NotFound(c.Response, c.Request)
+		if c.App != nil {
+			c.App.notFound(c)
+		} else {
+			NotFound(c.Response, c.Request)
+		}
 	}
 
 	return nil
@@ -511,29 +511,21 @@ func ExampleCtx_Accepts() {
 	q := New()
 
 	q.Get("/accepts", func(c *Ctx) error {
-		// Set Accept header
-		c.Accepts("application/json")
-		return c.String("Accept Set")
+		// Choose the best representation from the Accept header
+		return c.String(c.Accepts("application/json", "text/html"))
 	})
 
 	// Simulate a GET request
 	res, _ := q.Qtest(QuickTestOptions{
-		Method: MethodGet,
-		URI:    "/accepts",
+		Method:  MethodGet,
+		URI:     "/accepts",
+		Headers: map[string]string{"Accept": "text/html, application/json;q=0.8"},
 	})
 
-	if err := res.AssertString("Accept Set"); err != nil {
-		fmt.Println("Body error:", err)
-	}
-
-	fmt.Println(res.Response().Header.Get("Accept"))
-
 	fmt.Println(res.BodyStr())
 
 	// Output:
-	// application/json
-	// Accept Set
-
+	// text/html
 }
 
 // This function is named ExampleCtx_Status()
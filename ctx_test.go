@@ -814,52 +814,125 @@ func TestCtx_Append(t *testing.T) {
 }
 
 // TestCtx_Accepts ensures that Ctx.Accepts correctly evaluates the Accept header
-// to determine if the content type is acceptable.
+// to select the best offer, honoring q-values and wildcards.
 //
 // To run:
 //
 //	$ go test -v -run ^TestCtx_Accepts$
 func TestCtx_Accepts(t *testing.T) {
-	type fields struct {
-		Response  http.ResponseWriter
-		Request   *http.Request
-		resStatus int
-		bodyByte  []byte
-		JsonStr   string
-		Headers   map[string][]string
-		Params    map[string]string
-		Query     map[string]string
-	}
-	type args struct {
-		acceptType string
-	}
 	tests := []struct {
 		name   string
-		fields fields
-		args   args
-		want   *Ctx
+		accept string
+		offers []string
+		want   string
 	}{
-		// TODO: Add test cases.
+		{"empty header picks first offer", "", []string{"application/json", "text/html"}, "application/json"},
+		{"exact match", "text/html", []string{"application/json", "text/html"}, "text/html"},
+		{"q-values", "application/json;q=0.5, text/html", []string{"application/json", "text/html"}, "text/html"},
+		{"subtype wildcard", "text/*", []string{"application/json", "text/plain"}, "text/plain"},
+		{"any wildcard keeps offer order", "*/*", []string{"application/xml", "application/json"}, "application/xml"},
+		{"specific range overrides wildcard", "*/*, application/json;q=0", []string{"application/json", "text/html"}, "text/html"},
+		{"extension offers", "text/html", []string{"json", "html"}, "html"},
+		{"q=0 excludes", "application/json;q=0", []string{"application/json"}, ""},
+		{"no match", "image/png", []string{"application/json"}, ""},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := &Ctx{
-				Response:  tt.fields.Response,
-				Request:   tt.fields.Request,
-				resStatus: tt.fields.resStatus,
-				bodyByte:  tt.fields.bodyByte,
-				JsonStr:   tt.fields.JsonStr,
-				Headers:   tt.fields.Headers,
-				Params:    tt.fields.Params,
-				Query:     tt.fields.Query,
+			req := httptest.NewRequest(MethodGet, "/", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
 			}
-			if got := c.Accepts(tt.args.acceptType); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("Ctx.Accepts() = %v, want %v", got, tt.want)
+			c := &Ctx{Request: req}
+			if got := c.Accepts(tt.offers...); got != tt.want {
+				t.Errorf("Ctx.Accepts() = %q, want %q", got, tt.want)
 			}
 		})
 	}
 }
 
+// TestCtx_AcceptsTokens verifies negotiation of encodings, languages and charsets.
+//
+// To run:
+//
+//	$ go test -v -run ^TestCtx_AcceptsTokens$
+func TestCtx_AcceptsTokens(t *testing.T) {
+	req := httptest.NewRequest(MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip;q=0.8, br, *;q=0.1")
+	req.Header.Set("Accept-Language", "pt-BR, en;q=0.7")
+	req.Header.Set("Accept-Charset", "iso-8859-1;q=0.5, utf-8")
+	c := &Ctx{Request: req}
+
+	if got := c.AcceptsEncodings("gzip", "br"); got != "br" {
+		t.Errorf("AcceptsEncodings() = %q, want br", got)
+	}
+	if got := c.AcceptsEncodings("zstd"); got != "zstd" {
+		t.Errorf("AcceptsEncodings() with wildcard = %q, want zstd", got)
+	}
+	if got := c.AcceptsLanguages("en-US", "es"); got != "en-US" {
+		t.Errorf("AcceptsLanguages() = %q, want en-US", got)
+	}
+	if got := c.AcceptsLanguages("pt-br", "en"); got != "pt-br" {
+		t.Errorf("AcceptsLanguages() = %q, want pt-br", got)
+	}
+	if got := c.AcceptsCharsets("iso-8859-1", "utf-8"); got != "utf-8" {
+		t.Errorf("AcceptsCharsets() = %q, want utf-8", got)
+	}
+}
+
+// TestCtx_Format verifies that Format dispatches to the best renderer,
+// falls back to "default" and answers 406 otherwise.
+//
+// To run:
+//
+//	$ go test -v -run ^TestCtx_Format$
+func TestCtx_Format(t *testing.T) {
+	q := New()
+	q.Get("/user", func(c *Ctx) error {
+		return c.Format(map[string]func() error{
+			"application/json": func() error { return c.JSON(M{"name": "quick"}) },
+			"text/html":        func() error { return c.SendString("<b>quick</b>") },
+		})
+	})
+	q.Get("/default", func(c *Ctx) error {
+		return c.Format(map[string]func() error{
+			"text/html": func() error { return c.SendString("<b>quick</b>") },
+			"default":   func() error { return c.SendString("quick") },
+		})
+	})
+
+	tests := []struct {
+		path   string
+		accept string
+		code   int
+		ctype  string
+		body   string
+	}{
+		{"/user", "text/html, application/json;q=0.9", StatusOK, "text/html", "<b>quick</b>"},
+		{"/user", "application/*", StatusOK, "application/json", `{"name":"quick"}`},
+		{"/user", "image/png", StatusNotAcceptable, ContentTypeProblemJSON, ""},
+		{"/default", "image/png", StatusOK, "", "quick"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(MethodGet, tt.path, nil)
+		req.Header.Set("Accept", tt.accept)
+		rec := httptest.NewRecorder()
+		q.ServeHTTP(rec, req)
+
+		if rec.Code != tt.code {
+			t.Errorf("%s %s: expected %d, got %d", tt.path, tt.accept, tt.code, rec.Code)
+		}
+		if tt.ctype != "" && rec.Header().Get("Content-Type") != tt.ctype {
+			t.Errorf("%s %s: expected Content-Type %q, got %q", tt.path, tt.accept, tt.ctype, rec.Header().Get("Content-Type"))
+		}
+		if tt.body != "" && rec.Body.String() != tt.body {
+			t.Errorf("%s %s: expected body %q, got %q", tt.path, tt.accept, tt.body, rec.Body.String())
+		}
+		if rec.Header().Get("Vary") != "Accept" {
+			t.Errorf("%s %s: expected Vary: Accept", tt.path, tt.accept)
+		}
+	}
+}
+
 // TestCtx_Status validates that calling Ctx.Status sets the correct status code for the response.
 //
 // To run:
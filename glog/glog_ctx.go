@@ -153,6 +153,35 @@ func (b *CtxBuilder) Todo() *CtxBuilder {
 	return b.Background()
 }
 
+// SetCtx returns a copy of parent carrying the key-value pair, keeping the
+// fields already injected so GetCtxAll and Entry.Ctx return all of them.
+// Unlike CreateCtx, it derives from an existing context, such as the one of
+// an HTTP request. Empty keys or values return parent unchanged.
+//
+// Example:
+//
+//	ctx := glog.SetCtx(r.Context(), "request_id", id)
+func SetCtx(parent context.Context, key, value string) context.Context {
+	if parent == nil {
+		parent = emptyContext
+	}
+	if key == "" || value == "" {
+		return parent
+	}
+
+	prior, _ := parent.Value(internalKeysKey).([]string)
+	keys := make([]string, 0, len(prior)+1)
+	for _, k := range prior {
+		if k != key {
+			keys = append(keys, k)
+		}
+	}
+	keys = append(keys, key)
+
+	ctx := context.WithValue(parent, getCtxKey(key), value)
+	return context.WithValue(ctx, internalKeysKey, keys)
+}
+
 // GetCtx retrieves the string value for the given key from the context.
 // Returns an empty string if the context is nil or key is not found.
 func GetCtx(ctx context.Context, keyName ...string) string {
@@ -192,3 +221,23 @@ func GetCtxAll(ctx context.Context) map[string]string {
 
 	return result
 }
+
+// Ctx adds every field injected into ctx with CreateCtx or SetCtx to the
+// log entry, such as the request ID set by middleware/requestid.
+// A nil context adds nothing.
+//
+// Example:
+//
+//	logger.Info().Ctx(c.Ctx()).Msg("user created").Send()
+func (e *Entry) Ctx(ctx context.Context) *Entry {
+	if ctx == nil {
+		return e
+	}
+	keys, _ := ctx.Value(internalKeysKey).([]string)
+	for _, k := range keys {
+		if v, ok := ctx.Value(getCtxKey(k)).(string); ok {
+			e.fields = append(e.fields, Field{key: k, val: v})
+		}
+	}
+	return e
+}
@@ -1,6 +1,7 @@
 package glog_test
 
 import (
+	"bytes"
 	"context"
 	"strings"
 	"testing"
@@ -119,3 +120,51 @@ func TestNewCtx_GetCtxAll(t *testing.T) {
 		t.Errorf("Expected X-Session-ID to be 'sess-789', got '%s'", values["X-Session-ID"])
 	}
 }
+
+// TestSetCtx verifies that SetCtx derives from a parent context, keeps the
+// fields already injected and replaces a field set twice.
+//
+// To run:
+//
+//	go test -v -run ^TestSetCtx$
+func TestSetCtx(t *testing.T) {
+	parent, cancel := glog.CreateCtx().Set("TraceID", "abc-123").Build()
+	defer cancel()
+
+	ctx := glog.SetCtx(parent, "request_id", "req-1")
+	ctx = glog.SetCtx(ctx, "request_id", "req-2")
+	ctx = glog.SetCtx(ctx, "empty", "")
+
+	values := glog.GetCtxAll(ctx)
+	if len(values) != 2 || values["TraceID"] != "abc-123" || values["request_id"] != "req-2" {
+		t.Errorf("Expected TraceID and request_id, got %v", values)
+	}
+	if glog.GetCtx(parent, "request_id") != "" {
+		t.Errorf("Expected the parent context to be unchanged")
+	}
+	if glog.GetCtx(glog.SetCtx(nil, "k", "v"), "k") != "v" {
+		t.Errorf("Expected SetCtx to accept a nil parent")
+	}
+}
+
+// TestEntryCtx verifies that Entry.Ctx adds the context fields to the log entry.
+//
+// To run:
+//
+//	go test -v -run ^TestEntryCtx$
+func TestEntryCtx(t *testing.T) {
+	var buf bytes.Buffer
+	logger := glog.Set(glog.Config{
+		Format: "json",
+		Writer: &buf,
+		Level:  glog.DEBUG,
+	})
+
+	ctx := glog.SetCtx(context.Background(), "request_id", "req-1")
+	logger.Info().Ctx(ctx).Ctx(nil).Str("status", "ok").Msg("done").Send()
+	out := buf.String()
+
+	if !strings.Contains(out, `"request_id":"req-1"`) || !strings.Contains(out, `"status":"ok"`) {
+		t.Errorf("Expected the context fields in output: %s", out)
+	}
+}
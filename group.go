@@ -170,7 +170,9 @@ func createAndRegisterRoute(g *Group, method, pattern, compiledPattern, params s
 		Method:  method,
 		Group:   g.prefix,
 	}
-	g.quick.appendRoute(&route)
+	if !g.quick.appendRoute(&route) {
+		return
+	}
 
 	// FIX: Adjust path in mux to maintain compatibility with tests
 	if method == http.MethodGet {
@@ -0,0 +1,77 @@
+// Package lookup parses the lists of request locations used by middlewares to
+// find a credential, such as "header:Authorization,query:token".
+package lookup
+
+import (
+	"strings"
+
+	"github.com/jeffotoni/quick"
+)
+
+// Extractor reads a value from one location of the request, or returns "".
+type Extractor func(c *quick.Ctx) string
+
+// Source builds the Extractor of a named location, e.g. a header name.
+type Source func(name string) Extractor
+
+// Parse builds the extractors of lookup, a comma-separated list of
+// "source:name" entries, in order. Entries whose source is not in sources,
+// or without a name, are ignored.
+func Parse(lookup string, sources map[string]Source) []Extractor {
+	var extractors []Extractor
+	for _, part := range strings.Split(lookup, ",") {
+		source, name, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok || name == "" {
+			continue
+		}
+		if build, ok := sources[strings.ToLower(source)]; ok {
+			extractors = append(extractors, build(name))
+		}
+	}
+	return extractors
+}
+
+// Extract returns the first value found by extractors, or "".
+func Extract(c *quick.Ctx, extractors []Extractor) string {
+	for _, extract := range extractors {
+		if v := extract(c); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Header reads a request header. For the Authorization header, only the
+// credentials of scheme (e.g. "Bearer") are returned, unless scheme is empty.
+func Header(scheme string) Source {
+	return func(name string) Extractor {
+		auth := scheme != "" && strings.EqualFold(name, "Authorization")
+		return func(c *quick.Ctx) string {
+			v := c.Request.Header.Get(name)
+			if !auth {
+				return v
+			}
+			if len(v) > len(scheme) && strings.EqualFold(v[:len(scheme)], scheme) && v[len(scheme)] == ' ' {
+				return strings.TrimSpace(v[len(scheme)+1:])
+			}
+			return ""
+		}
+	}
+}
+
+// Query reads a query string parameter.
+func Query(name string) Extractor {
+	return func(c *quick.Ctx) string {
+		return c.Request.URL.Query().Get(name)
+	}
+}
+
+// Cookie reads a cookie.
+func Cookie(name string) Extractor {
+	return func(c *quick.Ctx) string {
+		if ck, err := c.Request.Cookie(name); err == nil {
+			return ck.Value
+		}
+		return ""
+	}
+}
@@ -9,6 +9,7 @@
 //   - Secure credential validation
 //   - WWW-Authenticate header with realm support
 //   - Clear unauthorized responses
+//   - Multiple users, hashed passwords and htpasswd files with New
 //
 // Example of how to use middleware in Quick
 //
@@ -27,9 +28,13 @@
 package basicauth
 
 import (
+	"context"
 	"encoding/base64"
 	"net/http"
+	"strconv"
 	"strings"
+
+	"github.com/jeffotoni/quick"
 )
 
 // BasicAuth creates middleware that enforces HTTP Basic Authentication.
@@ -47,8 +52,7 @@ import (
 //   protectedHandler := authMiddleware(yourHandler)
 //
 // Note:
-// For more advanced configuration (multiple users, custom responses),
-// consider implementing a Config struct as shown in more complete examples.
+// For multiple users, hashed passwords or custom responses, use New.
 
 func BasicAuth(username, password string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -86,3 +90,126 @@ func BasicAuth(username, password string) func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// Config defines the configuration options for the BasicAuth middleware.
+type Config struct {
+	// Users maps usernames to passwords. Values may be plain text or hashes
+	// in a registered format: Apache MD5 ($apr1$), MD5 crypt ($1$), SHA-256
+	// crypt ($5$), SHA-512 crypt ($6$), {SHA}, bcrypt and Argon2 once
+	// middleware/basicauth/xcrypt is imported, or formats added with
+	// RegisterVerifier.
+	Users map[string]string
+
+	// Authorizer validates credentials not accepted by Users, e.g. against a
+	// database. It must compare secrets in constant time.
+	Authorizer func(user, pass string, c *quick.Ctx) bool
+
+	// Realm is sent in the WWW-Authenticate header. Default is "Restricted".
+	Realm string
+
+	// Next is a function that determines whether to skip the middleware.
+	Next func(c *quick.Ctx) bool
+
+	// Unauthorized writes the response for rejected requests. The default
+	// returns 401 Unauthorized with a WWW-Authenticate challenge.
+	Unauthorized func(c *quick.Ctx) error
+}
+
+// contextKey is the request context key holding the authenticated username.
+type contextKey struct{}
+
+// New creates a BasicAuth middleware with multiple users, hashed passwords
+// and a custom authorizer.
+//
+// New panics if a password in Users uses a hash format that is not registered,
+// so that a misconfiguration is detected at startup.
+//
+// Parameters:
+//   - config: The middleware configuration.
+//
+// Returns:
+//   - func(http.Handler) http.Handler: The middleware, usable with q.Use and Group.Use.
+//
+// Example Usage:
+//
+//	users, _ := basicauth.LoadHtpasswd(".htpasswd")
+//	q.Use(basicauth.New(basicauth.Config{
+//	    Users: users,
+//	    Realm: "Admin",
+//	}))
+//
+//	q.Get("/admin", func(c *quick.Ctx) error {
+//	    return c.String("hello " + basicauth.Username(c))
+//	})
+func New(config Config) func(http.Handler) http.Handler {
+	cfg := config
+	if cfg.Realm == "" {
+		cfg.Realm = "Restricted"
+	}
+	challenge := `Basic realm=` + strconv.Quote(cfg.Realm) + `, charset="UTF-8"`
+	if cfg.Unauthorized == nil {
+		cfg.Unauthorized = func(c *quick.Ctx) error {
+			c.Set("WWW-Authenticate", challenge)
+			return c.Status(quick.StatusUnauthorized).String(http.StatusText(http.StatusUnauthorized))
+		}
+	}
+
+	users := make(map[string]Verifier, len(cfg.Users))
+	// decoy is checked for unknown usernames, so that the response time
+	// does not reveal which usernames exist
+	var decoy string
+	for user, stored := range cfg.Users {
+		verify, ok := verifierFor(stored)
+		if !ok {
+			panic("basicauth: unsupported password hash for user " + strconv.Quote(user) +
+				"; import middleware/basicauth/xcrypt for bcrypt and Argon2, or see RegisterVerifier")
+		}
+		users[user], decoy = verify, user
+	}
+
+	authenticate := func(user, pass string, c *quick.Ctx) bool {
+		if verify, ok := users[user]; ok {
+			if verify(cfg.Users[user], pass) {
+				return true
+			}
+		} else if decoy != "" {
+			users[decoy](cfg.Users[decoy], pass)
+		}
+		return cfg.Authorizer != nil && cfg.Authorizer(user, pass, c)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Create a Quick context for Next, Authorizer and Unauthorized
+			c := &quick.Ctx{Response: w, Request: r}
+
+			// Skip middleware if Next returns true
+			if cfg.Next != nil && cfg.Next(c) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			user, pass, ok := r.BasicAuth()
+			if !ok || !authenticate(user, pass, c) {
+				if err := cfg.Unauthorized(c); err != nil {
+					quick.HandleError(c, err)
+				}
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, user)))
+		})
+	}
+}
+
+// Username returns the username authenticated by the middleware created with New.
+//
+// Parameters:
+//   - c: The Quick context.
+//
+// Returns:
+//   - string: The username, or "" if the request was not authenticated by New.
+func Username(c *quick.Ctx) string {
+	user, _ := c.Request.Context().Value(contextKey{}).(string)
+	return user
+}
@@ -0,0 +1,106 @@
+package basicauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jeffotoni/quick"
+)
+
+// TestNew verifies multiple users, hashed passwords, the Authorizer,
+// the realm, Next and the username exposed to handlers.
+//
+// go test -v -failfast -count=1 -run ^TestNew$
+func TestNew(t *testing.T) {
+	q := quick.New()
+	q.Use(New(Config{
+		Users: map[string]string{
+			"admin": "$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5",
+			"guest": "guest",
+		},
+		Authorizer: func(user, pass string, c *quick.Ctx) bool {
+			return user == "svc" && pass == "token"
+		},
+		Realm: "Admin Area",
+		Next: func(c *quick.Ctx) bool {
+			return c.Path() == "/health"
+		},
+	}))
+	q.Get("/me", func(c *quick.Ctx) error {
+		return c.Status(quick.StatusOK).String(Username(c))
+	})
+	q.Get("/health", func(c *quick.Ctx) error {
+		return c.Status(quick.StatusOK).String("up")
+	})
+
+	tests := []struct {
+		name string
+		path string
+		user string
+		pass string
+		code int
+		body string
+	}{
+		{"hashed", "/me", "admin", "Hello world!", http.StatusOK, "admin"},
+		{"plain", "/me", "guest", "guest", http.StatusOK, "guest"},
+		{"authorizer", "/me", "svc", "token", http.StatusOK, "svc"},
+		{"wrong_password", "/me", "admin", "1234", http.StatusUnauthorized, ""},
+		{"unknown_user", "/me", "root", "Hello world!", http.StatusUnauthorized, ""},
+		{"no_credentials", "/me", "", "", http.StatusUnauthorized, ""},
+		{"skipped", "/health", "", "", http.StatusOK, "up"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(quick.MethodGet, tt.path, nil)
+			if tt.user != "" {
+				req.SetBasicAuth(tt.user, tt.pass)
+			}
+			rec := httptest.NewRecorder()
+			q.ServeHTTP(rec, req)
+			if rec.Code != tt.code {
+				t.Fatalf("expected %d, got %d", tt.code, rec.Code)
+			}
+			if tt.body != "" && rec.Body.String() != tt.body {
+				t.Errorf("expected %q, got %q", tt.body, rec.Body.String())
+			}
+			if tt.code == http.StatusUnauthorized {
+				if got := rec.Header().Get("WWW-Authenticate"); got != `Basic realm="Admin Area", charset="UTF-8"` {
+					t.Errorf("unexpected challenge: %q", got)
+				}
+			}
+		})
+	}
+}
+
+// TestNewUnsupportedHash verifies that New rejects unregistered hash formats at startup.
+//
+// go test -v -failfast -count=1 -run ^TestNewUnsupportedHash$
+func TestNewUnsupportedHash(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected New to panic")
+		}
+	}()
+	New(Config{Users: map[string]string{"admin": "$argon2id$v=19$m=65536,t=3,p=4$c2FsdA$aGFzaA"}})
+}
+
+// TestRegisterVerifier verifies that custom hash formats can be plugged in.
+//
+// go test -v -failfast -count=1 -run ^TestRegisterVerifier$
+func TestRegisterVerifier(t *testing.T) {
+	RegisterVerifier("$test$", func(hash, password string) bool {
+		return hash == "$test$"+password
+	})
+	h := New(Config{Users: map[string]string{"admin": "$test$pw"}})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(quick.MethodGet, "/", nil)
+	req.SetBasicAuth("admin", "pw")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", rec.Code)
+	}
+}
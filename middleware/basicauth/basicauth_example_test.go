@@ -7,6 +7,8 @@
 package basicauth
 
 import (
+	"encoding/base64"
+	"fmt"
 	"log"
 
 	"github.com/jeffotoni/quick"
@@ -57,3 +59,36 @@ func ExampleBasicAuth_withGroup() {
 	// Start server
 	log.Fatal(q.Listen("0.0.0.0:8080"))
 }
+
+// This function is named ExampleNew()
+// it with the Examples type.
+func ExampleNew() {
+	q := quick.New()
+
+	// Multiple users; passwords may be hashed (here with SHA-256 crypt)
+	q.Use(New(Config{
+		Users: map[string]string{
+			"admin": "$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5", // "Hello world!"
+			"guest": "guest",
+		},
+		Realm: "Admin",
+	}))
+
+	q.Get("/protected", func(c *quick.Ctx) error {
+		return c.Status(quick.StatusOK).String("hello " + Username(c))
+	})
+
+	auth := base64.StdEncoding.EncodeToString([]byte("admin:Hello world!"))
+	resp, err := q.Qtest(quick.QuickTestOptions{
+		Method:  quick.MethodGet,
+		URI:     "/protected",
+		Headers: map[string]string{"Authorization": "Basic " + auth},
+	})
+	if err != nil {
+		fmt.Println("Test execution error:", err)
+		return
+	}
+	fmt.Println(resp.StatusCode(), resp.BodyStr())
+
+	// Output: 200 hello admin
+}
@@ -0,0 +1,415 @@
+package basicauth
+
+import (
+	"bufio"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Verifier reports whether password matches a stored hash.
+type Verifier func(hash, password string) bool
+
+// verifiers maps hash prefixes to their verifier.
+var (
+	verifiersMu sync.RWMutex
+	verifiers   = map[string]Verifier{
+		"$apr1$": verifyMD5Crypt,
+		"$1$":    verifyMD5Crypt,
+		"$5$":    verifySHACrypt,
+		"$6$":    verifySHACrypt,
+		"{SHA}":  verifySHA1,
+	}
+)
+
+// RegisterVerifier adds support for a password hash format, identified by its prefix.
+//
+// Apache MD5 ($apr1$, the default of htpasswd), MD5 crypt ($1$), SHA-256 crypt
+// ($5$), SHA-512 crypt ($6$) and {SHA} are built in. bcrypt ($2y$, written by
+// htpasswd -B) and Argon2 need golang.org/x/crypto, so they live in the
+// middleware/basicauth/xcrypt module, which registers them when imported.
+// Register verifiers before loading an htpasswd file, which rejects unknown
+// formats.
+//
+// Parameters:
+//   - prefix: The hash prefix (e.g. "$scrypt$").
+//   - verify: The verification function.
+//
+// Example Usage:
+//
+//	import _ "github.com/jeffotoni/quick/middleware/basicauth/xcrypt" // bcrypt and Argon2
+//
+//	basicauth.RegisterVerifier("$plain$", func(hash, password string) bool {
+//	    return subtle.ConstantTimeCompare([]byte(hash[len("$plain$"):]), []byte(password)) == 1
+//	})
+func RegisterVerifier(prefix string, verify Verifier) {
+	verifiersMu.Lock()
+	verifiers[prefix] = verify
+	verifiersMu.Unlock()
+}
+
+// verifierFor returns the verifier of a stored password. Values that do not
+// look like a hash are compared as plain text; hashes of an unregistered
+// format return ok == false.
+func verifierFor(stored string) (Verifier, bool) {
+	if !strings.HasPrefix(stored, "$") && !strings.HasPrefix(stored, "{") {
+		return verifyPlain, true
+	}
+	verifiersMu.RLock()
+	defer verifiersMu.RUnlock()
+	for prefix, v := range verifiers {
+		if strings.HasPrefix(stored, prefix) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// CheckPassword reports whether password matches stored, which is either a
+// hash in a registered format or a plain-text password.
+//
+// Parameters:
+//   - stored: The stored hash or plain-text password.
+//   - password: The password sent by the client.
+//
+// Returns:
+//   - bool: true if they match; false for hashes of an unregistered format.
+func CheckPassword(stored, password string) bool {
+	verify, ok := verifierFor(stored)
+	return ok && verify(stored, password)
+}
+
+// HashPassword hashes password with SHA-256 crypt and a random salt.
+//
+// The result can be used in Config.Users or in an htpasswd file.
+//
+// Parameters:
+//   - password: The password to hash.
+//
+// Returns:
+//   - string: The hash, "$5$rounds=N$salt$digest".
+//   - error: An error from the random source.
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	for i, b := range salt {
+		salt[i] = cryptAlphabet[b&0x3f]
+	}
+	return shaCrypt(sha256.New, shaCrypt256Order, "$5$", password, string(salt), 100000, true), nil
+}
+
+// verifyPlain compares plain-text passwords in constant time.
+func verifyPlain(stored, password string) bool {
+	a, b := sha256.Sum256([]byte(stored)), sha256.Sum256([]byte(password))
+	return subtle.ConstantTimeCompare(a[:], b[:]) == 1
+}
+
+// verifySHA1 checks the {SHA} format of htpasswd -s.
+func verifySHA1(stored, password string) bool {
+	sum := sha1.Sum([]byte(password))
+	want := "{SHA}" + base64.StdEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(stored), []byte(want)) == 1
+}
+
+// verifySHACrypt checks SHA-256 ($5$) and SHA-512 ($6$) crypt hashes.
+func verifySHACrypt(stored, password string) bool {
+	newHash, order, prefix := sha256.New, shaCrypt256Order, "$5$"
+	if strings.HasPrefix(stored, "$6$") {
+		newHash, order, prefix = sha512.New, shaCrypt512Order, "$6$"
+	}
+
+	rest := stored[len(prefix):]
+	rounds, custom := 5000, false
+	if r, ok := strings.CutPrefix(rest, "rounds="); ok {
+		n, after, found := strings.Cut(r, "$")
+		v, err := strconv.Atoi(n)
+		if !found || err != nil {
+			return false
+		}
+		rounds, custom, rest = min(max(v, 1000), 999999999), true, after
+	}
+	salt, _, ok := strings.Cut(rest, "$")
+	if !ok {
+		return false
+	}
+	got := shaCrypt(newHash, order, prefix, password, salt, rounds, custom)
+	return subtle.ConstantTimeCompare([]byte(got), []byte(stored)) == 1
+}
+
+// verifyMD5Crypt checks Apache MD5 ($apr1$) and MD5 crypt ($1$) hashes.
+func verifyMD5Crypt(stored, password string) bool {
+	prefix := "$1$"
+	if strings.HasPrefix(stored, "$apr1$") {
+		prefix = "$apr1$"
+	}
+	salt, _, ok := strings.Cut(stored[len(prefix):], "$")
+	if !ok {
+		return false
+	}
+	got := md5Crypt(prefix, password, salt)
+	return subtle.ConstantTimeCompare([]byte(got), []byte(stored)) == 1
+}
+
+// md5Crypt implements the MD5-crypt algorithm by Poul-Henning Kamp, used by
+// crypt(3) for $1$ hashes and by Apache with the "$apr1$" magic.
+func md5Crypt(prefix, password, salt string) string {
+	if len(salt) > 8 {
+		salt = salt[:8]
+	}
+	pw, s := []byte(password), []byte(salt)
+
+	// Alternate sum
+	h := md5.New()
+	h.Write(pw)
+	h.Write(s)
+	h.Write(pw)
+	alt := h.Sum(nil)
+
+	h.Reset()
+	h.Write(pw)
+	h.Write([]byte(prefix))
+	h.Write(s)
+	writeRepeated(h, alt, len(pw))
+	for i := len(pw); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			h.Write([]byte{0})
+		} else {
+			h.Write(pw[:1])
+		}
+	}
+	c := h.Sum(nil)
+
+	for i := range 1000 {
+		h.Reset()
+		if i&1 != 0 {
+			h.Write(pw)
+		} else {
+			h.Write(c)
+		}
+		if i%3 != 0 {
+			h.Write(s)
+		}
+		if i%7 != 0 {
+			h.Write(pw)
+		}
+		if i&1 != 0 {
+			h.Write(c)
+		} else {
+			h.Write(pw)
+		}
+		c = h.Sum(c[:0])
+	}
+
+	var out strings.Builder
+	out.WriteString(prefix)
+	out.WriteString(salt)
+	out.WriteByte('$')
+	for i := 0; i+2 < len(md5CryptOrder); i += 3 {
+		encode24(&out, c[md5CryptOrder[i]], c[md5CryptOrder[i+1]], c[md5CryptOrder[i+2]], 4)
+	}
+	encode24(&out, 0, 0, c[11], 2)
+	return out.String()
+}
+
+// cryptAlphabet is the base64 alphabet of crypt(3).
+const cryptAlphabet = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// Byte orders used to encode MD5-crypt and SHA-crypt digests, three bytes at a time.
+var (
+	md5CryptOrder    = []int{0, 6, 12, 1, 7, 13, 2, 8, 14, 3, 9, 15, 4, 10, 5}
+	shaCrypt256Order = []int{
+		0, 10, 20, 21, 1, 11, 12, 22, 2, 3, 13, 23, 24, 4, 14,
+		15, 25, 5, 6, 16, 26, 27, 7, 17, 18, 28, 8, 9, 19, 29,
+	}
+	shaCrypt512Order = []int{
+		0, 21, 42, 22, 43, 1, 44, 2, 23, 3, 24, 45, 25, 46, 4,
+		47, 5, 26, 6, 27, 48, 28, 49, 7, 50, 8, 29, 9, 30, 51,
+		31, 52, 10, 53, 11, 32, 12, 33, 54, 34, 55, 13, 56, 14, 35,
+		15, 36, 57, 37, 58, 16, 59, 17, 38, 18, 39, 60, 40, 61, 19,
+		62, 20, 41,
+	}
+)
+
+// shaCrypt implements the SHA-crypt algorithm by Ulrich Drepper, used by
+// glibc crypt(3) for $5$ and $6$ hashes.
+func shaCrypt(newHash func() hash.Hash, order []int, prefix, password, salt string, rounds int, customRounds bool) string {
+	if len(salt) > 16 {
+		salt = salt[:16]
+	}
+	pw, s := []byte(password), []byte(salt)
+
+	h := newHash()
+	size := h.Size()
+
+	// Digest B
+	h.Write(pw)
+	h.Write(s)
+	h.Write(pw)
+	b := h.Sum(nil)
+
+	// Digest A
+	h.Reset()
+	h.Write(pw)
+	h.Write(s)
+	writeRepeated(h, b, len(pw))
+	for i := len(pw); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			h.Write(b)
+		} else {
+			h.Write(pw)
+		}
+	}
+	a := h.Sum(nil)
+
+	// Sequence P
+	h.Reset()
+	for range pw {
+		h.Write(pw)
+	}
+	p := repeatTo(h.Sum(nil), len(pw))
+
+	// Sequence S
+	h.Reset()
+	for range 16 + int(a[0]) {
+		h.Write(s)
+	}
+	sq := repeatTo(h.Sum(nil), len(s))
+
+	c := a
+	for i := range rounds {
+		h.Reset()
+		if i&1 != 0 {
+			h.Write(p)
+		} else {
+			h.Write(c)
+		}
+		if i%3 != 0 {
+			h.Write(sq)
+		}
+		if i%7 != 0 {
+			h.Write(p)
+		}
+		if i&1 != 0 {
+			h.Write(c)
+		} else {
+			h.Write(p)
+		}
+		c = h.Sum(c[:0])
+	}
+
+	var out strings.Builder
+	out.WriteString(prefix)
+	if customRounds {
+		out.WriteString("rounds=" + strconv.Itoa(rounds) + "$")
+	}
+	out.WriteString(salt)
+	out.WriteByte('$')
+	for i := 0; i+2 < len(order); i += 3 {
+		encode24(&out, c[order[i]], c[order[i+1]], c[order[i+2]], 4)
+	}
+	if size == sha256.Size {
+		encode24(&out, 0, c[31], c[30], 3)
+	} else {
+		encode24(&out, 0, 0, c[63], 2)
+	}
+	return out.String()
+}
+
+// writeRepeated writes n bytes of the infinite repetition of b.
+func writeRepeated(h hash.Hash, b []byte, n int) {
+	for ; n > len(b); n -= len(b) {
+		h.Write(b)
+	}
+	h.Write(b[:n])
+}
+
+// repeatTo returns n bytes of the infinite repetition of b.
+func repeatTo(b []byte, n int) []byte {
+	out := make([]byte, 0, n)
+	for len(out) < n {
+		out = append(out, b[:min(len(b), n-len(out))]...)
+	}
+	return out
+}
+
+// encode24 writes n characters encoding the 24 bits b2 b1 b0.
+func encode24(out *strings.Builder, b2, b1, b0 byte, n int) {
+	w := uint(b2)<<16 | uint(b1)<<8 | uint(b0)
+	for range n {
+		out.WriteByte(cryptAlphabet[w&0x3f])
+		w >>= 6
+	}
+}
+
+// ParseHtpasswd reads users from an htpasswd file ("user:hash" per line).
+//
+// Blank lines and lines starting with "#" are ignored.
+//
+// Parameters:
+//   - r: The htpasswd content.
+//
+// Returns:
+//   - map[string]string: The hashes by username, ready for Config.Users.
+//   - error: An error for malformed lines and for hashes of an unregistered
+//     format, naming the user and the line.
+func ParseHtpasswd(r io.Reader) (map[string]string, error) {
+	users := make(map[string]string)
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		user, hash, ok := strings.Cut(line, ":")
+		if !ok || user == "" || hash == "" {
+			return nil, fmt.Errorf("basicauth: htpasswd line %d: expected user:hash", n)
+		}
+		if _, ok := verifierFor(hash); !ok {
+			return nil, fmt.Errorf("basicauth: htpasswd line %d: unsupported password hash for user %q; import middleware/basicauth/xcrypt for bcrypt and Argon2, or see RegisterVerifier", n, user)
+		}
+		users[user] = hash
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("basicauth: htpasswd: %w", err)
+	}
+	return users, nil
+}
+
+// LoadHtpasswd reads users from an htpasswd file.
+//
+// Parameters:
+//   - path: The file path.
+//
+// Returns:
+//   - map[string]string: The hashes by username, ready for Config.Users.
+//   - error: An error if the file cannot be read, is malformed or uses an
+//     unregistered hash format.
+//
+// Example Usage:
+//
+//	users, err := basicauth.LoadHtpasswd("/etc/myapp/.htpasswd")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	q.Use(basicauth.New(basicauth.Config{Users: users}))
+func LoadHtpasswd(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("basicauth: %w", err)
+	}
+	defer f.Close()
+	return ParseHtpasswd(f)
+}
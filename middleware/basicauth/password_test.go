@@ -0,0 +1,84 @@
+package basicauth
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCheckPassword verifies the built-in password formats against
+// reference hashes produced by `openssl passwd` and `htpasswd -s`/`-m`.
+//
+// go test -v -failfast -count=1 -run ^TestCheckPassword$
+func TestCheckPassword(t *testing.T) {
+	tests := []struct {
+		name   string
+		stored string
+		pass   string
+		want   bool
+	}{
+		{"sha256crypt", "$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5", "Hello world!", true},
+		{"sha256crypt_rounds", "$5$rounds=10000$saltstringsaltst$3xv.VbSHBb41AL9AvLeujZkZRBAwqFMz2.opqey6IcA", "Hello world!", true},
+		{"sha512crypt", "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1", "Hello world!", true},
+		{"sha256crypt_wrong", "$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5", "hello world!", false},
+		{"apr1", "$apr1$saltsalt$r/QcFGT5pNL28bNkeDMHR.", "hunter2", true},
+		{"apr1_long", "$apr1$8charsal$p0CQ0M2PTonrjmQL150nw1", "a much longer password than sixteen", true},
+		{"apr1_wrong", "$apr1$saltsalt$r/QcFGT5pNL28bNkeDMHR.", "hunter3", false},
+		{"md5crypt", "$1$abc$BXBqpb9BZcZhXLgbee.0s/", "password", true},
+		{"sha1", "{SHA}qUqP5cyxm6YcTAhz05Hph5gvu9M=", "test", true},
+		{"sha1_wrong", "{SHA}qUqP5cyxm6YcTAhz05Hph5gvu9M=", "Test", false},
+		{"plain", "1234", "1234", true},
+		{"plain_wrong", "1234", "12345", false},
+		{"unregistered", "$2y$10$abcdefghijklmnopqrstuv", "secret", false},
+		{"malformed", "$5$rounds=abc$salt$hash", "secret", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CheckPassword(tt.stored, tt.pass); got != tt.want {
+				t.Errorf("CheckPassword(%q, %q) = %v, want %v", tt.stored, tt.pass, got, tt.want)
+			}
+		})
+	}
+}
+
+// go test -v -failfast -count=1 -run ^TestHashPassword$
+func TestHashPassword(t *testing.T) {
+	hash, err := HashPassword("s3cr3t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "$5$rounds=") {
+		t.Errorf("unexpected hash format: %s", hash)
+	}
+	if !CheckPassword(hash, "s3cr3t") || CheckPassword(hash, "other") {
+		t.Errorf("hash does not verify: %s", hash)
+	}
+	if other, _ := HashPassword("s3cr3t"); other == hash {
+		t.Error("expected a random salt")
+	}
+}
+
+// go test -v -failfast -count=1 -run ^TestParseHtpasswd$
+func TestParseHtpasswd(t *testing.T) {
+	users, err := ParseHtpasswd(strings.NewReader(`
+# admins
+admin:$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5
+ops:{SHA}qUqP5cyxm6YcTAhz05Hph5gvu9M=
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 || !CheckPassword(users["admin"], "Hello world!") || !CheckPassword(users["ops"], "test") {
+		t.Errorf("unexpected users: %v", users)
+	}
+
+	if _, err := ParseHtpasswd(strings.NewReader("admin\n")); err == nil {
+		t.Error("expected an error for a malformed line")
+	}
+	_, err = ParseHtpasswd(strings.NewReader("admin:1234\nops:$2y$10$abcdefghijklmnopqrstuv\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") || !strings.Contains(err.Error(), `"ops"`) {
+		t.Errorf("expected an error naming the user and line of an unsupported hash, got %v", err)
+	}
+	if _, err := LoadHtpasswd("does-not-exist"); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
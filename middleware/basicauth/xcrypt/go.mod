@@ -0,0 +1,12 @@
+module github.com/jeffotoni/quick/middleware/basicauth/xcrypt
+
+go 1.24.0
+
+require (
+	github.com/jeffotoni/quick v0.0.0-00010101000000-000000000000
+	golang.org/x/crypto v0.48.0
+)
+
+require golang.org/x/sys v0.41.0 // indirect
+
+replace github.com/jeffotoni/quick => ../../..
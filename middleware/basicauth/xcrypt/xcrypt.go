@@ -0,0 +1,74 @@
+// Package xcrypt adds bcrypt and Argon2 password hashes to middleware/basicauth.
+//
+// It is a separate module, so that Quick itself does not depend on
+// golang.org/x/crypto. Importing it registers the verifiers with
+// basicauth.RegisterVerifier:
+//
+//	import _ "github.com/jeffotoni/quick/middleware/basicauth/xcrypt"
+//
+// Supported formats:
+//   - bcrypt: $2a$, $2b$ and $2y$, as written by htpasswd -B.
+//   - Argon2id and Argon2i, in the PHC string format written by the argon2
+//     reference tool, e.g. "$argon2id$v=19$m=65536,t=3,p=4$salt$hash".
+package xcrypt
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/jeffotoni/quick/middleware/basicauth"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func init() {
+	for _, prefix := range []string{"$2a$", "$2b$", "$2y$"} {
+		basicauth.RegisterVerifier(prefix, VerifyBcrypt)
+	}
+	basicauth.RegisterVerifier("$argon2id$", VerifyArgon2)
+	basicauth.RegisterVerifier("$argon2i$", VerifyArgon2)
+}
+
+// VerifyBcrypt checks bcrypt hashes ($2a$, $2b$ and $2y$).
+func VerifyBcrypt(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// VerifyArgon2 checks Argon2id and Argon2i hashes in the PHC string format.
+// Only version 19 (0x13), the current version of Argon2, is supported.
+func VerifyArgon2(hash, password string) bool {
+	// "", variant, version, parameters, salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[2] != "v=19" {
+		return false
+	}
+	var memory, time uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil {
+		return false
+	}
+	if time == 0 || threads == 0 {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return false
+	}
+
+	var got []byte
+	switch parts[1] {
+	case "argon2id":
+		got = argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(key)))
+	case "argon2i":
+		got = argon2.Key([]byte(password), salt, time, memory, threads, uint32(len(key)))
+	default:
+		return false
+	}
+	return subtle.ConstantTimeCompare(got, key) == 1
+}
@@ -0,0 +1,80 @@
+package xcrypt
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jeffotoni/quick"
+	"github.com/jeffotoni/quick/middleware/basicauth"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// TestCheckPassword verifies the registered formats against reference hashes:
+// the $2y$ example of the PHP manual, the format of htpasswd -B, and the
+// examples of the Argon2 reference implementation.
+//
+// go test -v -failfast -count=1 -run ^TestCheckPassword$
+func TestCheckPassword(t *testing.T) {
+	tests := []struct {
+		name   string
+		stored string
+		pass   string
+		want   bool
+	}{
+		{"bcrypt_2y", "$2y$10$.vGA1O9wmRjrwAVXD98HNOgsNpDczlqm3Jq7KnEd1rVAGv3Fykk1a", "rasmuslerdorf", true},
+		{"bcrypt_2y_wrong", "$2y$10$.vGA1O9wmRjrwAVXD98HNOgsNpDczlqm3Jq7KnEd1rVAGv3Fykk1a", "rasmusLerdorf", false},
+		{"argon2id", "$argon2id$v=19$m=65536,t=2,p=4$c29tZXNhbHQ$GpZ3sK/oH9p7VIiV56G/64Zo/8GaUw434IimaPqxwCo", "password", true},
+		{"argon2id_p1", "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", "password", true},
+		{"argon2id_wrong", "$argon2id$v=19$m=65536,t=2,p=4$c29tZXNhbHQ$GpZ3sK/oH9p7VIiV56G/64Zo/8GaUw434IimaPqxwCo", "Password", false},
+		{"argon2i", "$argon2i$v=19$m=65536,t=2,p=4$c29tZXNhbHQ$RdescudvJCsgt3ub+b+dWRWJTmaaJObG", "password", true},
+		{"argon2_version", "$argon2id$v=16$m=65536,t=2,p=4$c29tZXNhbHQ$GpZ3sK/oH9p7VIiV56G/64Zo/8GaUw434IimaPqxwCo", "password", false},
+		{"argon2_malformed", "$argon2id$v=19$m=65536,t=0,p=4$c29tZXNhbHQ$GpZ3sK", "password", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := basicauth.CheckPassword(tt.stored, tt.pass); got != tt.want {
+				t.Errorf("CheckPassword(%q, %q) = %v, want %v", tt.stored, tt.pass, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestHtpasswd verifies that htpasswd files with bcrypt entries are loaded
+// and served by the basicauth middleware.
+//
+// go test -v -failfast -count=1 -run ^TestHtpasswd$
+func TestHtpasswd(t *testing.T) {
+	// htpasswd -B writes $2y$ hashes with a cost of 5
+	hash, err := bcrypt.GenerateFromPassword([]byte("s3cr3t"), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := "admin:$2y$" + strings.TrimPrefix(string(hash), "$2a$") + "\n" +
+		"ops:$argon2id$v=19$m=65536,t=2,p=4$c29tZXNhbHQ$GpZ3sK/oH9p7VIiV56G/64Zo/8GaUw434IimaPqxwCo\n"
+	users, err := basicauth.ParseHtpasswd(strings.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := basicauth.New(basicauth.Config{Users: users})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	for _, tt := range []struct {
+		user, pass string
+		code       int
+	}{
+		{"admin", "s3cr3t", http.StatusOK},
+		{"ops", "password", http.StatusOK},
+		{"admin", "password", http.StatusUnauthorized},
+	} {
+		req := httptest.NewRequest(quick.MethodGet, "/", nil)
+		req.SetBasicAuth(tt.user, tt.pass)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tt.code {
+			t.Errorf("%s: expected %d, got %d", tt.user, tt.code, rec.Code)
+		}
+	}
+}
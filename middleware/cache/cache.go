@@ -189,6 +189,14 @@ func handleCacheHit(c *quick.Ctx, cfg *Config, cached interface{}) error {
 		c.Response.Header().Set("Content-Type", entry.ContentType)
 	}
 
+	// Answer conditional requests with the validators of the entry
+	if entry.ETag != "" {
+		c.Response.Header().Set("ETag", entry.ETag)
+	}
+	if entry.StatusCode == http.StatusOK && c.CheckPreconditions() {
+		return nil
+	}
+
 	// Set the status code directly on the response writer
 	c.Response.WriteHeader(entry.StatusCode)
 
@@ -240,8 +248,8 @@ func handleResponseWithoutCaching(c *quick.Ctx, cfg *Config, responseWriter *res
 			cacheResponse(c, cfg, responseWriter)
 		}
 
-		// Write the body directly
-		_, err = c.Response.Write(responseWriter.buffer.Bytes())
+		// Send the captured response
+		err = responseWriter.commit()
 	}
 	return err
 }
@@ -269,9 +277,17 @@ func processAndCacheResponse(c *quick.Ctx, cfg *Config, responseWriter *response
 	// Copy important headers to avoid WriteHeader conflicts
 	copyImportantHeaders(c.Response, responseWriter.headers)
 
-	// Write the body directly and return
-	_, err := c.Response.Write(responseWriter.buffer.Bytes())
-	return err
+	// Answer with the validators of the entry, as hits do, unless Flush
+	// already sent the headers
+	if !responseWriter.committed && entry.ETag != "" {
+		c.Response.Header().Set("ETag", entry.ETag)
+		if entry.StatusCode == http.StatusOK && c.CheckPreconditions() {
+			return nil
+		}
+	}
+
+	// Send the captured response
+	return responseWriter.commit()
 }
 
 // calculateExpiration determines the expiration time for a cache entry
@@ -331,11 +347,18 @@ func buildCacheEntry(c *quick.Ctx, w *responseCapture, cfg *Config, exp time.Tim
 		}
 	}
 
+	// Keep the ETag set by the handler, or derive one from the body
+	etag := w.ResponseWriter.Header().Get("ETag")
+	if etag == "" {
+		etag = quick.GenerateETag(w.buffer.Bytes(), false)
+	}
+
 	return &cacheEntry{
 		Body:         w.buffer.Bytes(),
 		StatusCode:   w.statusCode,
 		Expiration:   exp,
 		ContentType:  contentType,
+		ETag:         etag,
 		CreatedAt:    time.Now(),
 		LastAccessed: time.Now(),
 	}
@@ -350,12 +373,17 @@ func getHeader(h map[string][]string, key string) string {
 
 // responseCapture is a wrapper around http.ResponseWriter that captures
 // the response for caching.
+//
+// The status and body are held until commit, so the middleware can still set
+// headers such as the ETag once the handler returns. Server-Sent Events are
+// sent on Flush and streamed.
 type responseCapture struct {
 	http.ResponseWriter
 	statusCode    int
 	buffer        *bytes.Buffer
 	headers       http.Header
 	headerWritten bool // Flag to track if WriteHeader has been called
+	committed     bool // Flag to track if the response was sent to the client
 }
 
 // WriteHeader captures the status code.
@@ -371,7 +399,6 @@ func (r *responseCapture) WriteHeader(statusCode int) {
 			r.ResponseWriter.Header().Add(k, v)
 		}
 	}
-	r.ResponseWriter.WriteHeader(statusCode)
 }
 
 // Write captures the response body.
@@ -380,7 +407,27 @@ func (r *responseCapture) Write(b []byte) (int, error) {
 		r.WriteHeader(http.StatusOK)
 	}
 	r.buffer.Write(b)
-	return r.ResponseWriter.Write(b)
+	if r.committed {
+		return r.ResponseWriter.Write(b)
+	}
+	return len(b), nil
+}
+
+// commit sends the captured status and body to the client, unless Flush
+// already did.
+func (r *responseCapture) commit() error {
+	if r.committed {
+		return nil
+	}
+	r.committed = true
+
+	status := r.statusCode
+	if status == 0 {
+		status = http.StatusOK
+	}
+	r.ResponseWriter.WriteHeader(status)
+	_, err := r.ResponseWriter.Write(r.buffer.Bytes())
+	return err
 }
 
 // Header captures response headers.
@@ -406,8 +453,16 @@ func (r *responseCapture) Del(key string) {
 	r.ResponseWriter.Header().Del(key)
 }
 
-// Flush implements http.Flusher interface for SSE support
+// Flush implements http.Flusher interface for SSE support. Other responses
+// stay buffered: Quick flushes after every response it writes.
 func (r *responseCapture) Flush() {
+	if !r.committed {
+		media, _, _ := strings.Cut(r.Header().Get("Content-Type"), ";")
+		if !strings.EqualFold(strings.TrimSpace(media), "text/event-stream") {
+			return
+		}
+		r.commit()
+	}
 	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
 		flusher.Flush()
 	}
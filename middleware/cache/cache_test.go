@@ -399,6 +399,98 @@ func TestCacheStorage(t *testing.T) {
 	})
 }
 
+// TestCacheConditional verifies that misses and hits send the ETag of the
+// entry and answer If-None-Match with 304.
+//
+// go test -v -failfast -count=1 -run ^TestCacheConditional$
+func TestCacheConditional(t *testing.T) {
+	q := quick.New()
+	q.Use(New())
+
+	var counter int
+	q.Get("/doc", func(c *quick.Ctx) error {
+		counter++
+		return c.String("document")
+	})
+	q.Get("/versioned", func(c *quick.Ctx) error {
+		c.SetETag("v7")
+		return c.String("versioned document")
+	})
+
+	get := func(uri, inm string) quick.QtestReturn {
+		t.Helper()
+		opts := quick.QuickTestOptions{Method: quick.MethodGet, URI: uri}
+		if inm != "" {
+			opts.Headers = map[string]string{"If-None-Match": inm}
+		}
+		resp, err := q.Qtest(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	miss := get("/doc", "")
+	if miss.BodyStr() != "document" {
+		t.Errorf("expected the body once on a miss, got %q", miss.BodyStr())
+	}
+	hit := get("/doc", "")
+	etag := hit.Response().Header.Get("ETag")
+	if etag != quick.GenerateETag([]byte("document"), false) {
+		t.Fatalf("expected the ETag of the body, got %q", etag)
+	}
+	if got := miss.Response().Header.Get("ETag"); got != etag {
+		t.Errorf("expected the miss to send the ETag of the entry, got %q", got)
+	}
+
+	resp := get("/doc", etag)
+	if resp.StatusCode() != quick.StatusNotModified || resp.BodyStr() != "" {
+		t.Errorf("expected 304 without body, got %d %q", resp.StatusCode(), resp.BodyStr())
+	}
+	resp = get("/doc", `"stale"`)
+	if resp.StatusCode() != quick.StatusOK || resp.BodyStr() != "document" {
+		t.Errorf("expected the cached body, got %d %q", resp.StatusCode(), resp.BodyStr())
+	}
+	if counter != 1 {
+		t.Errorf("expected the handler to run once, got %d", counter)
+	}
+
+	resp = get("/versioned", `"v7"`)
+	if resp.StatusCode() != quick.StatusNotModified {
+		t.Errorf("expected a miss to answer If-None-Match, got %d", resp.StatusCode())
+	}
+	resp = get("/versioned", `"v7"`)
+	if resp.StatusCode() != quick.StatusNotModified {
+		t.Errorf("expected the ETag of the handler to be kept, got %d", resp.StatusCode())
+	}
+}
+
+// TestCacheStream verifies that Server-Sent Events are streamed on a miss
+// instead of being held until the handler returns.
+//
+// go test -v -failfast -count=1 -run ^TestCacheStream$
+func TestCacheStream(t *testing.T) {
+	q := quick.New()
+	q.Use(New())
+
+	rec := httptest.NewRecorder()
+	q.Get("/events", func(c *quick.Ctx) error {
+		c.Set("Content-Type", "text/event-stream")
+		if err := c.String("data: 1\n\n"); err != nil {
+			return err
+		}
+		if rec.Body.String() != "data: 1\n\n" {
+			t.Errorf("expected the event to be streamed, got %q", rec.Body.String())
+		}
+		return c.String("data: 2\n\n")
+	})
+
+	q.ServeHTTP(rec, httptest.NewRequest(quick.MethodGet, "/events", nil))
+	if body := rec.Body.String(); body != "data: 1\n\ndata: 2\n\n" {
+		t.Errorf("expected each event once, got %q", body)
+	}
+}
+
 // //---
 type capture struct {
 	count int
@@ -635,6 +727,10 @@ func TestResponseCapture_WriteHeaderAndImplicit(t *testing.T) {
 	if rc.statusCode != 201 || !rc.headerWritten {
 		t.Fatalf("WriteHeader did not set status or flag")
 	}
+	if rec.Flushed || rec.Code != http.StatusOK {
+		t.Fatalf("WriteHeader sent the status before commit")
+	}
+	rc.commit()
 	if rec.Result().StatusCode != 201 {
 		t.Fatalf("WriteHeader not propagated, got %d", rec.Result().StatusCode)
 	}
@@ -657,6 +753,10 @@ func TestResponseCapture_WriteHeaderAndImplicit(t *testing.T) {
 	}
 	rc2.Set("Content-Type", "text/plain")
 	rc2.Write([]byte("hi"))
+	if rec2.Body.Len() != 0 {
+		t.Fatalf("body sent before commit")
+	}
+	rc2.commit()
 
 	if rc2.statusCode != http.StatusOK || rec2.Code != http.StatusOK {
 		t.Fatalf("implicit WriteHeader failed")
@@ -689,6 +789,8 @@ func TestResponseCapture_WriteWithHeadersAlreadyWritten(t *testing.T) {
 		t.Fatalf("Write returned wrong byte count: %d", n)
 	}
 
+	rc.commit()
+
 	// Verify that the headers were not copied again
 	if rec.Header().Get("X-Test") != "" {
 		t.Fatalf("Headers were copied when they shouldn't have been")
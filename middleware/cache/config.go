@@ -92,6 +92,7 @@ type cacheEntry struct {
 	StatusCode   int
 	Headers      map[string][]string
 	ContentType  string
+	ETag         string
 	Expiration   time.Time
 	LastAccessed time.Time
 	CreatedAt    time.Time
@@ -1,4 +1,8 @@
-// Package compress provides middleware for compressing HTTP responses using gzip.
+// Package compress provides middleware for compressing HTTP responses.
+//
+// Gzip compresses every response for clients that accept gzip. New negotiates
+// br, zstd, gzip or deflate from Accept-Encoding and skips small bodies and
+// content types that are already compressed.
 //
 // This middleware enhances performance by reducing response payload sizes, leading to
 // faster page loads and reduced bandwidth consumption.
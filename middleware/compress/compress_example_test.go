@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"strings"
 
 	"github.com/jeffotoni/quick"
 )
@@ -68,3 +69,36 @@ func ExampleGzip() {
 	// Output:
 	// {"msg":"Quick in action!","headers":{"Accept-Encoding":["gzip"]}}
 }
+
+// This function is named ExampleNew()
+// it with the Examples type.
+func ExampleNew() {
+	q := quick.New()
+
+	// Negotiate the coding and skip bodies shorter than 64 bytes
+	q.Use(New(Config{MinLength: 64}))
+
+	q.Get("/v1/small", func(c *quick.Ctx) error {
+		return c.Status(200).String("too small to compress")
+	})
+	q.Get("/v1/large", func(c *quick.Ctx) error {
+		c.Set("Content-Type", "text/plain")
+		return c.Status(200).String(strings.Repeat("Quick in action! ", 10))
+	})
+
+	for _, uri := range []string{"/v1/small", "/v1/large"} {
+		res, err := q.Qtest(quick.QuickTestOptions{
+			Method:  quick.MethodGet,
+			URI:     uri,
+			Headers: map[string]string{"Accept-Encoding": "br;q=0.5, deflate;q=0.8, gzip;q=0.2"},
+		})
+		if err != nil {
+			log.Fatalf("Error running test request: %v", err)
+		}
+		fmt.Printf("%s: %q\n", uri, res.Response().Header.Get("Content-Encoding"))
+	}
+
+	// Output:
+	// /v1/small: ""
+	// /v1/large: "deflate"
+}
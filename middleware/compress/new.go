@@ -0,0 +1,226 @@
+package compress
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/jeffotoni/quick"
+)
+
+// Level is the compression level passed to encoders.
+type Level int
+
+const (
+	// LevelDefault balances speed and size.
+	LevelDefault Level = iota
+	// LevelBestSpeed compresses as fast as possible.
+	LevelBestSpeed
+	// LevelBestCompression produces the smallest output.
+	LevelBestCompression
+)
+
+// Encoder creates a writer that compresses to w with one content coding.
+//
+// Writers that also implement Reset(io.Writer), as those of compress/gzip,
+// andybalholm/brotli and klauspost/compress/zstd do, are pooled and reused.
+type Encoder func(w io.Writer, level Level) (io.WriteCloser, error)
+
+// Config defines the configuration options for the compress middleware.
+type Config struct {
+	// Level is the compression level.
+	// Default is LevelDefault.
+	Level Level
+
+	// MinLength is the smallest body, in bytes, that is compressed.
+	// Default is 1024.
+	MinLength int
+
+	// Encodings lists the content codings offered, in order of preference.
+	// Codings without an encoder are ignored.
+	// Default is "br", "zstd", "gzip", "deflate".
+	Encodings []string
+
+	// Encoders adds or replaces encoders by content coding. gzip and
+	// deflate are built in; br and zstd are not part of the standard
+	// library and are only offered when an encoder is provided here.
+	Encoders map[string]Encoder
+
+	// ExcludedContentTypes lists media types, or prefixes such as "image/",
+	// that are never compressed. image/svg+xml is always compressible.
+	// Default is DefaultExcludedContentTypes.
+	ExcludedContentTypes []string
+
+	// Next is a function that determines whether to skip the middleware.
+	Next func(c *quick.Ctx) bool
+}
+
+// DefaultExcludedContentTypes are already compressed media types.
+var DefaultExcludedContentTypes = []string{
+	"image/",
+	"video/",
+	"audio/",
+	"font/woff",
+	"application/zip",
+	"application/gzip",
+	"application/x-gzip",
+	"application/zstd",
+	"application/x-bzip2",
+	"application/x-xz",
+	"application/x-7z-compressed",
+	"application/x-rar-compressed",
+	"application/pdf",
+	"application/octet-stream",
+}
+
+// builtinEncoders are the encoders of the standard library.
+var builtinEncoders = map[string]Encoder{
+	"gzip": func(w io.Writer, level Level) (io.WriteCloser, error) {
+		return gzip.NewWriterLevel(w, stdLevel(level))
+	},
+	// The "deflate" content coding is the zlib format (RFC 9110, 8.4.1.2)
+	"deflate": func(w io.Writer, level Level) (io.WriteCloser, error) {
+		return zlib.NewWriterLevel(w, stdLevel(level))
+	},
+}
+
+// stdLevel maps level to the levels of compress/flate.
+func stdLevel(level Level) int {
+	switch level {
+	case LevelBestSpeed:
+		return gzip.BestSpeed
+	case LevelBestCompression:
+		return gzip.BestCompression
+	}
+	return gzip.DefaultCompression
+}
+
+// resetter is implemented by encoders that can be reused.
+type resetter interface {
+	Reset(w io.Writer)
+}
+
+// coder creates and pools the writers of one content coding.
+type coder struct {
+	encoder Encoder
+	level   Level
+	pool    sync.Pool
+}
+
+// get returns a writer compressing to w.
+func (cd *coder) get(w io.Writer) (io.WriteCloser, error) {
+	if v := cd.pool.Get(); v != nil {
+		v.(resetter).Reset(w)
+		return v.(io.WriteCloser), nil
+	}
+	return cd.encoder(w, cd.level)
+}
+
+// put returns a closed writer to the pool, if it can be reused.
+func (cd *coder) put(enc io.WriteCloser) {
+	if _, ok := enc.(resetter); ok {
+		cd.pool.Put(enc)
+	}
+}
+
+// compressor holds the state shared by the requests of one middleware.
+type compressor struct {
+	cfg    Config
+	coders map[string]*coder
+	offers []string // Encodings with an encoder, then "identity"
+}
+
+// New creates a middleware that compresses responses with the content
+// coding preferred by the client.
+//
+// The coding is negotiated from Accept-Encoding, honoring q-values; between
+// equally acceptable codings the order of Config.Encodings wins. Bodies
+// shorter than MinLength, responses that are already encoded and excluded
+// content types are sent as is. Flush and Hijack are passed through, so
+// Server-Sent Events and WebSockets keep working.
+//
+// Parameters:
+//   - config: Optional configuration.
+//
+// Returns:
+//   - func(http.Handler) http.Handler: The middleware, usable with q.Use and Group.Use.
+//
+// Example Usage:
+//
+//	q.Use(compress.New(compress.Config{
+//	    Level:     compress.LevelBestSpeed,
+//	    MinLength: 512,
+//	}))
+func New(config ...Config) func(http.Handler) http.Handler {
+	var cfg Config
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.MinLength <= 0 {
+		cfg.MinLength = 1024
+	}
+	if cfg.Encodings == nil {
+		cfg.Encodings = []string{"br", "zstd", "gzip", "deflate"}
+	}
+	if cfg.ExcludedContentTypes == nil {
+		cfg.ExcludedContentTypes = DefaultExcludedContentTypes
+	}
+
+	cp := &compressor{cfg: cfg, coders: make(map[string]*coder)}
+	for _, name := range cfg.Encodings {
+		name = strings.ToLower(name)
+		enc, ok := cfg.Encoders[name]
+		if !ok {
+			enc, ok = builtinEncoders[name]
+		}
+		if !ok || cp.coders[name] != nil {
+			continue
+		}
+		cp.coders[name] = &coder{encoder: enc, level: cfg.Level}
+		cp.offers = append(cp.offers, name)
+	}
+	cp.offers = append(cp.offers, "identity")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c := &quick.Ctx{Response: w, Request: r}
+			if (cfg.Next != nil && cfg.Next(c)) || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add("Vary", "Accept-Encoding")
+			coding := ""
+			if r.Header.Get("Accept-Encoding") != "" {
+				coding = c.AcceptsEncodings(cp.offers...)
+			}
+			cd := cp.coders[coding]
+			if cd == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{ResponseWriter: w, cp: cp, coding: coding, coder: cd}
+			defer cw.close()
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// compressible reports whether responses of content type ct are compressed.
+func (cp *compressor) compressible(ct string) bool {
+	media, _, _ := strings.Cut(ct, ";")
+	media = strings.ToLower(strings.TrimSpace(media))
+	if media == "image/svg+xml" {
+		return true
+	}
+	for _, excluded := range cp.cfg.ExcludedContentTypes {
+		if strings.HasPrefix(media, excluded) {
+			return false
+		}
+	}
+	return true
+}
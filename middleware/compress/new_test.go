@@ -0,0 +1,240 @@
+package compress
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jeffotoni/quick"
+)
+
+// fakeBrotli stands in for a brotli encoder: raw DEFLATE is enough to check
+// that custom encoders are negotiated and pooled.
+func fakeBrotli(w io.Writer, level Level) (io.WriteCloser, error) {
+	return flate.NewWriter(w, flate.BestSpeed)
+}
+
+// decode decompresses body according to coding.
+func decode(t *testing.T, coding string, body io.Reader) string {
+	t.Helper()
+	var r io.Reader
+	var err error
+	switch coding {
+	case "gzip":
+		r, err = gzip.NewReader(body)
+	case "deflate":
+		r, err = zlib.NewReader(body)
+	case "br":
+		r = flate.NewReader(body)
+	default:
+		r = body
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+// TestNew verifies negotiation, the minimum length and excluded content types.
+//
+// go test -v -failfast -count=1 -run ^TestNew$
+func TestNew(t *testing.T) {
+	large := strings.Repeat("quick ", 500)
+
+	q := quick.New()
+	q.Use(New(Config{Encoders: map[string]Encoder{"br": fakeBrotli}}))
+	q.Get("/text", func(c *quick.Ctx) error {
+		c.Set("Content-Type", "text/plain")
+		return c.Status(quick.StatusOK).String(large)
+	})
+	q.Get("/small", func(c *quick.Ctx) error {
+		return c.Status(quick.StatusOK).String("tiny")
+	})
+	q.Get("/image", func(c *quick.Ctx) error {
+		c.Set("Content-Type", "image/png")
+		return c.Status(quick.StatusOK).String(large)
+	})
+	q.Get("/svg", func(c *quick.Ctx) error {
+		c.Set("Content-Type", "image/svg+xml")
+		return c.Status(quick.StatusOK).String(large)
+	})
+	q.Get("/encoded", func(c *quick.Ctx) error {
+		c.Set("Content-Encoding", "gzip")
+		return c.Status(quick.StatusOK).String(large)
+	})
+	q.Get("/missing", func(c *quick.Ctx) error {
+		return quick.NewError(quick.StatusNotFound, large)
+	})
+
+	tests := []struct {
+		name   string
+		path   string
+		accept string
+		want   string
+	}{
+		{"prefers_server_order", "/text", "gzip, deflate, br", "br"},
+		{"q_values", "/text", "br;q=0.5, gzip;q=0.9, deflate;q=0.8", "gzip"},
+		{"deflate", "/text", "deflate", "deflate"},
+		{"wildcard", "/text", "*", "br"},
+		{"identity_preferred", "/text", "gzip;q=0.1, identity", ""},
+		{"refused", "/text", "gzip;q=0, br;q=0", ""},
+		{"zstd_without_encoder", "/text", "zstd", ""},
+		{"no_header", "/text", "", ""},
+		{"small", "/small", "gzip", ""},
+		{"image", "/image", "gzip", ""},
+		{"svg", "/svg", "gzip", "gzip"},
+		{"already_encoded", "/encoded", "br", "gzip"},
+		{"error_response", "/missing", "gzip", "gzip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(quick.MethodGet, tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept-Encoding", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			q.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Content-Encoding"); got != tt.want {
+				t.Fatalf("Content-Encoding = %q, want %q", got, tt.want)
+			}
+			if rec.Header().Get("Vary") != "Accept-Encoding" {
+				t.Errorf("expected Vary: Accept-Encoding, got %q", rec.Header().Get("Vary"))
+			}
+			if tt.path == "/encoded" {
+				return
+			}
+			body := decode(t, tt.want, rec.Body)
+			if tt.path == "/small" && body != "tiny" {
+				t.Errorf("unexpected body %q", body)
+			}
+			if tt.path != "/small" && !strings.Contains(body, "quick quick") {
+				t.Errorf("unexpected body %.40q", body)
+			}
+		})
+	}
+}
+
+// TestNewLevelsAndStatus verifies compression levels, bodyless statuses and
+// the reuse of pooled encoders.
+//
+// go test -v -failfast -count=1 -run ^TestNewLevelsAndStatus$
+func TestNewLevelsAndStatus(t *testing.T) {
+	body := strings.Repeat("abcdefghij0123456789", 200)
+	sizes := map[Level]int{}
+	for _, level := range []Level{LevelBestSpeed, LevelBestCompression} {
+		h := New(Config{Level: level})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, body)
+		}))
+		for i := 0; i < 2; i++ {
+			req := httptest.NewRequest(quick.MethodGet, "/", nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			sizes[level] = rec.Body.Len()
+			if got := decode(t, "gzip", rec.Body); got != body {
+				t.Fatalf("level %d, request %d: body mismatch", level, i)
+			}
+		}
+	}
+	if sizes[LevelBestCompression] > sizes[LevelBestSpeed] {
+		t.Errorf("expected best compression to be smaller: %v", sizes)
+	}
+
+	h := New(Config{MinLength: 1})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	req := httptest.NewRequest(quick.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Content-Encoding") != "" || rec.Body.Len() != 0 {
+		t.Errorf("unexpected 204 response: %d %v %q", rec.Code, rec.Header(), rec.Body.String())
+	}
+}
+
+// TestNewFlush verifies that flushed events reach the client before the
+// handler returns, as Server-Sent Events need.
+//
+// go test -v -failfast -count=1 -run ^TestNewFlush$
+func TestNewFlush(t *testing.T) {
+	next := make(chan struct{})
+	h := New()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: one\n\n")
+		w.(http.Flusher).Flush()
+		<-next
+		io.WriteString(w, "data: two\n\n")
+	}))
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	req, _ := http.NewRequest(quick.MethodGet, srv.URL, nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected a gzip stream, got %q", resp.Header.Get("Content-Encoding"))
+	}
+
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := make(chan string, 1)
+	go func() {
+		s, _ := bufio.NewReader(gz).ReadString('\n')
+		line <- s
+	}()
+	select {
+	case s := <-line:
+		if s != "data: one\n" {
+			t.Errorf("unexpected event %q", s)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the flushed event did not reach the client")
+	}
+	close(next)
+}
+
+// hijackRecorder is a ResponseRecorder that can be hijacked.
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (h *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h.hijacked = true
+	return nil, nil, nil
+}
+
+// go test -v -failfast -count=1 -run ^TestNewHijack$
+func TestNewHijack(t *testing.T) {
+	h := New()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, _, err := w.(http.Hijacker).Hijack(); err != nil {
+			t.Error(err)
+		}
+	}))
+	req := httptest.NewRequest(quick.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	h.ServeHTTP(rec, req)
+	if !rec.hijacked {
+		t.Error("expected Hijack to reach the underlying ResponseWriter")
+	}
+}
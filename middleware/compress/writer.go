@@ -0,0 +1,162 @@
+package compress
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// compressWriter buffers the beginning of a response until it knows whether
+// to compress it: when MinLength bytes are written, on Flush or at the end.
+//
+// Quick flushes after every response it writes, so a Flush before MinLength
+// bytes means a short body: it is sent as is, except for event streams,
+// which are compressed from the first event.
+type compressWriter struct {
+	http.ResponseWriter
+	cp     *compressor
+	coding string
+	coder  *coder
+
+	code        int            // Status set by the handler
+	wroteHeader bool           // WriteHeader was called by the handler
+	started     bool           // Status and headers were sent
+	buf         []byte         // Body written before the decision
+	enc         io.WriteCloser // Encoder, when compressing
+}
+
+// WriteHeader records the status; it is sent with the first bytes of the body.
+func (w *compressWriter) WriteHeader(code int) {
+	if w.started || w.wroteHeader {
+		return
+	}
+	if code < http.StatusOK {
+		// Informational responses (103 Early Hints) go out at once
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.code = code
+	w.wroteHeader = true
+	if !bodyAllowed(code) {
+		_ = w.start(false)
+	}
+}
+
+// Write buffers the body until the decision is made, then compresses it or
+// passes it through.
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if !w.started {
+		w.buf = append(w.buf, b...)
+		if len(w.buf) < w.cp.cfg.MinLength {
+			return len(b), nil
+		}
+		return len(b), w.start(w.shouldCompress())
+	}
+	if w.enc != nil {
+		return w.enc.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher for SSE support.
+func (w *compressWriter) Flush() {
+	if !w.started {
+		_ = w.start(w.eventStream() && w.shouldCompress())
+	}
+	if f, ok := w.enc.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker for WebSocket upgrades.
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("compress: ResponseWriter does not implement http.Hijacker")
+}
+
+// Unwrap returns the original ResponseWriter, for http.ResponseController.
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// shouldCompress decides whether the response is compressed.
+func (w *compressWriter) shouldCompress() bool {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	h := w.Header()
+	if !bodyAllowed(w.code) || w.code == http.StatusPartialContent || h.Get("Content-Encoding") != "" {
+		return false
+	}
+	ct := h.Get("Content-Type")
+	if ct == "" && len(w.buf) > 0 {
+		ct = http.DetectContentType(w.buf)
+		h.Set("Content-Type", ct)
+	}
+	return w.cp.compressible(ct)
+}
+
+// eventStream reports whether the response is a Server-Sent Events stream.
+func (w *compressWriter) eventStream() bool {
+	media, _, _ := strings.Cut(w.Header().Get("Content-Type"), ";")
+	return strings.EqualFold(strings.TrimSpace(media), "text/event-stream")
+}
+
+// start sends the status, the headers and the buffered body.
+func (w *compressWriter) start(compress bool) error {
+	w.started = true
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	if compress {
+		enc, err := w.coder.get(w.ResponseWriter)
+		if err == nil {
+			w.enc = enc
+			h := w.Header()
+			h.Del("Content-Length")
+			h.Set("Content-Encoding", w.coding)
+		}
+	}
+	w.ResponseWriter.WriteHeader(w.code)
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if w.enc != nil {
+		_, err := w.enc.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+// close sends a response still being buffered and finishes the compressed stream.
+func (w *compressWriter) close() {
+	if !w.started {
+		if !w.wroteHeader && len(w.buf) == 0 {
+			// Nothing was written: let net/http send its default response
+			return
+		}
+		_ = w.start(false)
+	}
+	if w.enc != nil {
+		_ = w.enc.Close()
+		w.coder.put(w.enc)
+		w.enc = nil
+	}
+}
+
+// bodyAllowed reports whether a response with this status may have a body.
+func bodyAllowed(code int) bool {
+	return code >= http.StatusOK && code != http.StatusNoContent && code != http.StatusNotModified
+}
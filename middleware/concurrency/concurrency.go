@@ -0,0 +1,215 @@
+// Package concurrency provides load-shedding middleware for Quick.
+//
+// Unlike the limiter middleware, which limits how often each client calls,
+// concurrency caps how many requests are served at the same time, whoever
+// sends them. Requests over the limit wait in a bounded queue for up to a
+// maximum time; the rest are shed with 503 Service Unavailable and
+// Retry-After, so an overloaded server answers quickly instead of
+// collapsing under its own backlog.
+//
+// Features:
+//   - Global limit (q.Use, Group.Use) or per route (Route).
+//   - Bounded FIFO queue with a maximum wait time.
+//   - Fixed limit, or adapted from observed latency with AIMD or Gradient.
+//   - Custom response for shed requests.
+package concurrency
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jeffotoni/quick"
+)
+
+// Config defines the configuration options for the concurrency middleware.
+type Config struct {
+	// Limit is the maximum number of requests served at once, or the initial
+	// limit of the adaptive algorithms.
+	// Default is 100.
+	Limit int
+
+	// MaxQueue is the number of requests that may wait for a slot.
+	// Default is Limit. Set a negative value to disable the queue.
+	MaxQueue int
+
+	// MaxWait is how long a request may wait in the queue.
+	// Default is 1 second.
+	MaxWait time.Duration
+
+	// RetryAfter is sent in the Retry-After header of shed requests.
+	// Default is 1 second.
+	RetryAfter time.Duration
+
+	// Algorithm selects how the limit adapts to latency.
+	// Default is Fixed.
+	Algorithm Algorithm
+
+	// MinLimit and MaxLimit bound the adaptive limit.
+	// Defaults are 1 and 10 times Limit.
+	MinLimit int
+	MaxLimit int
+
+	// LatencyThreshold is the latency above which AIMD decreases the limit.
+	// Default is 1 second.
+	LatencyThreshold time.Duration
+
+	// BackoffRatio multiplies the limit when AIMD decreases it.
+	// Default is 0.9.
+	BackoffRatio float64
+
+	// Tolerance is how many times the long-term latency Gradient accepts
+	// before decreasing the limit.
+	// Default is 2.
+	Tolerance float64
+
+	// Next is a function that determines whether to skip the middleware.
+	Next func(c *quick.Ctx) bool
+
+	// OnShed is called for requests that are not served.
+	// Returned errors are rendered by the error handler of the app.
+	// Default is a 503 quick.Error.
+	OnShed func(c *quick.Ctx) error
+}
+
+// ConfigDefault is the default configuration.
+var ConfigDefault = Config{
+	Limit:            100,
+	MaxWait:          time.Second,
+	RetryAfter:       time.Second,
+	Algorithm:        Fixed,
+	LatencyThreshold: time.Second,
+	BackoffRatio:     0.9,
+	Tolerance:        2,
+}
+
+// limiter admits requests for one middleware or route.
+type limiter struct {
+	cfg Config
+	sem *semaphore
+}
+
+// New creates the concurrency middleware.
+//
+// Parameters:
+//   - config: Optional configuration; ConfigDefault is used when omitted.
+//
+// Returns:
+//   - func(http.Handler) http.Handler: The middleware, usable with q.Use and Group.Use.
+//
+// Example Usage:
+//
+//	q.Use(concurrency.New(concurrency.Config{
+//	    Limit:     200,
+//	    MaxWait:   500 * time.Millisecond,
+//	    Algorithm: concurrency.Gradient,
+//	}))
+func New(config ...Config) func(http.Handler) http.Handler {
+	l := newLimiter(config...)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c := &quick.Ctx{Response: w, Request: r}
+			err := l.serve(c, func() error {
+				next.ServeHTTP(w, r)
+				return nil
+			})
+			if err != nil {
+				quick.HandleError(c, err)
+			}
+		})
+	}
+}
+
+// Route creates a concurrency limit for a single route.
+//
+// Each call has its own limit, so an expensive route can be capped
+// separately from the rest of the application.
+//
+// Example Usage:
+//
+//	q.Post("/reports", concurrency.Route(concurrency.Config{Limit: 4})(buildReport))
+func Route(config ...Config) func(quick.HandleFunc) quick.HandleFunc {
+	l := newLimiter(config...)
+	return func(h quick.HandleFunc) quick.HandleFunc {
+		return func(c *quick.Ctx) error {
+			return l.serve(c, func() error { return h(c) })
+		}
+	}
+}
+
+// newLimiter applies the defaults of config.
+func newLimiter(config ...Config) *limiter {
+	cfg := ConfigDefault
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Limit <= 0 {
+		cfg.Limit = ConfigDefault.Limit
+	}
+	if cfg.MaxQueue == 0 {
+		cfg.MaxQueue = cfg.Limit
+	}
+	if cfg.MaxQueue < 0 {
+		cfg.MaxQueue = 0
+	}
+	if cfg.MaxWait <= 0 {
+		cfg.MaxWait = ConfigDefault.MaxWait
+	}
+	if cfg.RetryAfter <= 0 {
+		cfg.RetryAfter = ConfigDefault.RetryAfter
+	}
+	if cfg.MinLimit <= 0 {
+		cfg.MinLimit = 1
+	}
+	if cfg.MaxLimit <= 0 {
+		cfg.MaxLimit = 10 * cfg.Limit
+	}
+	if cfg.LatencyThreshold <= 0 {
+		cfg.LatencyThreshold = ConfigDefault.LatencyThreshold
+	}
+	if cfg.BackoffRatio <= 0 || cfg.BackoffRatio >= 1 {
+		cfg.BackoffRatio = ConfigDefault.BackoffRatio
+	}
+	if cfg.Tolerance < 1 {
+		cfg.Tolerance = ConfigDefault.Tolerance
+	}
+	if cfg.OnShed == nil {
+		cfg.OnShed = func(c *quick.Ctx) error {
+			return quick.NewError(quick.StatusServiceUnavailable)
+		}
+	}
+
+	sem := &semaphore{limit: cfg.Limit, maxQueue: cfg.MaxQueue}
+	if cfg.Algorithm != Fixed {
+		sem.adaptive = &adaptive{
+			algo:      cfg.Algorithm,
+			limit:     float64(cfg.Limit),
+			min:       float64(cfg.MinLimit),
+			max:       float64(cfg.MaxLimit),
+			threshold: cfg.LatencyThreshold,
+			backoff:   cfg.BackoffRatio,
+			tolerance: cfg.Tolerance,
+			smoothing: 0.2,
+		}
+	}
+	return &limiter{cfg: cfg, sem: sem}
+}
+
+// serve runs handler once a slot is available, or sheds the request.
+func (l *limiter) serve(c *quick.Ctx, handler func() error) error {
+	if l.cfg.Next != nil && l.cfg.Next(c) {
+		return handler()
+	}
+
+	if !l.sem.acquire(c.Request.Context(), l.cfg.MaxWait) {
+		c.Set("Retry-After", strconv.Itoa(int((l.cfg.RetryAfter+time.Second-1)/time.Second)))
+		return l.cfg.OnShed(c)
+	}
+
+	inflight, _ := l.sem.snapshot()
+	start := time.Now()
+	defer func() {
+		l.sem.release(time.Since(start), inflight)
+	}()
+	return handler()
+}
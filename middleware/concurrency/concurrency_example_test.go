@@ -0,0 +1,38 @@
+package concurrency
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jeffotoni/quick"
+)
+
+// This function is named ExampleNew()
+//
+//	it with the Examples type.
+func ExampleNew() {
+	q := quick.New()
+
+	q.Use(New(Config{
+		Limit:     100,
+		MaxWait:   500 * time.Millisecond,
+		Algorithm: Gradient,
+	}))
+
+	q.Get("/", func(c *quick.Ctx) error {
+		return c.Status(quick.StatusOK).String("served")
+	})
+
+	resp, err := q.Qtest(quick.QuickTestOptions{
+		Method: quick.MethodGet,
+		URI:    "/",
+	})
+	if err != nil {
+		fmt.Println("Test execution error:", err)
+		return
+	}
+	fmt.Println(resp.StatusCode(), resp.BodyStr())
+
+	// Output:
+	// 200 served
+}
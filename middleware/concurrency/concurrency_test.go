@@ -0,0 +1,178 @@
+package concurrency
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jeffotoni/quick"
+)
+
+// blockingApp returns an app whose /slow route waits for release.
+func blockingApp(mw func(http.Handler) http.Handler, release <-chan struct{}) *quick.Quick {
+	q := quick.New()
+	q.Use(mw)
+	q.Get("/slow", func(c *quick.Ctx) error {
+		<-release
+		return c.Status(quick.StatusOK).String("done")
+	})
+	q.Get("/fast", func(c *quick.Ctx) error {
+		return c.Status(quick.StatusOK).String("done")
+	})
+	return q
+}
+
+// serve sends a GET request to q.
+func serve(q *quick.Quick, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	q.ServeHTTP(rec, httptest.NewRequest(quick.MethodGet, path, nil))
+	return rec
+}
+
+// waitInflight waits until the semaphore holds n requests and queued waiters.
+func waitInflight(t *testing.T, s *semaphore, n, queued int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		inflight, waiting := s.inflight, len(s.waiters)
+		s.mu.Unlock()
+		if inflight == n && waiting == queued {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d in flight and %d queued", n, queued)
+}
+
+// TestNewShed verifies that requests over the limit and the queue are shed
+// with 503 and Retry-After, and that queued requests run once a slot frees.
+//
+// go test -v -failfast -count=1 -run ^TestNewShed$
+func TestNewShed(t *testing.T) {
+	l := newLimiter(Config{Limit: 2, MaxQueue: 1, MaxWait: 5 * time.Second, RetryAfter: 3 * time.Second})
+	mw := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c := &quick.Ctx{Response: w, Request: r}
+			err := l.serve(c, func() error {
+				next.ServeHTTP(w, r)
+				return nil
+			})
+			if err != nil {
+				quick.HandleError(c, err)
+			}
+		})
+	}
+	release := make(chan struct{})
+	q := blockingApp(mw, release)
+
+	var wg sync.WaitGroup
+	codes := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- serve(q, "/slow").Code
+		}()
+	}
+	// Two requests are served and one is queued: the next one is shed
+	waitInflight(t, l.sem, 2, 1)
+
+	rec := serve(q, "/fast")
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") != "3" {
+		t.Fatalf("expected 503 with Retry-After 3, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	var e quick.Error
+	if err := json.Unmarshal(rec.Body.Bytes(), &e); err != nil || e.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected a quick.Error payload, got %q", rec.Body.String())
+	}
+
+	close(release)
+	wg.Wait()
+	close(codes)
+	for code := range codes {
+		if code != http.StatusOK {
+			t.Errorf("expected the served and queued requests to succeed, got %d", code)
+		}
+	}
+	if inflight, _ := l.sem.snapshot(); inflight != 0 {
+		t.Errorf("expected no request in flight, got %d", inflight)
+	}
+}
+
+// TestMaxWait verifies that queued requests give up after MaxWait or when
+// the client goes away.
+//
+// go test -v -failfast -count=1 -run ^TestMaxWait$
+func TestMaxWait(t *testing.T) {
+	s := &semaphore{limit: 1, maxQueue: 5}
+	if !s.acquire(context.Background(), time.Second) {
+		t.Fatal("expected the first request to be admitted")
+	}
+
+	start := time.Now()
+	if s.acquire(context.Background(), 20*time.Millisecond) {
+		t.Fatal("expected the queued request to time out")
+	}
+	if time.Since(start) < 20*time.Millisecond {
+		t.Error("expected the request to wait MaxWait")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if s.acquire(ctx, time.Second) {
+		t.Fatal("expected a canceled request to give up")
+	}
+	if len(s.waiters) != 0 {
+		t.Errorf("expected abandoned waiters to leave the queue, got %d", len(s.waiters))
+	}
+
+	done := make(chan bool)
+	go func() { done <- s.acquire(context.Background(), time.Second) }()
+	waitInflight(t, s, 1, 1)
+	s.release(time.Millisecond, 1)
+	if !<-done {
+		t.Error("expected the queued request to take the released slot")
+	}
+}
+
+// TestRoute verifies per-route limits, the custom shed response and Next.
+//
+// go test -v -failfast -count=1 -run ^TestRoute$
+func TestRoute(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+
+	q := quick.New()
+	q.Get("/report", Route(Config{
+		Limit:    1,
+		MaxQueue: -1,
+		Next: func(c *quick.Ctx) bool {
+			return c.Query["admin"] == "1"
+		},
+		OnShed: func(c *quick.Ctx) error {
+			return c.Status(quick.StatusTooManyRequests).String("busy")
+		},
+	})(func(c *quick.Ctx) error {
+		if c.Query["block"] == "1" {
+			started <- struct{}{}
+			<-release
+		}
+		return c.Status(quick.StatusOK).String("report")
+	}))
+
+	go serve(q, "/report?block=1")
+	<-started
+
+	if rec := serve(q, "/report"); rec.Code != http.StatusTooManyRequests || rec.Body.String() != "busy" {
+		t.Errorf("expected the custom shed response, got %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := serve(q, "/report?admin=1"); rec.Code != http.StatusOK {
+		t.Errorf("expected Next to bypass the limit, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,98 @@
+package concurrency
+
+import (
+	"math"
+	"time"
+)
+
+// Algorithm selects how the concurrency limit evolves.
+type Algorithm int
+
+const (
+	// Fixed keeps the limit at Config.Limit.
+	Fixed Algorithm = iota
+
+	// AIMD (additive increase, multiplicative decrease) raises the limit by
+	// one while latency stays under Config.LatencyThreshold and the limit is
+	// in use, and multiplies it by Config.BackoffRatio when a request is slower.
+	AIMD
+
+	// Gradient compares the latency of each request with the long-term
+	// average and shrinks the limit as latency grows, as in Netflix's
+	// concurrency-limits Gradient2. It needs no latency threshold.
+	Gradient
+)
+
+// gradientWindow is the number of samples averaged into the long-term latency.
+const gradientWindow = 600
+
+// adaptive holds the state of the limit algorithm.
+//
+// It is not safe for concurrent use; the semaphore guards it.
+type adaptive struct {
+	algo      Algorithm
+	limit     float64
+	min, max  float64
+	threshold time.Duration // AIMD
+	backoff   float64       // AIMD
+	tolerance float64       // Gradient
+	smoothing float64       // Gradient
+	longRTT   float64       // Gradient, exponential moving average in nanoseconds
+}
+
+// sample updates the limit with the latency of a request that ran while
+// inflight requests (itself included) were being served, and returns the
+// new limit.
+func (a *adaptive) sample(rtt time.Duration, inflight int) int {
+	switch a.algo {
+	case AIMD:
+		a.aimd(rtt, inflight)
+	case Gradient:
+		a.gradient(rtt, inflight)
+	}
+	return int(a.limit)
+}
+
+// aimd applies one AIMD step.
+func (a *adaptive) aimd(rtt time.Duration, inflight int) {
+	switch {
+	case rtt > a.threshold:
+		a.limit *= a.backoff
+	case float64(inflight)*2 >= a.limit:
+		// Only grow when the limit is actually in use
+		a.limit++
+	}
+	a.clamp()
+}
+
+// gradient applies one Gradient step.
+func (a *adaptive) gradient(rtt time.Duration, inflight int) {
+	short := float64(rtt)
+	if short <= 0 {
+		return
+	}
+	if a.longRTT == 0 {
+		a.longRTT = short
+	} else {
+		a.longRTT += (short - a.longRTT) * 2 / (gradientWindow + 1)
+	}
+	// Let the average recover quickly after a sustained latency increase ends
+	if a.longRTT/short > 2 {
+		a.longRTT *= 0.95
+	}
+
+	// An application that does not use its limit says nothing about it
+	if float64(inflight) < a.limit/2 {
+		return
+	}
+
+	gradient := math.Max(0.5, math.Min(1, a.tolerance*a.longRTT/short))
+	next := a.limit*gradient + math.Sqrt(a.limit)
+	a.limit = a.limit*(1-a.smoothing) + next*a.smoothing
+	a.clamp()
+}
+
+// clamp keeps the limit within its bounds.
+func (a *adaptive) clamp() {
+	a.limit = math.Max(a.min, math.Min(a.max, a.limit))
+}
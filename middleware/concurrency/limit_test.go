@@ -0,0 +1,62 @@
+package concurrency
+
+import (
+	"testing"
+	"time"
+)
+
+// go test -v -failfast -count=1 -run ^TestAIMD$
+func TestAIMD(t *testing.T) {
+	a := &adaptive{algo: AIMD, limit: 10, min: 1, max: 12, threshold: 100 * time.Millisecond, backoff: 0.5}
+
+	// Fast requests while the limit is in use raise it, up to max
+	for i := 0; i < 5; i++ {
+		a.sample(10*time.Millisecond, 8)
+	}
+	if got := a.sample(10*time.Millisecond, 8); got != 12 {
+		t.Errorf("expected the limit to grow to 12, got %d", got)
+	}
+	// Fast requests with an idle limit leave it alone
+	if got := a.sample(10*time.Millisecond, 1); got != 12 {
+		t.Errorf("expected the limit to stay at 12, got %d", got)
+	}
+	// A slow request halves it, down to min
+	if got := a.sample(time.Second, 8); got != 6 {
+		t.Errorf("expected the limit to drop to 6, got %d", got)
+	}
+	for i := 0; i < 10; i++ {
+		a.sample(time.Second, 8)
+	}
+	if got := a.sample(time.Second, 8); got != 1 {
+		t.Errorf("expected the limit to stop at 1, got %d", got)
+	}
+}
+
+// go test -v -failfast -count=1 -run ^TestGradient$
+func TestGradient(t *testing.T) {
+	a := &adaptive{algo: Gradient, limit: 50, min: 5, max: 200, tolerance: 2, smoothing: 0.2}
+
+	// Steady latency with the limit in use: the limit grows
+	for i := 0; i < 50; i++ {
+		a.sample(20*time.Millisecond, int(a.limit))
+	}
+	grown := int(a.limit)
+	if grown <= 50 {
+		t.Fatalf("expected the limit to grow above 50, got %d", grown)
+	}
+
+	// Latency well above the tolerated average: the limit shrinks
+	for i := 0; i < 20; i++ {
+		a.sample(200*time.Millisecond, int(a.limit))
+	}
+	if int(a.limit) >= grown {
+		t.Errorf("expected the limit to shrink below %d, got %d", grown, int(a.limit))
+	}
+
+	// An idle application does not move the limit
+	before := a.limit
+	a.sample(time.Second, 1)
+	if a.limit != before {
+		t.Errorf("expected the limit to stay at %v, got %v", before, a.limit)
+	}
+}
@@ -0,0 +1,80 @@
+package concurrency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// semaphore admits up to limit requests at once and queues up to maxQueue
+// more, in arrival order.
+type semaphore struct {
+	mu       sync.Mutex
+	limit    int
+	inflight int
+	maxQueue int
+	waiters  []chan struct{}
+	adaptive *adaptive
+}
+
+// acquire takes a slot, waiting up to maxWait in the queue.
+//
+// It reports whether the request was admitted.
+func (s *semaphore) acquire(ctx context.Context, maxWait time.Duration) bool {
+	s.mu.Lock()
+	if s.inflight < s.limit {
+		s.inflight++
+		s.mu.Unlock()
+		return true
+	}
+	if len(s.waiters) >= s.maxQueue || maxWait <= 0 {
+		s.mu.Unlock()
+		return false
+	}
+	ready := make(chan struct{})
+	s.waiters = append(s.waiters, ready)
+	s.mu.Unlock()
+
+	timer := time.NewTimer(maxWait)
+	defer timer.Stop()
+	select {
+	case <-ready:
+		return true
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, w := range s.waiters {
+		if w == ready {
+			s.waiters = append(s.waiters[:i], s.waiters[i+1:]...)
+			return false
+		}
+	}
+	// The slot was handed over while giving up: keep it
+	return true
+}
+
+// release frees a slot and records the latency of the request that held it.
+func (s *semaphore) release(rtt time.Duration, inflight int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.adaptive != nil {
+		s.limit = s.adaptive.sample(rtt, inflight)
+	}
+	s.inflight--
+	// Hand slots to waiters while the limit allows it
+	for s.inflight < s.limit && len(s.waiters) > 0 {
+		close(s.waiters[0])
+		s.waiters = s.waiters[1:]
+		s.inflight++
+	}
+}
+
+// snapshot returns the number of requests in flight and the current limit.
+func (s *semaphore) snapshot() (inflight, limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inflight, s.limit
+}
@@ -0,0 +1,133 @@
+package csrf
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/jeffotoni/quick"
+)
+
+// Mode selects where the expected token is kept between requests.
+type Mode int
+
+const (
+	// ModeDoubleSubmit keeps the token in a cookie and expects the same value
+	// in the request. It needs no server-side state.
+	ModeDoubleSubmit Mode = iota
+
+	// ModeSynchronizer keeps the token in the session (middleware/session),
+	// which must be registered before the CSRF middleware.
+	ModeSynchronizer
+)
+
+// Config defines the configuration options for the CSRF middleware.
+type Config struct {
+	// Mode selects the double-submit cookie or the synchronizer token pattern.
+	// Default is ModeDoubleSubmit.
+	Mode Mode
+
+	// TokenLookup lists where the token is read from, as comma-separated
+	// "source:name" pairs tried in order. Sources are header, form and query.
+	// Default is "header:X-CSRF-Token,form:_csrf".
+	TokenLookup string
+
+	// CookieName is the name of the token cookie in double-submit mode.
+	// Default is "csrf_".
+	CookieName string
+
+	// CookiePath is the path of the token cookie. Default is "/".
+	CookiePath string
+
+	// CookieDomain is the domain of the token cookie.
+	CookieDomain string
+
+	// CookieSecure sends the token cookie only over HTTPS.
+	CookieSecure bool
+
+	// CookieHTTPOnly hides the token cookie from JavaScript. Leave it false
+	// when scripts read the cookie to send the token in a header.
+	CookieHTTPOnly bool
+
+	// CookieSameSite is the SameSite attribute of the token cookie.
+	// Default is http.SameSiteLaxMode.
+	CookieSameSite http.SameSite
+
+	// Expiration is the lifetime of the token cookie. Default is 12 hours.
+	Expiration time.Duration
+
+	// Keys signs the double-submit token with HMAC, so that a cookie planted
+	// by a sibling subdomain cannot be used. Optional, but recommended.
+	Keys *quick.KeyRing
+
+	// SessionKey is the session key holding the token in synchronizer mode.
+	// Default is "csrf_token".
+	SessionKey string
+
+	// SafeMethods are not checked. Default is GET, HEAD, OPTIONS and TRACE.
+	SafeMethods []string
+
+	// TrustedOrigins are origins, besides the request host, allowed to send
+	// unsafe requests (e.g. "https://app.example.com").
+	TrustedOrigins []string
+
+	// DisableOriginCheck skips the Origin and Referer checks.
+	DisableOriginCheck bool
+
+	// Next is a function that determines whether to skip the middleware.
+	Next func(c *quick.Ctx) bool
+
+	// ErrorHandler is called when the check fails. The default handler
+	// returns a 403 Forbidden problem.
+	ErrorHandler func(c *quick.Ctx, err error) error
+}
+
+// defaultConfig returns the default configuration for the CSRF middleware.
+var defaultConfig = Config{
+	Mode:           ModeDoubleSubmit,
+	TokenLookup:    "header:" + HeaderName + ",form:" + FormField,
+	CookieName:     "csrf_",
+	CookiePath:     "/",
+	CookieSameSite: http.SameSiteLaxMode,
+	Expiration:     12 * time.Hour,
+	SessionKey:     "csrf_token",
+	SafeMethods:    []string{quick.MethodGet, quick.MethodHead, quick.MethodOptions, quick.MethodTrace},
+	ErrorHandler:   defaultErrorHandler,
+}
+
+// defaultErrorHandler rejects the request with 403 Forbidden.
+func defaultErrorHandler(_ *quick.Ctx, err error) error {
+	return quick.ProblemForbidden(err.Error()).Wrap(err)
+}
+
+// configDefault applies the defaults to empty fields.
+func configDefault(config ...Config) Config {
+	if len(config) == 0 {
+		return defaultConfig
+	}
+	cfg := config[0]
+	if cfg.TokenLookup == "" {
+		cfg.TokenLookup = defaultConfig.TokenLookup
+	}
+	if cfg.CookieName == "" {
+		cfg.CookieName = defaultConfig.CookieName
+	}
+	if cfg.CookiePath == "" {
+		cfg.CookiePath = defaultConfig.CookiePath
+	}
+	if cfg.CookieSameSite == 0 {
+		cfg.CookieSameSite = defaultConfig.CookieSameSite
+	}
+	if cfg.Expiration <= 0 {
+		cfg.Expiration = defaultConfig.Expiration
+	}
+	if cfg.SessionKey == "" {
+		cfg.SessionKey = defaultConfig.SessionKey
+	}
+	if len(cfg.SafeMethods) == 0 {
+		cfg.SafeMethods = defaultConfig.SafeMethods
+	}
+	if cfg.ErrorHandler == nil {
+		cfg.ErrorHandler = defaultConfig.ErrorHandler
+	}
+	return cfg
+}
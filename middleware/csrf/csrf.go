@@ -0,0 +1,352 @@
+// Package csrf provides Cross-Site Request Forgery protection middleware for Quick.
+//
+// Requests with an unsafe method (POST, PUT, PATCH, DELETE, ...) must carry a
+// token issued by the server, read from a header, a form field or the query
+// string. Two patterns are supported:
+//
+//   - Double-submit cookie (default): the token is sent in a cookie and must be
+//     echoed in the request. No server state; optionally signed with a quick.KeyRing.
+//   - Synchronizer token: the token is kept in the session (middleware/session).
+//
+// The Origin header (or the Referer, when Origin is absent) of unsafe
+// requests must also match the request host or a trusted origin.
+//
+// Features:
+//   - Token extraction from header, form or query.
+//   - Safe-method exemptions.
+//   - Origin/Referer checks.
+//   - Template function emitting the hidden input.
+//   - Configurable failure handler, returning 403 Forbidden by default.
+package csrf
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"html/template"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jeffotoni/quick"
+	"github.com/jeffotoni/quick/internal/lookup"
+	"github.com/jeffotoni/quick/middleware/session"
+)
+
+// Default token locations.
+const (
+	HeaderName = "X-CSRF-Token"
+	FormField  = "_csrf"
+)
+
+// Errors passed to Config.ErrorHandler.
+var (
+	// ErrTokenMissing is returned when the request carries no token.
+	ErrTokenMissing = errors.New("csrf: token missing")
+	// ErrTokenInvalid is returned when the token does not match the expected one.
+	ErrTokenInvalid = errors.New("csrf: token invalid")
+	// ErrOriginMismatch is returned when the Origin or Referer is not trusted.
+	ErrOriginMismatch = errors.New("csrf: origin not allowed")
+	// ErrNoSession is returned in synchronizer mode when the session middleware is missing.
+	ErrNoSession = errors.New("csrf: synchronizer mode requires the session middleware")
+)
+
+// contextKey is the request context key holding the *tokenInfo.
+type contextKey struct{}
+
+// tokenInfo is the token of the current request and the form field expected to carry it.
+type tokenInfo struct {
+	token string
+	field string
+}
+
+// New creates the CSRF middleware.
+//
+// Parameters:
+//   - config ...Config (optional): Custom configuration; empty fields use the defaults.
+//
+// Returns:
+//   - func(quick.Handler) quick.Handler: The middleware.
+//
+// Example Usage:
+//
+//	q.Use(csrf.New(csrf.Config{
+//	    Keys:         quick.NewKeyRing([]byte(os.Getenv("CSRF_KEY"))),
+//	    CookieSecure: true,
+//	}))
+//
+//	q.Get("/form", func(c *quick.Ctx) error {
+//	    return c.HTML("form", map[string]any{"csrf": csrf.Token(c)})
+//	})
+func New(config ...Config) func(quick.Handler) quick.Handler {
+	cfg := configDefault(config...)
+
+	extractors, field := parseLookup(cfg.TokenLookup)
+
+	safe := make(map[string]struct{}, len(cfg.SafeMethods))
+	for _, m := range cfg.SafeMethods {
+		safe[strings.ToUpper(m)] = struct{}{}
+	}
+
+	trusted := make(map[string]struct{}, len(cfg.TrustedOrigins))
+	for _, o := range cfg.TrustedOrigins {
+		trusted[strings.ToLower(strings.TrimSuffix(o, "/"))] = struct{}{}
+	}
+
+	return func(next quick.Handler) quick.Handler {
+		return quick.HandlerFunc(func(c *quick.Ctx) error {
+			// Skip middleware if Next returns true
+			if cfg.Next != nil && cfg.Next(c) {
+				return next.ServeQuick(c)
+			}
+
+			var sess *session.Session
+			if cfg.Mode == ModeSynchronizer {
+				if sess = session.Get(c); sess == nil {
+					return ErrNoSession
+				}
+			}
+
+			expected := storedToken(c, &cfg, sess)
+
+			if _, ok := safe[c.Method()]; !ok {
+				if !cfg.DisableOriginCheck && !originAllowed(c.Request, trusted) {
+					return cfg.ErrorHandler(c, ErrOriginMismatch)
+				}
+				if err := verify(c, extractors, expected); err != nil {
+					return cfg.ErrorHandler(c, err)
+				}
+			}
+
+			// Issue a token on first use; it then stays valid until it expires
+			token := expected
+			if token == "" {
+				var err error
+				if token, err = issueToken(c, &cfg, sess); err != nil {
+					return err
+				}
+			}
+
+			c.Append("Vary", "Cookie")
+			info := &tokenInfo{token: token, field: field}
+			c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), contextKey{}, info))
+			return next.ServeQuick(c)
+		})
+	}
+}
+
+// Token returns the CSRF token of the request, to be embedded in forms or
+// exposed to scripts.
+//
+// Parameters:
+//   - c: The Quick context.
+//
+// Returns:
+//   - string: The token, or "" if the CSRF middleware is not installed.
+func Token(c *quick.Ctx) string {
+	if info, ok := c.Request.Context().Value(contextKey{}).(*tokenInfo); ok {
+		return info.token
+	}
+	return ""
+}
+
+// Field returns the hidden input carrying the CSRF token of the request.
+//
+// Parameters:
+//   - c: The Quick context.
+//
+// Returns:
+//   - template.HTML: The input element, named after the form source of TokenLookup.
+//
+// Example Usage:
+//
+//	return c.HTML("form", map[string]any{"csrfField": csrf.Field(c)})
+//
+//	<form method="post">{{ .csrfField }} ... </form>
+func Field(c *quick.Ctx) template.HTML {
+	info, ok := c.Request.Context().Value(contextKey{}).(*tokenInfo)
+	if !ok {
+		return ""
+	}
+	return hiddenInput(info.field, info.token)
+}
+
+// TemplateFunc returns a template function that renders the hidden input for a token.
+//
+// Parameters:
+//   - field: The form field name; defaults to "_csrf". It must match the form source of TokenLookup.
+//
+// Returns:
+//   - func(token string) template.HTML: The template function.
+//
+// Example Usage:
+//
+//	engine := html.New("./views", ".html")
+//	engine.AddFunc("csrfField", csrf.TemplateFunc())
+//
+//	<form method="post">{{ csrfField .csrf }} ... </form>
+func TemplateFunc(field ...string) func(token string) template.HTML {
+	name := FormField
+	if len(field) > 0 && field[0] != "" {
+		name = field[0]
+	}
+	return func(token string) template.HTML {
+		return hiddenInput(name, token)
+	}
+}
+
+// hiddenInput renders an escaped hidden input element.
+func hiddenInput(field, token string) template.HTML {
+	return template.HTML(`<input type="hidden" name="` + template.HTMLEscapeString(field) +
+		`" value="` + template.HTMLEscapeString(token) + `">`)
+}
+
+// parseLookup builds the extractors of TokenLookup and returns the form field name.
+// Cookies are not a source: the token must be echoed by the page.
+func parseLookup(tokenLookup string) ([]lookup.Extractor, string) {
+	field := FormField
+	extractors := lookup.Parse(tokenLookup, map[string]lookup.Source{
+		"header": lookup.Header(""),
+		"form": func(name string) lookup.Extractor {
+			field = name
+			return func(c *quick.Ctx) string {
+				return formValue(c, name)
+			}
+		},
+		"query": lookup.Query,
+	})
+	return extractors, field
+}
+
+// formValue reads a field of an urlencoded or multipart body. Other bodies are
+// left untouched. The body is read once, up to the MaxBodySize of the app, and
+// restored so that c.Body, BodyParser and Bind still see it.
+func formValue(c *quick.Ctx, name string) string {
+	r := c.Request
+	mediaType, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != quick.ContentTypeForm && mediaType != quick.ContentTypeMultipart {
+		return ""
+	}
+	if r.Body == nil || r.Body == http.NoBody {
+		return ""
+	}
+
+	limit := int64(2 << 20)
+	if c.App != nil && c.App.GetConfig().MaxBodySize > 0 {
+		limit = c.App.GetConfig().MaxBodySize
+	}
+	buf, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
+	// Put back what was read, followed by whatever is left
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(buf), r.Body), r.Body}
+	if err != nil || int64(len(buf)) > limit {
+		return ""
+	}
+
+	if mediaType == quick.ContentTypeForm {
+		values, _ := url.ParseQuery(string(buf))
+		return values.Get(name)
+	}
+	form, err := multipart.NewReader(bytes.NewReader(buf), params["boundary"]).ReadForm(limit)
+	if err != nil {
+		return ""
+	}
+	defer form.RemoveAll()
+	if v := form.Value[name]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// storedToken returns the token issued to the client, or "" if there is none.
+func storedToken(c *quick.Ctx, cfg *Config, sess *session.Session) string {
+	if sess != nil {
+		token, _ := sess.Get(cfg.SessionKey).(string)
+		return token
+	}
+	token := c.Cookie(cfg.CookieName)
+	if token != "" && cfg.Keys != nil {
+		if _, err := cfg.Keys.Verify(cfg.CookieName, token); err != nil {
+			return ""
+		}
+	}
+	return token
+}
+
+// issueToken generates a token and stores it in the session or in the cookie.
+func issueToken(c *quick.Ctx, cfg *Config, sess *session.Session) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	if sess != nil {
+		sess.Set(cfg.SessionKey, token)
+		return token, nil
+	}
+
+	if cfg.Keys != nil {
+		signed, err := cfg.Keys.Sign(cfg.CookieName, token)
+		if err != nil {
+			return "", err
+		}
+		token = signed
+	}
+	c.SetCookie(&http.Cookie{
+		Name:     cfg.CookieName,
+		Value:    token,
+		Path:     cfg.CookiePath,
+		Domain:   cfg.CookieDomain,
+		Expires:  time.Now().Add(cfg.Expiration),
+		MaxAge:   int(cfg.Expiration.Seconds()),
+		Secure:   cfg.CookieSecure,
+		HttpOnly: cfg.CookieHTTPOnly,
+		SameSite: cfg.CookieSameSite,
+	})
+	return token, nil
+}
+
+// verify compares the token carried by the request with the expected one.
+func verify(c *quick.Ctx, extractors []lookup.Extractor, expected string) error {
+	token := lookup.Extract(c, extractors)
+	if token == "" {
+		return ErrTokenMissing
+	}
+	if expected == "" || subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+		return ErrTokenInvalid
+	}
+	return nil
+}
+
+// originAllowed checks the Origin header, or the Referer when Origin is absent,
+// against the request host and the trusted origins. Requests carrying neither
+// are allowed, the token check still applies.
+func originAllowed(r *http.Request, trusted map[string]struct{}) bool {
+	source := r.Header.Get("Origin")
+	if source == "" {
+		source = r.Header.Get("Referer")
+		if source == "" {
+			return true
+		}
+	}
+	u, err := url.Parse(source)
+	if err != nil || u.Host == "" {
+		// Includes the opaque "null" origin sent by sandboxed documents
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	_, ok := trusted[strings.ToLower(u.Scheme+"://"+u.Host)]
+	return ok
+}
@@ -0,0 +1,55 @@
+package csrf
+
+import (
+	"fmt"
+
+	"github.com/jeffotoni/quick"
+)
+
+// This function is named ExampleNew()
+//
+//	it with the Examples type.
+func ExampleNew() {
+	q := quick.New()
+
+	// Apply the CSRF middleware before registering routes
+	q.Use(New())
+
+	q.Get("/form", func(c *quick.Ctx) error {
+		return c.Status(quick.StatusOK).String(Token(c))
+	})
+	q.Post("/transfer", func(c *quick.Ctx) error {
+		return c.Status(quick.StatusOK).String("transfer done")
+	})
+
+	// A POST without token is rejected
+	resp, err := q.Qtest(quick.QuickTestOptions{
+		Method: quick.MethodPost,
+		URI:    "/transfer",
+	})
+	if err != nil {
+		fmt.Println("Test execution error:", err)
+		return
+	}
+	fmt.Println(resp.StatusCode())
+
+	// Fetch a token, then send it back in the cookie and the header
+	resp, _ = q.Qtest(quick.QuickTestOptions{
+		Method: quick.MethodGet,
+		URI:    "/form",
+	})
+	token := resp.BodyStr()
+	resp, _ = q.Qtest(quick.QuickTestOptions{
+		Method: quick.MethodPost,
+		URI:    "/transfer",
+		Headers: map[string]string{
+			"Cookie":       "csrf_=" + token,
+			"X-CSRF-Token": token,
+		},
+	})
+	fmt.Println(resp.StatusCode(), resp.BodyStr())
+
+	// Output:
+	// 403
+	// 200 transfer done
+}
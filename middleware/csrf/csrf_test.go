@@ -0,0 +1,265 @@
+package csrf
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jeffotoni/quick"
+	"github.com/jeffotoni/quick/middleware/session"
+)
+
+// newTestApp registers a page issuing the token and a protected POST route.
+func newTestApp(cfg Config, mws ...any) *quick.Quick {
+	q := quick.New(quick.Config{MaxBodySize: 1 << 20})
+	for _, mw := range mws {
+		q.Use(mw)
+	}
+	q.Use(New(cfg))
+	q.Get("/form", func(c *quick.Ctx) error {
+		return c.Status(quick.StatusOK).String(Token(c))
+	})
+	q.Post("/submit", func(c *quick.Ctx) error {
+		return c.Status(quick.StatusOK).String("ok " + c.FormValue("name"))
+	})
+	return q
+}
+
+// request serves a request with optional body, headers and cookies.
+func request(q *quick.Quick, method, target, body string, header http.Header, cookies []*http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	for _, ck := range cookies {
+		req.AddCookie(ck)
+	}
+	rec := httptest.NewRecorder()
+	q.ServeHTTP(rec, req)
+	return rec
+}
+
+// go test -v -failfast -count=1 -run ^TestDoubleSubmit$
+func TestDoubleSubmit(t *testing.T) {
+	q := newTestApp(Config{})
+
+	rec := request(q, quick.MethodGet, "/form", "", nil, nil)
+	token := rec.Body.String()
+	cookies := rec.Result().Cookies()
+	if token == "" || len(cookies) != 1 || cookies[0].Value != token {
+		t.Fatalf("expected the token in body and cookie, got %q %v", token, cookies)
+	}
+
+	tests := []struct {
+		name    string
+		header  http.Header
+		body    string
+		cookies []*http.Cookie
+		want    int
+	}{
+		{"no_token", nil, "", cookies, http.StatusForbidden},
+		{"no_cookie", http.Header{"X-Csrf-Token": {token}}, "", nil, http.StatusForbidden},
+		{"wrong_token", http.Header{"X-Csrf-Token": {"forged"}}, "", cookies, http.StatusForbidden},
+		{"header", http.Header{"X-Csrf-Token": {token}}, "", cookies, http.StatusOK},
+		{
+			"form",
+			http.Header{"Content-Type": {quick.ContentTypeForm}},
+			url.Values{"_csrf": {token}, "name": {"quick"}}.Encode(),
+			cookies,
+			http.StatusOK,
+		},
+		{
+			"same_origin",
+			http.Header{"X-Csrf-Token": {token}, "Origin": {"http://example.com"}},
+			"", cookies, http.StatusOK,
+		},
+		{
+			"cross_origin",
+			http.Header{"X-Csrf-Token": {token}, "Origin": {"https://evil.test"}},
+			"", cookies, http.StatusForbidden,
+		},
+		{
+			"cross_referer",
+			http.Header{"X-Csrf-Token": {token}, "Referer": {"https://evil.test/page"}},
+			"", cookies, http.StatusForbidden,
+		},
+		{
+			"null_origin",
+			http.Header{"X-Csrf-Token": {token}, "Origin": {"null"}},
+			"", cookies, http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := request(q, quick.MethodPost, "/submit", tt.body, tt.header, tt.cookies)
+			if rec.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	// the form body is still readable by the handler
+	rec = request(q, quick.MethodPost, "/submit",
+		url.Values{"_csrf": {token}, "name": {"quick"}}.Encode(),
+		http.Header{"Content-Type": {quick.ContentTypeForm}}, cookies)
+	if rec.Body.String() != "ok quick" {
+		t.Errorf("expected %q, got %q", "ok quick", rec.Body.String())
+	}
+}
+
+// go test -v -failfast -count=1 -run ^TestSignedDoubleSubmit$
+func TestSignedDoubleSubmit(t *testing.T) {
+	q := newTestApp(Config{Keys: quick.NewKeyRing([]byte("csrf-secret"))})
+
+	rec := request(q, quick.MethodGet, "/form", "", nil, nil)
+	token := rec.Body.String()
+	cookies := rec.Result().Cookies()
+	if rec := request(q, quick.MethodPost, "/submit", "", http.Header{"X-Csrf-Token": {token}}, cookies); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	// a cookie planted by an attacker is not signed with the key ring
+	planted := []*http.Cookie{{Name: "csrf_", Value: "attacker"}}
+	if rec := request(q, quick.MethodPost, "/submit", "", http.Header{"X-Csrf-Token": {"attacker"}}, planted); rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for an unsigned cookie, got %d", rec.Code)
+	}
+}
+
+// go test -v -failfast -count=1 -run ^TestSynchronizer$
+func TestSynchronizer(t *testing.T) {
+	q := newTestApp(Config{Mode: ModeSynchronizer, TokenLookup: "query:csrf"}, session.New())
+
+	rec := request(q, quick.MethodGet, "/form", "", nil, nil)
+	token := rec.Body.String()
+	cookies := rec.Result().Cookies()
+	if token == "" || len(cookies) != 1 || cookies[0].Name != session.DefaultCookieName {
+		t.Fatalf("expected a token kept in the session, got %q %v", token, cookies)
+	}
+
+	if rec := request(q, quick.MethodPost, "/submit?csrf="+token, "", nil, cookies); rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := request(q, quick.MethodPost, "/submit?csrf="+token, "", nil, nil); rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403 without the session, got %d", rec.Code)
+	}
+
+	// without the session middleware the request fails
+	q = newTestApp(Config{Mode: ModeSynchronizer})
+	if rec := request(q, quick.MethodGet, "/form", "", nil, nil); rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500 without session middleware, got %d", rec.Code)
+	}
+}
+
+// go test -v -failfast -count=1 -run ^TestFormBody$
+func TestFormBody(t *testing.T) {
+	q := quick.New(quick.Config{MaxBodySize: 1 << 20})
+	q.Use(New())
+	q.Get("/form", func(c *quick.Ctx) error {
+		return c.Status(quick.StatusOK).String(Token(c))
+	})
+	q.Post("/bind", func(c *quick.Ctx) error {
+		var in struct {
+			Name string `form:"name"`
+		}
+		if err := c.Bind(&in); err != nil {
+			return err
+		}
+		return c.Status(quick.StatusOK).String(in.Name + " " + c.BodyString())
+	})
+
+	rec := request(q, quick.MethodGet, "/form", "", nil, nil)
+	token, cookies := rec.Body.String(), rec.Result().Cookies()
+
+	t.Run("urlencoded", func(t *testing.T) {
+		body := url.Values{"_csrf": {token}, "name": {"quick"}}.Encode()
+		rec := request(q, quick.MethodPost, "/bind", body,
+			http.Header{"Content-Type": {quick.ContentTypeForm}}, cookies)
+		if want := "quick " + body; rec.Code != http.StatusOK || rec.Body.String() != want {
+			t.Errorf("expected %q, got %d %q", want, rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("multipart", func(t *testing.T) {
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		_ = mw.WriteField("_csrf", token)
+		_ = mw.WriteField("name", "quick")
+		_ = mw.Close()
+		rec := request(q, quick.MethodPost, "/bind", buf.String(),
+			http.Header{"Content-Type": {mw.FormDataContentType()}}, cookies)
+		if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Body.String(), "quick --") {
+			t.Errorf("expected the form to be bound, got %d %q", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("too_large", func(t *testing.T) {
+		q := quick.New(quick.Config{MaxBodySize: 64})
+		q.Use(New())
+		q.Post("/bind", func(c *quick.Ctx) error {
+			return c.Status(quick.StatusOK).String("ok")
+		})
+		body := url.Values{"pad": {strings.Repeat("a", 64)}, "_csrf": {token}}.Encode()
+		req := httptest.NewRequest(quick.MethodPost, "/bind", strings.NewReader(body))
+		req.ContentLength = -1
+		req.Header.Set("Content-Type", quick.ContentTypeForm)
+		for _, ck := range cookies {
+			req.AddCookie(ck)
+		}
+		rec := httptest.NewRecorder()
+		q.ServeHTTP(rec, req)
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("expected a form over MaxBodySize to be ignored, got %d", rec.Code)
+		}
+	})
+}
+
+// go test -v -failfast -count=1 -run ^TestOptions$
+func TestOptions(t *testing.T) {
+	var got error
+	q := newTestApp(Config{
+		TrustedOrigins: []string{"https://app.example.com"},
+		Next: func(c *quick.Ctx) bool {
+			return c.Request.Header.Get("X-Skip") != ""
+		},
+		ErrorHandler: func(c *quick.Ctx, err error) error {
+			got = err
+			return c.Status(quick.StatusTeapot).String("custom")
+		},
+	})
+	rec := request(q, quick.MethodGet, "/form", "", nil, nil)
+	token, cookies := rec.Body.String(), rec.Result().Cookies()
+
+	rec = request(q, quick.MethodPost, "/submit", "", nil, nil)
+	if rec.Code != quick.StatusTeapot || !errors.Is(got, ErrTokenMissing) {
+		t.Errorf("expected custom handler with ErrTokenMissing, got %d %v", rec.Code, got)
+	}
+	rec = request(q, quick.MethodPost, "/submit", "", http.Header{"X-Csrf-Token": {token}, "Origin": {"https://app.example.com"}}, cookies)
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected trusted origin to pass, got %d", rec.Code)
+	}
+	if rec := request(q, quick.MethodPost, "/submit", "", http.Header{"X-Skip": {"1"}}, nil); rec.Code != http.StatusOK {
+		t.Errorf("expected Next to skip the check, got %d", rec.Code)
+	}
+}
+
+// go test -v -failfast -count=1 -run ^TestTemplateHelpers$
+func TestTemplateHelpers(t *testing.T) {
+	if got := TemplateFunc()(`a"b`); got != `<input type="hidden" name="_csrf" value="a&#34;b">` {
+		t.Errorf("unexpected input: %s", got)
+	}
+
+	q := quick.New()
+	q.Use(New(Config{TokenLookup: "form:token"}))
+	q.Get("/", func(c *quick.Ctx) error {
+		return c.Status(quick.StatusOK).String(string(Field(c)))
+	})
+	body := request(q, quick.MethodGet, "/", "", nil, nil).Body.String()
+	if !strings.HasPrefix(body, `<input type="hidden" name="token" value="`) {
+		t.Errorf("unexpected field: %s", body)
+	}
+}
@@ -0,0 +1,216 @@
+// Package decompress provides middleware that inflates compressed request bodies.
+//
+// Clients uploading large payloads often send them with Content-Encoding:
+// gzip. Quick reads request bodies as is, so without this middleware
+// c.Body() and c.Bind() would see the compressed bytes. decompress inflates
+// the body before the handler runs and removes Content-Encoding, so the rest
+// of the application never notices the request was compressed.
+//
+// Features:
+//   - gzip and deflate built in; zstd and others through Config.Decoders.
+//   - MaxBodySize applies to the decompressed size, which protects against
+//     zip bombs, and to the compressed size.
+//   - 415 Unsupported Media Type, with Accept-Encoding, for unknown codings.
+//   - 400 Bad Request for corrupt bodies.
+package decompress
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jeffotoni/quick"
+)
+
+// Decoder returns a reader that decompresses r with one content coding.
+type Decoder func(r io.Reader) (io.ReadCloser, error)
+
+// Config defines the configuration options for the decompress middleware.
+type Config struct {
+	// MaxBodySize is the largest body accepted, in bytes, both compressed
+	// and decompressed. Larger bodies are rejected with 413.
+	// Default is 2MB, the default MaxBodySize of quick.Config.
+	MaxBodySize int64
+
+	// Decoders adds or replaces decoders by content coding. gzip, x-gzip
+	// and deflate are built in; zstd is not part of the standard library
+	// and is only accepted when a decoder is provided here.
+	Decoders map[string]Decoder
+
+	// Next is a function that determines whether to skip the middleware.
+	Next func(c *quick.Ctx) bool
+}
+
+// ConfigDefault is the default configuration.
+var ConfigDefault = Config{
+	MaxBodySize: 2 * 1024 * 1024,
+}
+
+// builtinDecoders are the decoders of the standard library.
+var builtinDecoders = map[string]Decoder{
+	"gzip":    gzipDecoder,
+	"x-gzip":  gzipDecoder,
+	"deflate": deflateDecoder,
+}
+
+// gzipDecoder decodes the gzip content coding.
+func gzipDecoder(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// deflateDecoder decodes the deflate content coding, which is the zlib
+// format (RFC 9110, 8.4.1.2). Some clients send raw DEFLATE instead; it is
+// accepted too.
+func deflateDecoder(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+	// A zlib header uses method 8 and is a multiple of 31 (RFC 1950)
+	if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
+
+// New creates the decompress middleware.
+//
+// It must run before the body is read, so register it with q.Use or
+// Group.Use before the routes it applies to.
+//
+// Parameters:
+//   - config: Optional configuration; ConfigDefault is used when omitted.
+//
+// Returns:
+//   - func(http.Handler) http.Handler: The middleware, usable with q.Use and Group.Use.
+//
+// Example Usage:
+//
+//	q.Use(decompress.New(decompress.Config{
+//	    MaxBodySize: 10 << 20,
+//	}))
+func New(config ...Config) func(http.Handler) http.Handler {
+	cfg := ConfigDefault
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = ConfigDefault.MaxBodySize
+	}
+
+	decoders := make(map[string]Decoder, len(builtinDecoders)+len(cfg.Decoders))
+	for name, dec := range builtinDecoders {
+		decoders[name] = dec
+	}
+	for name, dec := range cfg.Decoders {
+		decoders[strings.ToLower(name)] = dec
+	}
+	supported := make([]string, 0, len(decoders))
+	for _, name := range []string{"gzip", "deflate", "zstd", "br"} {
+		if decoders[name] != nil {
+			supported = append(supported, name)
+		}
+	}
+	accept := strings.Join(supported, ", ")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c := &quick.Ctx{Response: w, Request: r}
+			if cfg.Next != nil && cfg.Next(c) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			codings := parseCodings(r.Header.Values("Content-Encoding"))
+			if len(codings) == 0 || r.Body == nil || r.Body == http.NoBody {
+				next.ServeHTTP(w, r)
+				return
+			}
+			for _, coding := range codings {
+				if decoders[coding] == nil {
+					c.Set("Accept-Encoding", accept)
+					quick.HandleError(c, quick.NewError(quick.StatusUnsupportedMediaType,
+						"unsupported Content-Encoding: "+coding))
+					return
+				}
+			}
+
+			body, err := decode(w, r.Body, codings, decoders, cfg.MaxBodySize)
+			if err != nil {
+				code := quick.StatusBadRequest
+				msg := "invalid compressed body"
+				if errors.Is(err, errTooLarge) {
+					code = quick.StatusRequestEntityTooLarge
+					msg = "Request body too large"
+				}
+				quick.HandleError(c, quick.NewError(code, msg))
+				return
+			}
+
+			r = r.Clone(r.Context())
+			r.Header.Del("Content-Encoding")
+			r.Header.Set("Content-Length", strconv.Itoa(len(body)))
+			r.ContentLength = int64(len(body))
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// errTooLarge is returned by decode when a body exceeds MaxBodySize.
+var errTooLarge = errors.New("decompress: body too large")
+
+// decode undoes codings, listed in the order they were applied, and reads
+// at most limit decompressed bytes.
+func decode(w http.ResponseWriter, body io.ReadCloser, codings []string, decoders map[string]Decoder, limit int64) ([]byte, error) {
+	var r io.Reader = http.MaxBytesReader(w, body, limit)
+	for i := len(codings) - 1; i >= 0; i-- {
+		dr, err := decoders[codings[i]](r)
+		if err != nil {
+			return nil, classify(err)
+		}
+		defer dr.Close()
+		r = dr
+	}
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(io.LimitReader(r, limit+1)); err != nil {
+		return nil, classify(err)
+	}
+	if int64(buf.Len()) > limit {
+		return nil, errTooLarge
+	}
+	return buf.Bytes(), nil
+}
+
+// classify reports a compressed body over the limit as errTooLarge.
+func classify(err error) error {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return errTooLarge
+	}
+	return err
+}
+
+// parseCodings returns the content codings of a Content-Encoding header,
+// lowercased and without identity.
+func parseCodings(values []string) []string {
+	var codings []string
+	for _, v := range values {
+		for _, coding := range strings.Split(v, ",") {
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			if coding != "" && coding != "identity" {
+				codings = append(codings, coding)
+			}
+		}
+	}
+	return codings
+}
@@ -0,0 +1,50 @@
+package decompress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"log"
+
+	"github.com/jeffotoni/quick"
+)
+
+// This function is named ExampleNew()
+// it with the Examples type.
+func ExampleNew() {
+	q := quick.New()
+
+	// Inflate gzip and deflate request bodies of up to 10MB
+	q.Use(New(Config{MaxBodySize: 10 << 20}))
+
+	q.Post("/v1/batch", func(c *quick.Ctx) error {
+		var items []string
+		if err := c.Bind(&items); err != nil {
+			return c.Status(quick.StatusBadRequest).String(err.Error())
+		}
+		return c.Status(quick.StatusOK).JSON(map[string]int{"received": len(items)})
+	})
+
+	var body bytes.Buffer
+	gz := gzip.NewWriter(&body)
+	gz.Write([]byte(`["a","b","c"]`))
+	gz.Close()
+
+	res, err := q.Qtest(quick.QuickTestOptions{
+		Method: quick.MethodPost,
+		URI:    "/v1/batch",
+		Headers: map[string]string{
+			"Content-Type":     "application/json",
+			"Content-Encoding": "gzip",
+		},
+		Body: body.Bytes(),
+	})
+	if err != nil {
+		log.Fatalf("Error running test request: %v", err)
+	}
+
+	fmt.Println(res.StatusCode(), res.BodyStr())
+
+	// Output:
+	// 200 {"received":3}
+}
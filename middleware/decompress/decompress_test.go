@@ -0,0 +1,138 @@
+package decompress
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jeffotoni/quick"
+)
+
+// compress encodes s with the gzip, deflate (zlib) or raw-deflate coding.
+func compress(t *testing.T, coding string, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch coding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	case "raw":
+		w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+	}
+	if _, err := io.WriteString(w, s); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// TestNew verifies that Ctx.Body and Bind see the decompressed body.
+//
+// go test -v -failfast -count=1 -run ^TestNew$
+func TestNew(t *testing.T) {
+	payload := `{"name":"quick","items":["` + strings.Repeat("a", 4096) + `"]}`
+
+	q := quick.New()
+	q.Use(New(Config{
+		MaxBodySize: 8 * 1024,
+		// A fake coding, to check custom decoders
+		Decoders: map[string]Decoder{"rev": func(r io.Reader) (io.ReadCloser, error) {
+			b, err := io.ReadAll(r)
+			for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+				b[i], b[j] = b[j], b[i]
+			}
+			return io.NopCloser(bytes.NewReader(b)), err
+		}},
+	}))
+	q.Post("/upload", func(c *quick.Ctx) error {
+		var v struct {
+			Name  string   `json:"name"`
+			Items []string `json:"items"`
+		}
+		if err := c.Bind(&v); err != nil {
+			return c.Status(quick.StatusBadRequest).String(err.Error())
+		}
+		return c.Status(quick.StatusOK).String(fmt.Sprintf("%s %d %q", v.Name, len(c.Body()), c.Get("Content-Encoding")))
+	})
+
+	gz := compress(t, "gzip", payload)
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+		wantCode int
+		wantBody string
+	}{
+		{"plain", "", []byte(payload), 200, `quick 4125 ""`},
+		{"identity", "identity", []byte(payload), 200, `quick 4125 "identity"`},
+		{"gzip", "gzip", gz, 200, `quick 4125 ""`},
+		{"x_gzip", "X-Gzip", gz, 200, `quick 4125 ""`},
+		{"deflate", "deflate", compress(t, "deflate", payload), 200, `quick 4125 ""`},
+		{"raw_deflate", "deflate", compress(t, "raw", payload), 200, `quick 4125 ""`},
+		{"stacked", "gzip, rev", reverse(gz), 200, `quick 4125 ""`},
+		{"unsupported", "zstd", gz, 415, "unsupported Content-Encoding: zstd"},
+		{"corrupt", "gzip", []byte("not gzip at all"), 400, "invalid compressed body"},
+		{"truncated", "gzip", gz[:len(gz)/2], 400, "invalid compressed body"},
+		{"zip_bomb", "gzip", compress(t, "gzip", strings.Repeat("0", 1<<20)), 413, "Request body too large"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(quick.MethodPost, "/upload", bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.encoding != "" {
+				req.Header.Set("Content-Encoding", tt.encoding)
+			}
+			rec := httptest.NewRecorder()
+			q.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode || !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Fatalf("got %d %q, want %d %q", rec.Code, rec.Body.String(), tt.wantCode, tt.wantBody)
+			}
+			if tt.wantCode == 415 && rec.Header().Get("Accept-Encoding") != "gzip, deflate" {
+				t.Errorf("unexpected Accept-Encoding %q", rec.Header().Get("Accept-Encoding"))
+			}
+		})
+	}
+}
+
+// reverse returns a reversed copy of b.
+func reverse(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+	return r
+}
+
+// TestNewCompressedLimit verifies that the compressed size is limited too.
+//
+// go test -v -failfast -count=1 -run ^TestNewCompressedLimit$
+func TestNewCompressedLimit(t *testing.T) {
+	h := New(Config{MaxBodySize: 64})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the handler should not run")
+	}))
+
+	// Incompressible data grows a little when compressed
+	var data bytes.Buffer
+	for i := 0; i < 60; i++ {
+		data.WriteByte(byte(i * 7919 % 251))
+	}
+	req := httptest.NewRequest(quick.MethodPost, "/", bytes.NewReader(compress(t, "gzip", data.String())))
+	req.Header.Set("Content-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != quick.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413, got %d", rec.Code)
+	}
+}
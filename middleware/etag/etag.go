@@ -0,0 +1,156 @@
+// Package etag provides ETag and conditional request middleware for Quick.
+//
+// The response of GET and HEAD requests is buffered so an entity tag can be
+// computed over the body. Conditional requests are then answered with the
+// validators of the response: 304 Not Modified when the client already has
+// it, 412 Precondition Failed when If-Match or If-Unmodified-Since fail.
+//
+// Handlers that know the version of a resource can set the ETag themselves
+// with c.SetETag, and Last-Modified with c.SetLastModified; the middleware
+// keeps them instead of hashing the body. The validator logic is the one of
+// quick.CheckPreconditions, shared with the cache middleware and Static.
+//
+// Features:
+//   - Strong or weak ETags computed over the body.
+//   - If-None-Match, If-Match, If-Modified-Since and If-Unmodified-Since.
+//   - ETags and Last-Modified set by handlers are honored.
+//   - Server-Sent Events are passed through unbuffered.
+package etag
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+
+	"github.com/jeffotoni/quick"
+)
+
+// Config defines the configuration options for the etag middleware.
+type Config struct {
+	// Weak generates weak ETags (W/"..."), for responses whose bytes may
+	// change without their meaning changing, e.g. when compressed later.
+	// Default is false.
+	Weak bool
+
+	// Next is a function that determines whether to skip the middleware.
+	Next func(c *quick.Ctx) bool
+}
+
+// ConfigDefault is the default configuration.
+var ConfigDefault = Config{}
+
+// New creates the etag middleware.
+//
+// Only GET and HEAD responses are buffered. State-changing requests must
+// check If-Match before the change is made, which the handler does with
+// c.SetETag and c.CheckPreconditions.
+//
+// Parameters:
+//   - config: Optional configuration; ConfigDefault is used when omitted.
+//
+// Returns:
+//   - func(http.Handler) http.Handler: The middleware, usable with q.Use and Group.Use.
+//
+// Example Usage:
+//
+//	q.Use(etag.New(etag.Config{Weak: true}))
+func New(config ...Config) func(http.Handler) http.Handler {
+	cfg := ConfigDefault
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c := &quick.Ctx{Response: w, Request: r}
+			if (cfg.Next != nil && cfg.Next(c)) || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			bw := &bufferedWriter{ResponseWriter: w}
+			next.ServeHTTP(bw, r)
+			if bw.streaming {
+				return
+			}
+
+			code := bw.code
+			if code == 0 {
+				code = http.StatusOK
+			}
+			if code == http.StatusOK {
+				h := w.Header()
+				if h.Get("ETag") == "" && bw.buf.Len() > 0 {
+					h.Set("ETag", quick.GenerateETag(bw.buf.Bytes(), cfg.Weak))
+				}
+				if c.CheckPreconditions() {
+					return
+				}
+			}
+			bw.send()
+		})
+	}
+}
+
+// bufferedWriter holds the status and body of a response until the
+// handler returns, or until it flushes.
+type bufferedWriter struct {
+	http.ResponseWriter
+	code      int
+	buf       bytes.Buffer
+	streaming bool // Flush was called: the response is written through
+}
+
+// WriteHeader records the status code.
+func (w *bufferedWriter) WriteHeader(code int) {
+	if w.streaming {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+// Write buffers the body.
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	if w.streaming {
+		return w.ResponseWriter.Write(b)
+	}
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	return w.buf.Write(b)
+}
+
+// Flush gives up on the ETag and streams Server-Sent Events. Other
+// responses stay buffered: Quick flushes after every response it writes.
+func (w *bufferedWriter) Flush() {
+	if !w.streaming {
+		media, _, _ := strings.Cut(w.Header().Get("Content-Type"), ";")
+		if !strings.EqualFold(strings.TrimSpace(media), "text/event-stream") {
+			return
+		}
+		w.send()
+		w.streaming = true
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the original ResponseWriter, for http.ResponseController.
+func (w *bufferedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// send writes the buffered status and body.
+func (w *bufferedWriter) send() {
+	if w.code != 0 {
+		w.ResponseWriter.WriteHeader(w.code)
+	}
+	if w.buf.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.buf.Bytes())
+		w.buf.Reset()
+	}
+}
@@ -0,0 +1,43 @@
+package etag
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/jeffotoni/quick"
+)
+
+// This function is named ExampleNew()
+// it with the Examples type.
+func ExampleNew() {
+	q := quick.New()
+
+	// Compute ETags and answer conditional requests
+	q.Use(New())
+
+	q.Get("/v1/user", func(c *quick.Ctx) error {
+		return c.Status(quick.StatusOK).JSON(map[string]string{"name": "quick"})
+	})
+
+	res, err := q.Qtest(quick.QuickTestOptions{Method: quick.MethodGet, URI: "/v1/user"})
+	if err != nil {
+		log.Fatalf("Error running test request: %v", err)
+	}
+	etag := res.Response().Header.Get("ETag")
+	fmt.Println(res.StatusCode(), res.BodyStr())
+
+	// The client revalidates with the ETag it received
+	res, err = q.Qtest(quick.QuickTestOptions{
+		Method:  quick.MethodGet,
+		URI:     "/v1/user",
+		Headers: map[string]string{"If-None-Match": etag},
+	})
+	if err != nil {
+		log.Fatalf("Error running test request: %v", err)
+	}
+	fmt.Println(res.StatusCode(), res.BodyStr() == "")
+
+	// Output:
+	// 200 {"name":"quick"}
+	// 304 true
+}
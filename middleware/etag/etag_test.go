@@ -0,0 +1,117 @@
+package etag
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jeffotoni/quick"
+)
+
+// TestNew verifies generated and explicit validators and the answers to
+// conditional requests.
+//
+// go test -v -failfast -count=1 -run ^TestNew$
+func TestNew(t *testing.T) {
+	modified := time.Date(2025, 5, 1, 8, 0, 0, 0, time.UTC)
+
+	q := quick.New()
+	q.Use(New())
+	q.Get("/hello", func(c *quick.Ctx) error {
+		c.Set("Content-Type", "text/plain")
+		return c.Status(quick.StatusOK).String("hello")
+	})
+	q.Get("/versioned", func(c *quick.Ctx) error {
+		c.SetETag("v3")
+		c.SetLastModified(modified)
+		return c.Status(quick.StatusOK).String("versioned")
+	})
+	q.Get("/missing", func(c *quick.Ctx) error {
+		return c.Status(quick.StatusNotFound).String("not found")
+	})
+	q.Post("/hello", func(c *quick.Ctx) error {
+		return c.Status(quick.StatusCreated).String("created")
+	})
+
+	hello := quick.GenerateETag([]byte("hello"), false)
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		headers  map[string]string
+		wantCode int
+		wantETag string
+		wantBody string
+	}{
+		{"generated", quick.MethodGet, "/hello", nil, 200, hello, "hello"},
+		{"if_none_match", quick.MethodGet, "/hello", map[string]string{"If-None-Match": hello}, 304, hello, ""},
+		{"if_none_match_weak", quick.MethodGet, "/hello", map[string]string{"If-None-Match": "W/" + hello}, 304, hello, ""},
+		{"if_none_match_stale", quick.MethodGet, "/hello", map[string]string{"If-None-Match": `"old"`}, 200, hello, "hello"},
+		{"if_match_failed", quick.MethodGet, "/hello", map[string]string{"If-Match": `"old"`}, 412, hello, `"code":412`},
+		{"explicit", quick.MethodGet, "/versioned", map[string]string{"If-None-Match": `"v3"`}, 304, `"v3"`, ""},
+		{"if_modified_since", quick.MethodGet, "/versioned", map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat)}, 304, `"v3"`, ""},
+		{"if_unmodified_since", quick.MethodGet, "/versioned", map[string]string{"If-Unmodified-Since": modified.Add(-time.Hour).Format(http.TimeFormat)}, 412, `"v3"`, `"code":412`},
+		{"not_ok", quick.MethodGet, "/missing", map[string]string{"If-None-Match": "*"}, 404, "", "not found"},
+		{"unsafe_method", quick.MethodPost, "/hello", map[string]string{"If-None-Match": "*"}, 201, "", "created"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			q.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("expected %d, got %d %q", tt.wantCode, rec.Code, rec.Body.String())
+			}
+			if got := rec.Header().Get("ETag"); got != tt.wantETag {
+				t.Errorf("expected ETag %q, got %q", tt.wantETag, got)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) || (tt.wantBody == "" && rec.Body.Len() > 0) {
+				t.Errorf("unexpected body %q", rec.Body.String())
+			}
+			if rec.Code == 304 && rec.Header().Get("Content-Type") != "" {
+				t.Errorf("304 should not have a Content-Type, got %q", rec.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+// TestNewWeak verifies weak ETags.
+//
+// go test -v -failfast -count=1 -run ^TestNewWeak$
+func TestNewWeak(t *testing.T) {
+	h := New(Config{Weak: true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "weak")
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(quick.MethodGet, "/", nil))
+	if got := rec.Header().Get("ETag"); got != quick.GenerateETag([]byte("weak"), true) {
+		t.Errorf("unexpected ETag %q", got)
+	}
+}
+
+// TestNewEventStream verifies that Server-Sent Events are not buffered.
+//
+// go test -v -failfast -count=1 -run ^TestNewEventStream$
+func TestNewEventStream(t *testing.T) {
+	h := New()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: one\n\n")
+		w.(http.Flusher).Flush()
+		if rec := w.(interface{ Unwrap() http.ResponseWriter }).Unwrap().(*httptest.ResponseRecorder); rec.Body.String() != "data: one\n\n" {
+			t.Errorf("the event was not flushed: %q", rec.Body.String())
+		}
+		io.WriteString(w, "data: two\n\n")
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(quick.MethodGet, "/", nil))
+	if rec.Header().Get("ETag") != "" || rec.Body.String() != "data: one\n\ndata: two\n\n" {
+		t.Errorf("unexpected response %v %q", rec.Header(), rec.Body.String())
+	}
+}
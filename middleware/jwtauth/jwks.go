@@ -0,0 +1,335 @@
+package jwtauth
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// jwksMinInterval is the minimum delay between two reloads of a JWKS.
+const jwksMinInterval = 30 * time.Second
+
+// jwksMaxSize limits the size of a JWKS document.
+const jwksMaxSize = 1 << 20
+
+// jwk is a JSON Web Key (RFC 7517). Only public parameters are read.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// setKey is a verification key of a JWKS.
+type setKey struct {
+	kid string
+	alg string
+	key any
+}
+
+// JWKS is a JSON Web Key Set loaded from a URL or a file and cached.
+//
+// The set is reloaded after the refresh interval, and also when a token
+// references an unknown key ID, so key rotation at the issuer is picked up
+// without restarting. When a reload fails, the previous keys are kept.
+//
+// Symmetric ("oct") keys are only accepted from files: a secret published at a
+// URL could be used by anyone to sign tokens. Configure HS256 secrets statically.
+//
+// A JWKS is safe for concurrent use.
+type JWKS struct {
+	source  string
+	client  *http.Client
+	refresh time.Duration
+
+	mu      sync.RWMutex
+	keys    []setKey
+	loaded  time.Time
+	attempt time.Time
+}
+
+// NewJWKS creates a key set loaded from source.
+//
+// Parameters:
+//   - source: An http(s) URL or a file path.
+//   - refresh: How long keys are cached; 1 hour when zero.
+//   - client: The HTTP client for URLs; http.DefaultClient with a 10s timeout when nil.
+//
+// Returns:
+//   - *JWKS: The key set. Keys are loaded on first use.
+//
+// Example Usage:
+//
+//	keys := jwtauth.NewJWKS("https://issuer.example.com/.well-known/jwks.json", time.Hour, nil)
+func NewJWKS(source string, refresh time.Duration, client *http.Client) *JWKS {
+	if refresh <= 0 {
+		refresh = time.Hour
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &JWKS{source: source, client: client, refresh: refresh}
+}
+
+// Key returns the key matching the token header. It implements KeyFunc.
+//
+// Parameters:
+//   - h: The token header.
+//
+// Returns:
+//   - any: The verification key.
+//   - error: ErrKeyNotFound if no key matches, or the load error if the set was never loaded.
+func (s *JWKS) Key(h Header) (any, error) {
+	s.mu.RLock()
+	stale := time.Since(s.loaded) > s.refresh
+	s.mu.RUnlock()
+	if stale {
+		if err := s.reload(); err != nil && s.empty() {
+			return nil, err
+		}
+	}
+
+	if key, ok := s.lookup(h); ok {
+		return key, nil
+	}
+	// Unknown key ID: the issuer may have rotated its keys
+	if err := s.reload(); err == nil {
+		if key, ok := s.lookup(h); ok {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: kid %q", ErrKeyNotFound, h.Kid)
+}
+
+// Refresh reloads the key set immediately.
+//
+// Returns:
+//   - error: An error if the set cannot be fetched or parsed.
+func (s *JWKS) Refresh() error {
+	return s.load()
+}
+
+// empty reports whether no key was ever loaded.
+func (s *JWKS) empty() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.keys) == 0
+}
+
+// lookup finds the key for h: by kid, or the only key compatible with the
+// algorithm when the token has no kid.
+func (s *JWKS) lookup(h Header) (any, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var found any
+	n := 0
+	for _, k := range s.keys {
+		if k.alg != "" && k.alg != h.Alg {
+			continue
+		}
+		if h.Kid != "" {
+			if k.kid == h.Kid {
+				return k.key, true
+			}
+			continue
+		}
+		if keyMatchesAlg(k.key, h.Alg) {
+			found = k.key
+			n++
+		}
+	}
+	return found, n == 1
+}
+
+// reload loads the set, at most once per jwksMinInterval, so that failing
+// sources and tokens with unknown key IDs cannot trigger a fetch per request.
+func (s *JWKS) reload() error {
+	s.mu.Lock()
+	if time.Since(s.attempt) < jwksMinInterval {
+		s.mu.Unlock()
+		return nil
+	}
+	s.attempt = time.Now()
+	s.mu.Unlock()
+	return s.load()
+}
+
+// load fetches and parses the set, replacing the cached keys.
+func (s *JWKS) load() error {
+	data, err := s.fetch()
+	if err != nil {
+		return err
+	}
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		return err
+	}
+	list := make([]setKey, 0, len(keys))
+	for kid, key := range keys {
+		if _, symmetric := key.Key.([]byte); symmetric && s.remote() {
+			continue
+		}
+		list = append(list, setKey{kid: kid, key: key.Key, alg: key.Alg})
+	}
+	s.mu.Lock()
+	s.keys, s.loaded = list, time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+// remote reports whether the set is fetched from a URL.
+func (s *JWKS) remote() bool {
+	return strings.HasPrefix(s.source, "http://") || strings.HasPrefix(s.source, "https://")
+}
+
+// fetch reads the JWKS document.
+func (s *JWKS) fetch() ([]byte, error) {
+	if !s.remote() {
+		data, err := os.ReadFile(s.source)
+		if err != nil {
+			return nil, fmt.Errorf("jwtauth: jwks: %w", err)
+		}
+		return data, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.source, nil)
+	if err != nil {
+		return nil, fmt.Errorf("jwtauth: jwks: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("jwtauth: jwks: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwtauth: jwks: unexpected status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, jwksMaxSize))
+	if err != nil {
+		return nil, fmt.Errorf("jwtauth: jwks: %w", err)
+	}
+	return data, nil
+}
+
+// JWK is a verification key parsed from a JWKS.
+type JWK struct {
+	Alg string // Algorithm declared by the key, if any
+	Key any    // []byte, *rsa.PublicKey, *ecdsa.PublicKey or ed25519.PublicKey
+}
+
+// ParseJWKS parses a JSON Web Key Set.
+//
+// Keys that are not signature keys or have an unsupported type are skipped.
+//
+// Parameters:
+//   - data: The JWKS document ({"keys": [...]}).
+//
+// Returns:
+//   - map[string]JWK: The keys by key ID.
+//   - error: An error if the document is not valid JSON.
+func ParseJWKS(data []byte) (map[string]JWK, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("jwtauth: jwks: %w", err)
+	}
+	keys := make(map[string]JWK, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = JWK{Alg: k.Alg, Key: key}
+	}
+	return keys, nil
+}
+
+// publicKey converts the JWK to a Go key.
+func (k jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err1 := decodeBigInt(k.N)
+		e, err2 := decodeBigInt(k.E)
+		if err1 != nil || err2 != nil || !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("jwtauth: invalid RSA key %q", k.Kid)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("jwtauth: unsupported curve %q", k.Crv)
+		}
+		x, err1 := base64.RawURLEncoding.DecodeString(k.X)
+		y, err2 := base64.RawURLEncoding.DecodeString(k.Y)
+		if err1 != nil || err2 != nil || len(x) != 32 || len(y) != 32 {
+			return nil, fmt.Errorf("jwtauth: invalid EC key %q", k.Kid)
+		}
+		// crypto/ecdh rejects points that are not on the curve
+		if _, err := ecdh.P256().NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+			return nil, fmt.Errorf("jwtauth: invalid EC key %q", k.Kid)
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if k.Crv != "Ed25519" || err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("jwtauth: invalid OKP key %q", k.Kid)
+		}
+		return ed25519.PublicKey(x), nil
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil || len(secret) == 0 {
+			return nil, fmt.Errorf("jwtauth: invalid oct key %q", k.Kid)
+		}
+		return secret, nil
+	}
+	return nil, fmt.Errorf("jwtauth: unsupported key type %q", k.Kty)
+}
+
+// decodeBigInt decodes a base64url unsigned big-endian integer.
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("jwtauth: invalid integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// keyMatchesAlg reports whether key can verify alg.
+func keyMatchesAlg(key any, alg string) bool {
+	switch key.(type) {
+	case []byte:
+		return alg == HS256
+	case *rsa.PublicKey:
+		return alg == RS256
+	case *ecdsa.PublicKey:
+		return alg == ES256
+	case ed25519.PublicKey:
+		return alg == EdDSA
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+package jwtauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+// b64 encodes b as base64url without padding.
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// toJWK encodes a public key as a JWK.
+func toJWK(t *testing.T, kid string, key any) map[string]string {
+	t.Helper()
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return map[string]string{"kty": "RSA", "kid": kid, "use": "sig", "n": b64(k.N.Bytes()), "e": b64(big.NewInt(int64(k.E)).Bytes())}
+	case *ecdsa.PublicKey:
+		pub, err := k.ECDH()
+		if err != nil {
+			t.Fatal(err)
+		}
+		point := pub.Bytes() // 0x04 || x || y
+		return map[string]string{"kty": "EC", "kid": kid, "crv": "P-256", "x": b64(point[1:33]), "y": b64(point[33:])}
+	case ed25519.PublicKey:
+		return map[string]string{"kty": "OKP", "kid": kid, "crv": "Ed25519", "x": b64(k)}
+	case []byte:
+		return map[string]string{"kty": "oct", "kid": kid, "alg": HS256, "k": b64(k)}
+	}
+	t.Fatalf("unsupported key %T", key)
+	return nil
+}
+
+// jwksJSON encodes a JWKS document.
+func jwksJSON(t *testing.T, keys ...map[string]string) []byte {
+	t.Helper()
+	b, err := json.Marshal(map[string]any{"keys": keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// go test -v -failfast -count=1 -run ^TestParseJWKS$
+func TestParseJWKS(t *testing.T) {
+	k := newTestKeys(t)
+	data := jwksJSON(t,
+		toJWK(t, "rsa", &k.rsa.PublicKey),
+		toJWK(t, "ec", &k.ec.PublicKey),
+		toJWK(t, "ed", k.ed.Public().(ed25519.PublicKey)),
+		toJWK(t, "hs", k.secret),
+		map[string]string{"kty": "RSA", "kid": "enc", "use": "enc", "n": "AQAB", "e": "AQAB"},
+		map[string]string{"kty": "EC", "kid": "bad", "crv": "P-256", "x": b64(make([]byte, 32)), "y": b64(make([]byte, 32))},
+	)
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 4 {
+		t.Fatalf("expected 4 keys (encryption and invalid keys skipped), got %d", len(keys))
+	}
+
+	cases := map[string]struct {
+		alg  string
+		sign any
+	}{
+		"rsa": {RS256, k.rsa},
+		"ec":  {ES256, k.ec},
+		"ed":  {EdDSA, k.ed},
+		"hs":  {HS256, k.secret},
+	}
+	for kid, c := range cases {
+		raw := mustSign(t, c.alg, c.sign, Claims{"sub": kid}, kid)
+		if _, err := Parse(raw, staticKey(keys[kid].Key), Validation{}); err != nil {
+			t.Errorf("%s: %v", kid, err)
+		}
+	}
+}
+
+// go test -v -failfast -count=1 -run ^TestJWKSRotation$
+func TestJWKSRotation(t *testing.T) {
+	k1, k2 := newTestKeys(t), newTestKeys(t)
+	var current atomic.Value
+	current.Store(jwksJSON(t, toJWK(t, "k1", &k1.rsa.PublicKey)))
+	var fetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Write(current.Load().([]byte))
+	}))
+	defer srv.Close()
+
+	jwks := NewJWKS(srv.URL, 0, srv.Client())
+	tok1 := mustSign(t, RS256, k1.rsa, Claims{}, "k1")
+	for range 3 {
+		if _, err := Parse(tok1, jwks.Key, Validation{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("expected keys to be cached, got %d fetches", n)
+	}
+
+	// the issuer rotates to k2; the unknown kid triggers a reload
+	current.Store(jwksJSON(t, toJWK(t, "k2", &k2.rsa.PublicKey)))
+	jwks.attempt = jwks.attempt.Add(-jwksMinInterval)
+	tok2 := mustSign(t, RS256, k2.rsa, Claims{}, "k2")
+	if _, err := Parse(tok2, jwks.Key, Validation{}); err != nil {
+		t.Fatalf("expected rotated key to be loaded: %v", err)
+	}
+	if _, err := Parse(tok1, jwks.Key, Validation{}); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("expected retired key to be rejected, got %v", err)
+	}
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("expected reloads to be throttled, got %d fetches", n)
+	}
+}
+
+// go test -v -failfast -count=1 -run ^TestJWKSFile$
+func TestJWKSFile(t *testing.T) {
+	k := newTestKeys(t)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwksJSON(t, toJWK(t, "", k.ed.Public().(ed25519.PublicKey))), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// a single key without kid matches tokens without kid
+	jwks := NewJWKS(path, 0, nil)
+	if _, err := Parse(mustSign(t, EdDSA, k.ed, Claims{}), jwks.Key, Validation{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewJWKS(filepath.Join(t.TempDir(), "missing.json"), 0, nil).Refresh(); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+// go test -v -failfast -count=1 -run ^TestJWKSSymmetric$
+func TestJWKSSymmetric(t *testing.T) {
+	secret := []byte("published-secret")
+	data := jwksJSON(t, toJWK(t, "hs", secret))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(data)
+	}))
+	defer srv.Close()
+	tok := mustSign(t, HS256, secret, Claims{}, "hs")
+
+	// anyone can read a URL, so a secret published there signs nothing
+	if _, err := Parse(tok, NewJWKS(srv.URL, 0, srv.Client()).Key, Validation{}); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("expected oct keys from a URL to be ignored, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Parse(tok, NewJWKS(path, 0, nil).Key, Validation{}); err != nil {
+		t.Errorf("expected oct keys from a file to be accepted, got %v", err)
+	}
+}
@@ -0,0 +1,398 @@
+package jwtauth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Supported signing algorithms (RFC 7518, RFC 8037).
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	ES256 = "ES256"
+	EdDSA = "EdDSA"
+)
+
+// Errors returned when a token is rejected.
+var (
+	ErrTokenMissing      = errors.New("jwtauth: token missing")
+	ErrTokenMalformed    = errors.New("jwtauth: token malformed")
+	ErrAlgorithm         = errors.New("jwtauth: signing algorithm not allowed")
+	ErrKeyNotFound       = errors.New("jwtauth: no key for token")
+	ErrSignature         = errors.New("jwtauth: invalid signature")
+	ErrTokenExpired      = errors.New("jwtauth: token expired")
+	ErrTokenNotValidYet  = errors.New("jwtauth: token not valid yet")
+	ErrInvalidIssuer     = errors.New("jwtauth: invalid issuer")
+	ErrInvalidAudience   = errors.New("jwtauth: invalid audience")
+	ErrExpirationMissing = errors.New("jwtauth: exp claim required")
+	ErrInvalidClaim      = errors.New("jwtauth: invalid claim")
+)
+
+// Header is the JOSE header of a token.
+type Header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// Claims holds the payload of a token.
+//
+// Numbers are decoded as float64, as with encoding/json.
+type Claims map[string]any
+
+// Subject returns the "sub" claim.
+func (c Claims) Subject() string { return c.str("sub") }
+
+// Issuer returns the "iss" claim.
+func (c Claims) Issuer() string { return c.str("iss") }
+
+// ID returns the "jti" claim.
+func (c Claims) ID() string { return c.str("jti") }
+
+// Audience returns the "aud" claim, which may be a string or an array.
+func (c Claims) Audience() []string {
+	switch aud := c["aud"].(type) {
+	case string:
+		return []string{aud}
+	case []any:
+		out := make([]string, 0, len(aud))
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	case []string:
+		return aud
+	}
+	return nil
+}
+
+// ExpiresAt returns the "exp" claim, or the zero time if absent.
+func (c Claims) ExpiresAt() time.Time { return c.time("exp") }
+
+// NotBefore returns the "nbf" claim, or the zero time if absent.
+func (c Claims) NotBefore() time.Time { return c.time("nbf") }
+
+// IssuedAt returns the "iat" claim, or the zero time if absent.
+func (c Claims) IssuedAt() time.Time { return c.time("iat") }
+
+// str returns a string claim.
+func (c Claims) str(name string) string {
+	s, _ := c[name].(string)
+	return s
+}
+
+// time returns a NumericDate claim (seconds since the epoch).
+func (c Claims) time(name string) time.Time {
+	t, _ := c.numericDate(name)
+	return t
+}
+
+// numericDate returns a NumericDate claim, the zero time if it is absent, or
+// ErrInvalidClaim if it is present but not a number.
+func (c Claims) numericDate(name string) (time.Time, error) {
+	switch v := c[name].(type) {
+	case nil:
+		if _, ok := c[name]; !ok {
+			return time.Time{}, nil
+		}
+	case float64:
+		sec, frac := int64(v), v-float64(int64(v))
+		return time.Unix(sec, int64(frac*1e9)), nil
+	case int64:
+		return time.Unix(v, 0), nil
+	case int:
+		return time.Unix(int64(v), 0), nil
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return time.Unix(int64(f), 0), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: %q is not a NumericDate", ErrInvalidClaim, name)
+}
+
+// Token is a parsed token.
+type Token struct {
+	Raw    string
+	Header Header
+	Claims Claims
+}
+
+// KeyFunc returns the verification key of a token header.
+type KeyFunc func(h Header) (any, error)
+
+// Validation holds the claim checks applied by Parse.
+type Validation struct {
+	Algorithms        []string      // Allowed algorithms; empty allows all supported ones
+	Issuer            string        // Expected "iss", if set
+	Audience          []string      // Accepted "aud" values, if set; one must match
+	ClockSkew         time.Duration // Tolerance applied to exp and nbf
+	RequireExpiration bool          // Reject tokens without "exp"
+	Now               func() time.Time
+}
+
+// Parse verifies the signature of a compact JWS token and validates its claims.
+//
+// Parameters:
+//   - raw: The token.
+//   - keyFunc: Returns the key for the token header.
+//   - v: The claim validation rules.
+//
+// Returns:
+//   - *Token: The parsed token.
+//   - error: One of the Err* errors of this package, possibly wrapped.
+func Parse(raw string, keyFunc KeyFunc, v Validation) (*Token, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, ErrTokenMalformed
+	}
+
+	var tok Token
+	tok.Raw = raw
+	if err := decodeSegment(parts[0], &tok.Header); err != nil {
+		return nil, err
+	}
+	if !algorithmAllowed(tok.Header.Alg, v.Algorithms) {
+		return nil, fmt.Errorf("%w: %q", ErrAlgorithm, tok.Header.Alg)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+
+	key, err := keyFunc(tok.Header)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(tok.Header.Alg, key, parts[0]+"."+parts[1], sig); err != nil {
+		return nil, err
+	}
+
+	if err := decodeSegment(parts[1], &tok.Claims); err != nil {
+		return nil, err
+	}
+	if err := validateClaims(tok.Claims, v); err != nil {
+		return nil, err
+	}
+	return &tok, nil
+}
+
+// Sign creates a compact JWS token.
+//
+// Parameters:
+//   - alg: HS256, RS256, ES256 or EdDSA.
+//   - key: []byte, *rsa.PrivateKey, *ecdsa.PrivateKey or ed25519.PrivateKey.
+//   - claims: The payload.
+//   - kid: Optional key ID written to the header.
+//
+// Returns:
+//   - string: The signed token.
+//   - error: An error if the key does not match the algorithm.
+//
+// Example Usage:
+//
+//	token, err := jwtauth.Sign(jwtauth.HS256, secret, jwtauth.Claims{
+//	    "sub": "42",
+//	    "exp": time.Now().Add(time.Hour).Unix(),
+//	})
+func Sign(alg string, key any, claims Claims, kid ...string) (string, error) {
+	h := Header{Alg: alg, Typ: "JWT"}
+	if len(kid) > 0 {
+		h.Kid = kid[0]
+	}
+	hb, err := json.Marshal(h)
+	if err != nil {
+		return "", err
+	}
+	cb, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	input := base64.RawURLEncoding.EncodeToString(hb) + "." + base64.RawURLEncoding.EncodeToString(cb)
+
+	var sig []byte
+	switch alg {
+	case HS256:
+		secret, ok := key.([]byte)
+		if !ok {
+			return "", fmt.Errorf("jwtauth: %s requires a []byte key", alg)
+		}
+		sig = hmacSHA256(secret, input)
+	case RS256:
+		k, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return "", fmt.Errorf("jwtauth: %s requires an *rsa.PrivateKey", alg)
+		}
+		digest := sha256.Sum256([]byte(input))
+		if sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:]); err != nil {
+			return "", err
+		}
+	case ES256:
+		k, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			return "", fmt.Errorf("jwtauth: %s requires an *ecdsa.PrivateKey", alg)
+		}
+		digest := sha256.Sum256([]byte(input))
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		if err != nil {
+			return "", err
+		}
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	case EdDSA:
+		k, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return "", fmt.Errorf("jwtauth: %s requires an ed25519.PrivateKey", alg)
+		}
+		sig = ed25519.Sign(k, []byte(input))
+	default:
+		return "", fmt.Errorf("%w: %q", ErrAlgorithm, alg)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// decodeSegment decodes a base64url JSON segment.
+func decodeSegment(seg string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return ErrTokenMalformed
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return ErrTokenMalformed
+	}
+	return nil
+}
+
+// algorithmAllowed reports whether alg is supported and allowed.
+// "none" is never accepted.
+func algorithmAllowed(alg string, allowed []string) bool {
+	switch alg {
+	case HS256, RS256, ES256, EdDSA:
+	default:
+		return false
+	}
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if a == alg {
+			return true
+		}
+	}
+	return false
+}
+
+// verifySignature checks sig over input. The key type must match the
+// algorithm, which prevents algorithm confusion (e.g. an RSA public key used as an HMAC secret).
+func verifySignature(alg string, key any, input string, sig []byte) error {
+	switch alg {
+	case HS256:
+		secret, ok := key.([]byte)
+		if !ok {
+			return fmt.Errorf("%w: %s needs a secret", ErrKeyNotFound, alg)
+		}
+		if !hmac.Equal(sig, hmacSHA256(secret, input)) {
+			return ErrSignature
+		}
+	case RS256:
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: %s needs an RSA key", ErrKeyNotFound, alg)
+		}
+		digest := sha256.Sum256([]byte(input))
+		if rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig) != nil {
+			return ErrSignature
+		}
+	case ES256:
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: %s needs an ECDSA key", ErrKeyNotFound, alg)
+		}
+		if len(sig) != 64 {
+			return ErrSignature
+		}
+		digest := sha256.Sum256([]byte(input))
+		r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(pub, digest[:], r, s) {
+			return ErrSignature
+		}
+	case EdDSA:
+		pub, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: %s needs an Ed25519 key", ErrKeyNotFound, alg)
+		}
+		if len(pub) != ed25519.PublicKeySize || !ed25519.Verify(pub, []byte(input), sig) {
+			return ErrSignature
+		}
+	default:
+		return ErrAlgorithm
+	}
+	return nil
+}
+
+// hmacSHA256 computes the HS256 signature of input.
+func hmacSHA256(secret []byte, input string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(input))
+	return mac.Sum(nil)
+}
+
+// validateClaims checks exp, nbf, iss and aud.
+func validateClaims(c Claims, v Validation) error {
+	now := time.Now()
+	if v.Now != nil {
+		now = v.Now()
+	}
+
+	// A malformed date must not pass for an absent one
+	exp, err := c.numericDate("exp")
+	if err != nil {
+		return err
+	}
+	nbf, err := c.numericDate("nbf")
+	if err != nil {
+		return err
+	}
+	if _, err := c.numericDate("iat"); err != nil {
+		return err
+	}
+
+	if !exp.IsZero() {
+		if now.After(exp.Add(v.ClockSkew)) {
+			return ErrTokenExpired
+		}
+	} else if v.RequireExpiration {
+		return ErrExpirationMissing
+	}
+	if !nbf.IsZero() && now.Add(v.ClockSkew).Before(nbf) {
+		return ErrTokenNotValidYet
+	}
+	if v.Issuer != "" && c.Issuer() != v.Issuer {
+		return ErrInvalidIssuer
+	}
+	if len(v.Audience) > 0 {
+		for _, aud := range c.Audience() {
+			for _, want := range v.Audience {
+				if aud == want {
+					return nil
+				}
+			}
+		}
+		return ErrInvalidAudience
+	}
+	return nil
+}
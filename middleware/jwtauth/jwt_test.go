@@ -0,0 +1,158 @@
+package jwtauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testKeys holds one key pair per supported algorithm.
+type testKeys struct {
+	secret []byte
+	rsa    *rsa.PrivateKey
+	ec     *ecdsa.PrivateKey
+	ed     ed25519.PrivateKey
+}
+
+// newTestKeys generates the keys used by the tests.
+func newTestKeys(t *testing.T) testKeys {
+	t.Helper()
+	rk, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ek, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, dk, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testKeys{secret: []byte("0123456789abcdef0123456789abcdef"), rsa: rk, ec: ek, ed: dk}
+}
+
+// staticKey returns a KeyFunc always returning key.
+func staticKey(key any) KeyFunc {
+	return func(Header) (any, error) { return key, nil }
+}
+
+// mustSign signs claims or fails the test.
+func mustSign(t *testing.T, alg string, key any, claims Claims, kid ...string) string {
+	t.Helper()
+	tok, err := Sign(alg, key, claims, kid...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tok
+}
+
+// go test -v -failfast -count=1 -run ^TestParseAlgorithms$
+func TestParseAlgorithms(t *testing.T) {
+	k := newTestKeys(t)
+	tests := []struct {
+		alg  string
+		sign any
+		pub  any
+	}{
+		{HS256, k.secret, k.secret},
+		{RS256, k.rsa, &k.rsa.PublicKey},
+		{ES256, k.ec, &k.ec.PublicKey},
+		{EdDSA, k.ed, k.ed.Public()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.alg, func(t *testing.T) {
+			raw := mustSign(t, tt.alg, tt.sign, Claims{"sub": "42"})
+			tok, err := Parse(raw, staticKey(tt.pub), Validation{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tok.Header.Alg != tt.alg || tok.Claims.Subject() != "42" {
+				t.Errorf("unexpected token: %+v", tok)
+			}
+
+			// flipping a bit of the signature must fail
+			parts := strings.Split(raw, ".")
+			sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+			sig[0] ^= 1
+			forged := parts[0] + "." + parts[1] + "." + base64.RawURLEncoding.EncodeToString(sig)
+			if _, err := Parse(forged, staticKey(tt.pub), Validation{}); !errors.Is(err, ErrSignature) {
+				t.Errorf("expected ErrSignature, got %v", err)
+			}
+		})
+	}
+}
+
+// go test -v -failfast -count=1 -run ^TestParseRejects$
+func TestParseRejects(t *testing.T) {
+	k := newTestKeys(t)
+	hs := mustSign(t, HS256, k.secret, Claims{"sub": "42"})
+
+	// "alg":"none" is never accepted
+	none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"42"}`)) + "."
+	if _, err := Parse(none, staticKey(k.secret), Validation{}); !errors.Is(err, ErrAlgorithm) {
+		t.Errorf("none: expected ErrAlgorithm, got %v", err)
+	}
+	// algorithm not in the allow list
+	if _, err := Parse(hs, staticKey(k.secret), Validation{Algorithms: []string{RS256}}); !errors.Is(err, ErrAlgorithm) {
+		t.Errorf("allow list: expected ErrAlgorithm, got %v", err)
+	}
+	// an RSA public key is never used as an HMAC secret
+	if _, err := Parse(hs, staticKey(&k.rsa.PublicKey), Validation{}); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("confusion: expected ErrKeyNotFound, got %v", err)
+	}
+	for _, raw := range []string{"", "a.b", "a.b.c", "e30.e30.!!"} {
+		if _, err := Parse(raw, staticKey(k.secret), Validation{}); !errors.Is(err, ErrTokenMalformed) && !errors.Is(err, ErrAlgorithm) {
+			t.Errorf("%q: expected a malformed token error, got %v", raw, err)
+		}
+	}
+}
+
+// go test -v -failfast -count=1 -run ^TestValidateClaims$
+func TestValidateClaims(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Unix(1_700_000_000, 0)
+	clock := func() time.Time { return now }
+
+	tests := []struct {
+		name   string
+		claims Claims
+		v      Validation
+		want   error
+	}{
+		{"valid", Claims{"exp": now.Add(time.Minute).Unix()}, Validation{}, nil},
+		{"expired", Claims{"exp": now.Add(-time.Minute).Unix()}, Validation{}, ErrTokenExpired},
+		{"expired_within_skew", Claims{"exp": now.Add(-time.Minute).Unix()}, Validation{ClockSkew: 2 * time.Minute}, nil},
+		{"not_yet_valid", Claims{"nbf": now.Add(time.Minute).Unix()}, Validation{}, ErrTokenNotValidYet},
+		{"nbf_within_skew", Claims{"nbf": now.Add(time.Minute).Unix()}, Validation{ClockSkew: 2 * time.Minute}, nil},
+		{"exp_required", Claims{}, Validation{RequireExpiration: true}, ErrExpirationMissing},
+		{"exp_string", Claims{"exp": "0"}, Validation{}, ErrInvalidClaim},
+		{"exp_null", Claims{"exp": nil}, Validation{RequireExpiration: true}, ErrInvalidClaim},
+		{"nbf_string", Claims{"nbf": "tomorrow"}, Validation{}, ErrInvalidClaim},
+		{"iat_bool", Claims{"iat": true}, Validation{}, ErrInvalidClaim},
+		{"issuer", Claims{"iss": "a"}, Validation{Issuer: "a"}, nil},
+		{"wrong_issuer", Claims{"iss": "b"}, Validation{Issuer: "a"}, ErrInvalidIssuer},
+		{"audience_string", Claims{"aud": "api"}, Validation{Audience: []string{"api"}}, nil},
+		{"audience_array", Claims{"aud": []string{"web", "api"}}, Validation{Audience: []string{"api"}}, nil},
+		{"wrong_audience", Claims{"aud": "web"}, Validation{Audience: []string{"api"}}, ErrInvalidAudience},
+		{"missing_audience", Claims{}, Validation{Audience: []string{"api"}}, ErrInvalidAudience},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.v.Now = clock
+			raw := mustSign(t, HS256, secret, tt.claims)
+			_, err := Parse(raw, staticKey(secret), tt.v)
+			if !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+// Package jwtauth provides JSON Web Token (RFC 7519) authentication middleware for Quick.
+//
+// Tokens are read from the Authorization header, a cookie or the query string,
+// their signature is verified and their registered claims are validated.
+// The parsed claims are then available to handlers with jwtauth.Get(c).
+//
+// Features:
+//   - HS256, RS256, ES256 and EdDSA signatures, using only the standard library.
+//   - exp, nbf, iss and aud validation with configurable clock skew.
+//   - Static keys, selected by key ID ("kid"), or a JWKS loaded from a URL or
+//     a file, cached and reloaded on key rotation.
+//   - Custom failure handler, returning 401 Unauthorized by default.
+//
+// Example:
+//
+//	q.Use(jwtauth.New(jwtauth.Config{
+//	    Key:      []byte(os.Getenv("JWT_SECRET")),
+//	    Issuer:   "https://auth.example.com",
+//	    Audience: []string{"api"},
+//	}))
+//
+//	q.Get("/me", func(c *quick.Ctx) error {
+//	    return c.JSON(map[string]string{"user": jwtauth.Get(c).Subject()})
+//	})
+package jwtauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/jeffotoni/quick"
+	"github.com/jeffotoni/quick/internal/lookup"
+)
+
+// Config defines the configuration options for the JWT middleware.
+type Config struct {
+	// Key verifies tokens without a matching entry in Keys: a []byte secret
+	// (HS256), *rsa.PublicKey, *ecdsa.PublicKey (P-256) or ed25519.PublicKey.
+	Key any
+
+	// Keys maps key IDs ("kid" header) to verification keys.
+	Keys map[string]any
+
+	// JWKSURL loads verification keys from a JWKS endpoint.
+	JWKSURL string
+
+	// JWKSFile loads verification keys from a JWKS file.
+	JWKSFile string
+
+	// JWKSRefresh is how long JWKS keys are cached. Default is 1 hour.
+	JWKSRefresh time.Duration
+
+	// HTTPClient fetches JWKSURL. Default is a client with a 10 second timeout.
+	HTTPClient *http.Client
+
+	// KeyFunc overrides the key lookup entirely.
+	KeyFunc KeyFunc
+
+	// Algorithms lists the accepted algorithms. Default accepts all supported
+	// ones; the key type must always match the algorithm.
+	Algorithms []string
+
+	// Issuer is the expected "iss" claim, if set.
+	Issuer string
+
+	// Audience lists the accepted "aud" values, if set.
+	Audience []string
+
+	// ClockSkew is the tolerance applied to exp and nbf.
+	ClockSkew time.Duration
+
+	// RequireExpiration rejects tokens without an "exp" claim.
+	RequireExpiration bool
+
+	// TokenLookup lists where the token is read from, as comma-separated
+	// "source:name" pairs tried in order. Sources are header, cookie and query.
+	// Default is "header:Authorization".
+	TokenLookup string
+
+	// AuthScheme is the scheme expected in the Authorization header.
+	// Default is "Bearer".
+	AuthScheme string
+
+	// Next is a function that determines whether to skip the middleware.
+	Next func(c *quick.Ctx) bool
+
+	// ErrorHandler is called when authentication fails. The default handler
+	// sets WWW-Authenticate and returns a 401 Unauthorized problem.
+	ErrorHandler func(c *quick.Ctx, err error) error
+}
+
+// contextKey is the request context key holding the *Token.
+type contextKey struct{}
+
+// New creates the JWT authentication middleware.
+//
+// One of Key, Keys, JWKSURL, JWKSFile or KeyFunc must be set; New panics otherwise.
+//
+// Parameters:
+//   - config: The middleware configuration.
+//
+// Returns:
+//   - func(http.Handler) http.Handler: The middleware, usable with q.Use and Group.Use.
+//
+// Example Usage:
+//
+//	q.Use(jwtauth.New(jwtauth.Config{
+//	    JWKSURL:     "https://auth.example.com/.well-known/jwks.json",
+//	    Algorithms:  []string{jwtauth.RS256},
+//	    Audience:    []string{"api"},
+//	    ClockSkew:   30 * time.Second,
+//	    TokenLookup: "header:Authorization,cookie:access_token",
+//	}))
+func New(config Config) func(http.Handler) http.Handler {
+	cfg := config
+	if cfg.TokenLookup == "" {
+		cfg.TokenLookup = "header:Authorization"
+	}
+	if cfg.AuthScheme == "" {
+		cfg.AuthScheme = "Bearer"
+	}
+	if cfg.ErrorHandler == nil {
+		cfg.ErrorHandler = defaultErrorHandler
+	}
+
+	keyFunc := buildKeyFunc(&cfg)
+	if keyFunc == nil {
+		panic("jwtauth: one of Key, Keys, JWKSURL, JWKSFile or KeyFunc is required")
+	}
+	extractors := lookup.Parse(cfg.TokenLookup, map[string]lookup.Source{
+		"header": lookup.Header(cfg.AuthScheme),
+		"cookie": lookup.Cookie,
+		"query":  lookup.Query,
+	})
+	validation := Validation{
+		Algorithms:        cfg.Algorithms,
+		Issuer:            cfg.Issuer,
+		Audience:          cfg.Audience,
+		ClockSkew:         cfg.ClockSkew,
+		RequireExpiration: cfg.RequireExpiration,
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Create a Quick context for Next and ErrorHandler
+			c := &quick.Ctx{Response: w, Request: r}
+
+			// Skip middleware if Next returns true
+			if cfg.Next != nil && cfg.Next(c) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			raw := lookup.Extract(c, extractors)
+			if raw == "" {
+				fail(c, cfg.ErrorHandler(c, ErrTokenMissing))
+				return
+			}
+
+			tok, err := Parse(raw, keyFunc, validation)
+			if err != nil {
+				fail(c, cfg.ErrorHandler(c, err))
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, tok)))
+		})
+	}
+}
+
+// fail renders the error returned by ErrorHandler, if any, with the error
+// handler of the app.
+func fail(c *quick.Ctx, err error) {
+	if err != nil {
+		quick.HandleError(c, err)
+	}
+}
+
+// Get returns the claims of the authenticated request.
+//
+// Parameters:
+//   - c: The Quick context.
+//
+// Returns:
+//   - Claims: The verified claims, or nil if the request was not authenticated by this middleware.
+//
+// Example Usage:
+//
+//	claims := jwtauth.Get(c)
+//	role, _ := claims["role"].(string)
+func Get(c *quick.Ctx) Claims {
+	if tok := GetToken(c); tok != nil {
+		return tok.Claims
+	}
+	return nil
+}
+
+// GetToken returns the verified token of the request, including its header.
+//
+// Parameters:
+//   - c: The Quick context.
+//
+// Returns:
+//   - *Token: The token, or nil if the request was not authenticated by this middleware.
+func GetToken(c *quick.Ctx) *Token {
+	tok, _ := c.Request.Context().Value(contextKey{}).(*Token)
+	return tok
+}
+
+// defaultErrorHandler rejects the request with 401 Unauthorized (RFC 6750).
+func defaultErrorHandler(c *quick.Ctx, err error) error {
+	if errors.Is(err, ErrTokenMissing) {
+		c.Set("WWW-Authenticate", `Bearer`)
+	} else {
+		c.Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	}
+	return quick.ProblemUnauthorized(err.Error()).Wrap(err)
+}
+
+// buildKeyFunc combines the configured key sources: KeyFunc, then static
+// keys, then the JWKS. It returns nil when no source is configured.
+func buildKeyFunc(cfg *Config) KeyFunc {
+	if cfg.KeyFunc != nil {
+		return cfg.KeyFunc
+	}
+
+	var jwks *JWKS
+	switch {
+	case cfg.JWKSURL != "":
+		jwks = NewJWKS(cfg.JWKSURL, cfg.JWKSRefresh, cfg.HTTPClient)
+	case cfg.JWKSFile != "":
+		jwks = NewJWKS(cfg.JWKSFile, cfg.JWKSRefresh, nil)
+	}
+	if cfg.Key == nil && len(cfg.Keys) == 0 && jwks == nil {
+		return nil
+	}
+
+	return func(h Header) (any, error) {
+		if h.Kid != "" {
+			if key, ok := cfg.Keys[h.Kid]; ok {
+				return key, nil
+			}
+		}
+		if cfg.Key != nil && (jwks == nil || h.Kid == "") {
+			return cfg.Key, nil
+		}
+		if jwks != nil {
+			return jwks.Key(h)
+		}
+		return nil, ErrKeyNotFound
+	}
+}
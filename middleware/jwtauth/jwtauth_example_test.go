@@ -0,0 +1,44 @@
+package jwtauth
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jeffotoni/quick"
+)
+
+// This function is named ExampleNew()
+//
+//	it with the Examples type.
+func ExampleNew() {
+	secret := []byte("my-256-bit-secret-my-256-bit-secret")
+
+	q := quick.New()
+
+	// Apply the JWT middleware before registering routes
+	q.Use(New(Config{Key: secret, Issuer: "quick"}))
+
+	q.Get("/me", func(c *quick.Ctx) error {
+		return c.Status(quick.StatusOK).String("hello " + Get(c).Subject())
+	})
+
+	// Issue a token, as a login endpoint would
+	token, _ := Sign(HS256, secret, Claims{
+		"sub": "jeff",
+		"iss": "quick",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	resp, err := q.Qtest(quick.QuickTestOptions{
+		Method:  quick.MethodGet,
+		URI:     "/me",
+		Headers: map[string]string{"Authorization": "Bearer " + token},
+	})
+	if err != nil {
+		fmt.Println("Test execution error:", err)
+		return
+	}
+	fmt.Println(resp.StatusCode(), resp.BodyStr())
+
+	// Output: 200 hello jeff
+}
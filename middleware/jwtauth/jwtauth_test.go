@@ -0,0 +1,105 @@
+package jwtauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jeffotoni/quick"
+)
+
+// go test -v -failfast -count=1 -run ^TestNew$
+func TestNew(t *testing.T) {
+	k := newTestKeys(t)
+	q := quick.New()
+	q.Use(New(Config{
+		Key:         k.secret,
+		Keys:        map[string]any{"ec": &k.ec.PublicKey},
+		Issuer:      "quick",
+		TokenLookup: "header:Authorization,cookie:jwt,query:token",
+	}))
+	q.Get("/me", func(c *quick.Ctx) error {
+		return c.Status(quick.StatusOK).String(Get(c).Subject() + " " + GetToken(c).Header.Alg)
+	})
+
+	valid := mustSign(t, HS256, k.secret, Claims{"sub": "jeff", "iss": "quick", "exp": time.Now().Add(time.Minute).Unix()})
+	ec := mustSign(t, ES256, k.ec, Claims{"sub": "ana", "iss": "quick"}, "ec")
+	expired := mustSign(t, HS256, k.secret, Claims{"sub": "jeff", "iss": "quick", "exp": time.Now().Add(-time.Minute).Unix()})
+
+	tests := []struct {
+		name   string
+		target string
+		header string
+		cookie string
+		code   int
+		body   string
+	}{
+		{name: "header", target: "/me", header: "Bearer " + valid, code: http.StatusOK, body: "jeff HS256"},
+		{name: "scheme_case", target: "/me", header: "bearer " + valid, code: http.StatusOK, body: "jeff HS256"},
+		{name: "kid", target: "/me", header: "Bearer " + ec, code: http.StatusOK, body: "ana ES256"},
+		{name: "cookie", target: "/me", cookie: valid, code: http.StatusOK, body: "jeff HS256"},
+		{name: "query", target: "/me?token=" + valid, code: http.StatusOK, body: "jeff HS256"},
+		{name: "missing", target: "/me", code: http.StatusUnauthorized},
+		{name: "wrong_scheme", target: "/me", header: "Basic " + valid, code: http.StatusUnauthorized},
+		{name: "expired", target: "/me", header: "Bearer " + expired, code: http.StatusUnauthorized},
+		{name: "garbage", target: "/me", header: "Bearer abc", code: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(quick.MethodGet, tt.target, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "jwt", Value: tt.cookie})
+			}
+			rec := httptest.NewRecorder()
+			q.ServeHTTP(rec, req)
+			if rec.Code != tt.code {
+				t.Fatalf("expected %d, got %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if tt.body != "" && rec.Body.String() != tt.body {
+				t.Errorf("expected %q, got %q", tt.body, rec.Body.String())
+			}
+			if tt.code == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("expected a WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+// go test -v -failfast -count=1 -run ^TestNewOptions$
+func TestNewOptions(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected New to panic without keys")
+		}
+	}()
+
+	var got error
+	q := quick.New()
+	q.Use(New(Config{
+		Key:  []byte("secret"),
+		Next: func(c *quick.Ctx) bool { return c.Path() == "/public" },
+		ErrorHandler: func(c *quick.Ctx, err error) error {
+			got = err
+			return c.Status(quick.StatusForbidden).String("denied")
+		},
+	}))
+	q.Get("/public", func(c *quick.Ctx) error { return c.Status(quick.StatusOK).String("public") })
+	q.Get("/private", func(c *quick.Ctx) error { return c.Status(quick.StatusOK).String("private") })
+
+	rec := httptest.NewRecorder()
+	q.ServeHTTP(rec, httptest.NewRequest(quick.MethodGet, "/public", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected Next to skip authentication, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	q.ServeHTTP(rec, httptest.NewRequest(quick.MethodGet, "/private", nil))
+	if rec.Code != http.StatusForbidden || got != ErrTokenMissing {
+		t.Errorf("expected custom handler with ErrTokenMissing, got %d %v", rec.Code, got)
+	}
+
+	New(Config{})
+}
@@ -0,0 +1,263 @@
+// Package keyauth provides API key authentication middleware for Quick.
+//
+// The key is read from a header, a query parameter or a cookie and resolved
+// by a lookup function into a Principal carrying metadata and scopes.
+// Handlers read the principal with keyauth.Get(c), and routes can require
+// scopes with keyauth.RequireScopes.
+//
+// Features:
+//   - Key extraction from header, query or cookie, tried in order.
+//   - Pluggable lookup returning principal metadata and scopes.
+//   - Required scopes for every route behind the middleware, or per route.
+//   - Configurable 401 and 403 payloads rendered by the error handler of the app.
+package keyauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/jeffotoni/quick"
+	"github.com/jeffotoni/quick/internal/lookup"
+)
+
+// ErrInvalidKey may be returned by a LookupFunc to reject a key.
+var ErrInvalidKey = errors.New("keyauth: invalid API key")
+
+// Principal is the client identified by an API key.
+type Principal struct {
+	ID       string         // Identifier of the client or key owner
+	Scopes   []string       // Granted scopes (e.g. "orders:read")
+	Metadata map[string]any // Any additional data (plan, tenant, ...)
+}
+
+// HasScope reports whether the principal was granted scope.
+func (p *Principal) HasScope(scope string) bool {
+	if p == nil {
+		return false
+	}
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScopes reports whether the principal was granted every scope.
+func (p *Principal) HasScopes(scopes ...string) bool {
+	for _, s := range scopes {
+		if !p.HasScope(s) {
+			return false
+		}
+	}
+	return true
+}
+
+// LookupFunc resolves an API key into a principal.
+//
+// It returns (nil, nil) or ErrInvalidKey for unknown keys, which are
+// answered with 401. Any other error is answered with 500.
+type LookupFunc func(c *quick.Ctx, key string) (*Principal, error)
+
+// Config defines the configuration options for the API key middleware.
+type Config struct {
+	// Lookup resolves keys into principals. Required.
+	Lookup LookupFunc
+
+	// KeyLookup lists where the key is read from, as comma-separated
+	// "source:name" pairs tried in order. Sources are header, query and cookie.
+	// Default is "header:X-API-Key".
+	KeyLookup string
+
+	// AuthScheme is the scheme expected when the key is read from the
+	// Authorization header. Default is "Bearer".
+	AuthScheme string
+
+	// Scopes are required for every request behind the middleware.
+	Scopes []string
+
+	// Next is a function that determines whether to skip the middleware.
+	Next func(c *quick.Ctx) bool
+
+	// Unauthorized is returned for missing or unknown keys.
+	// Default is quick.NewError(401, "missing or invalid API key").
+	Unauthorized *quick.Error
+
+	// Forbidden is returned when a required scope is missing.
+	// Default is quick.NewError(403, "insufficient scope").
+	Forbidden *quick.Error
+}
+
+// contextKey is the request context key holding the *auth.
+type contextKey struct{}
+
+// auth is the authentication result stored in the request context.
+type auth struct {
+	principal *Principal
+	cfg       *Config
+}
+
+// New creates the API key middleware.
+//
+// New panics if Config.Lookup is nil.
+//
+// Parameters:
+//   - config: The middleware configuration.
+//
+// Returns:
+//   - func(http.Handler) http.Handler: The middleware, usable with q.Use and Group.Use.
+//
+// Example Usage:
+//
+//	q.Use(keyauth.New(keyauth.Config{
+//	    KeyLookup: "header:X-API-Key,query:api_key",
+//	    Lookup: func(c *quick.Ctx, key string) (*keyauth.Principal, error) {
+//	        return db.FindAPIKey(c.Request.Context(), key)
+//	    },
+//	}))
+func New(config Config) func(http.Handler) http.Handler {
+	cfg := config
+	if cfg.Lookup == nil {
+		panic("keyauth: Config.Lookup is required")
+	}
+	if cfg.KeyLookup == "" {
+		cfg.KeyLookup = "header:X-API-Key"
+	}
+	if cfg.AuthScheme == "" {
+		cfg.AuthScheme = "Bearer"
+	}
+	if cfg.Unauthorized == nil {
+		cfg.Unauthorized = quick.NewError(quick.StatusUnauthorized, "missing or invalid API key")
+	}
+	if cfg.Forbidden == nil {
+		cfg.Forbidden = quick.NewError(quick.StatusForbidden, "insufficient scope")
+	}
+	extractors := lookup.Parse(cfg.KeyLookup, map[string]lookup.Source{
+		"header": lookup.Header(cfg.AuthScheme),
+		"query":  lookup.Query,
+		"cookie": lookup.Cookie,
+	})
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Create a Quick context for Next, Lookup and the error responses
+			c := &quick.Ctx{Response: w, Request: r}
+
+			// Skip middleware if Next returns true
+			if cfg.Next != nil && cfg.Next(c) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := lookup.Extract(c, extractors)
+			if key == "" {
+				quick.HandleError(c, cfg.Unauthorized)
+				return
+			}
+
+			p, err := cfg.Lookup(c, key)
+			switch {
+			case errors.Is(err, ErrInvalidKey) || (err == nil && p == nil):
+				quick.HandleError(c, cfg.Unauthorized)
+				return
+			case err != nil:
+				quick.HandleError(c, quick.NewError(quick.StatusInternalServerError))
+				return
+			}
+
+			if !p.HasScopes(cfg.Scopes...) {
+				quick.HandleError(c, cfg.Forbidden)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), contextKey{}, &auth{principal: p, cfg: &cfg})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Get returns the principal authenticated by the middleware.
+//
+// Parameters:
+//   - c: The Quick context.
+//
+// Returns:
+//   - *Principal: The principal, or nil if the request was not authenticated.
+//
+// Example Usage:
+//
+//	p := keyauth.Get(c)
+//	tenant, _ := p.Metadata["tenant"].(string)
+func Get(c *quick.Ctx) *Principal {
+	if a, ok := c.Request.Context().Value(contextKey{}).(*auth); ok {
+		return a.principal
+	}
+	return nil
+}
+
+// RequireScopes wraps a handler so that it only runs when the principal has
+// every scope; otherwise the configured 403 error is returned.
+//
+// Parameters:
+//   - scopes: The required scopes.
+//
+// Returns:
+//   - func(quick.HandleFunc) quick.HandleFunc: The handler wrapper.
+//
+// Example Usage:
+//
+//	q.Delete("/orders/:id", keyauth.RequireScopes("orders:write")(deleteOrder))
+func RequireScopes(scopes ...string) func(quick.HandleFunc) quick.HandleFunc {
+	return func(h quick.HandleFunc) quick.HandleFunc {
+		return func(c *quick.Ctx) error {
+			a, ok := c.Request.Context().Value(contextKey{}).(*auth)
+			if !ok {
+				return quick.NewError(quick.StatusUnauthorized, "missing or invalid API key")
+			}
+			if !a.principal.HasScopes(scopes...) {
+				return a.cfg.Forbidden
+			}
+			return h(c)
+		}
+	}
+}
+
+// StaticKeys returns a LookupFunc for a fixed set of keys.
+//
+// Keys are compared in constant time, through their SHA-256 digest.
+//
+// Parameters:
+//   - keys: The principals by API key.
+//
+// Returns:
+//   - LookupFunc: The lookup function.
+//
+// Example Usage:
+//
+//	lookup := keyauth.StaticKeys(map[string]*keyauth.Principal{
+//	    os.Getenv("BILLING_KEY"): {ID: "billing", Scopes: []string{"invoices:read"}},
+//	})
+func StaticKeys(keys map[string]*Principal) LookupFunc {
+	type entry struct {
+		sum [sha256.Size]byte
+		p   *Principal
+	}
+	entries := make([]entry, 0, len(keys))
+	for k, p := range keys {
+		entries = append(entries, entry{sha256.Sum256([]byte(k)), p})
+	}
+	return func(_ *quick.Ctx, key string) (*Principal, error) {
+		sum := sha256.Sum256([]byte(key))
+		var found *Principal
+		// Every entry is compared, so the lookup time does not depend on the key
+		for _, e := range entries {
+			if subtle.ConstantTimeCompare(sum[:], e.sum[:]) == 1 {
+				found = e.p
+			}
+		}
+		return found, nil
+	}
+}
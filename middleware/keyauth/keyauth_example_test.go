@@ -0,0 +1,44 @@
+package keyauth
+
+import (
+	"fmt"
+
+	"github.com/jeffotoni/quick"
+)
+
+// This function is named ExampleNew()
+//
+//	it with the Examples type.
+func ExampleNew() {
+	q := quick.New()
+
+	q.Use(New(Config{
+		Lookup: StaticKeys(map[string]*Principal{
+			"k-123": {ID: "billing", Scopes: []string{"invoices:read"}},
+		}),
+	}))
+
+	q.Get("/invoices", func(c *quick.Ctx) error {
+		return c.Status(quick.StatusOK).String("invoices for " + Get(c).ID)
+	})
+	q.Post("/invoices", RequireScopes("invoices:write")(func(c *quick.Ctx) error {
+		return c.Status(quick.StatusCreated).String("created")
+	}))
+
+	for _, method := range []string{quick.MethodGet, quick.MethodPost} {
+		resp, err := q.Qtest(quick.QuickTestOptions{
+			Method:  method,
+			URI:     "/invoices",
+			Headers: map[string]string{"X-API-Key": "k-123"},
+		})
+		if err != nil {
+			fmt.Println("Test execution error:", err)
+			return
+		}
+		fmt.Println(resp.StatusCode(), resp.BodyStr())
+	}
+
+	// Output:
+	// 200 invoices for billing
+	// 403 {"message":"insufficient scope","code":403}
+}
@@ -0,0 +1,167 @@
+package keyauth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jeffotoni/quick"
+)
+
+// testKeys are the principals used by the tests.
+var testKeys = map[string]*Principal{
+	"reader-key": {ID: "reader", Scopes: []string{"orders:read"}},
+	"writer-key": {ID: "writer", Scopes: []string{"orders:read", "orders:write"}, Metadata: map[string]any{"tenant": "acme"}},
+}
+
+// serve sends a request with optional header and cookie.
+func serve(q *quick.Quick, method, target string, header http.Header, cookie *http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	q.ServeHTTP(rec, req)
+	return rec
+}
+
+// go test -v -failfast -count=1 -run ^TestNew$
+func TestNew(t *testing.T) {
+	q := quick.New()
+	q.Use(New(Config{
+		Lookup:    StaticKeys(testKeys),
+		KeyLookup: "header:X-API-Key,header:Authorization,query:api_key,cookie:api_key",
+		Scopes:    []string{"orders:read"},
+	}))
+	q.Get("/orders", func(c *quick.Ctx) error {
+		return c.Status(quick.StatusOK).String(Get(c).ID)
+	})
+	q.Delete("/orders", RequireScopes("orders:write")(func(c *quick.Ctx) error {
+		tenant, _ := Get(c).Metadata["tenant"].(string)
+		return c.Status(quick.StatusOK).String("deleted for " + tenant)
+	}))
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		header http.Header
+		cookie *http.Cookie
+		code   int
+		body   string
+	}{
+		{name: "header", method: quick.MethodGet, target: "/orders", header: http.Header{"X-Api-Key": {"reader-key"}}, code: http.StatusOK, body: "reader"},
+		{name: "authorization", method: quick.MethodGet, target: "/orders", header: http.Header{"Authorization": {"Bearer writer-key"}}, code: http.StatusOK, body: "writer"},
+		{name: "query", method: quick.MethodGet, target: "/orders?api_key=reader-key", code: http.StatusOK, body: "reader"},
+		{name: "cookie", method: quick.MethodGet, target: "/orders", cookie: &http.Cookie{Name: "api_key", Value: "reader-key"}, code: http.StatusOK, body: "reader"},
+		{name: "missing", method: quick.MethodGet, target: "/orders", code: http.StatusUnauthorized},
+		{name: "unknown", method: quick.MethodGet, target: "/orders", header: http.Header{"X-Api-Key": {"nope"}}, code: http.StatusUnauthorized},
+		{name: "route_scope", method: quick.MethodDelete, target: "/orders", header: http.Header{"X-Api-Key": {"writer-key"}}, code: http.StatusOK, body: "deleted for acme"},
+		{name: "route_scope_missing", method: quick.MethodDelete, target: "/orders", header: http.Header{"X-Api-Key": {"reader-key"}}, code: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(q, tt.method, tt.target, tt.header, tt.cookie)
+			if rec.Code != tt.code {
+				t.Fatalf("expected %d, got %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if tt.body != "" && rec.Body.String() != tt.body {
+				t.Errorf("expected %q, got %q", tt.body, rec.Body.String())
+			}
+			if tt.code >= 400 {
+				var e quick.Error
+				if err := json.Unmarshal(rec.Body.Bytes(), &e); err != nil || e.Code != tt.code {
+					t.Errorf("expected a quick.Error payload, got %q", rec.Body.String())
+				}
+			}
+		})
+	}
+}
+
+// go test -v -failfast -count=1 -run ^TestNewOptions$
+func TestNewOptions(t *testing.T) {
+	q := quick.New()
+	q.Use(New(Config{
+		Lookup: func(c *quick.Ctx, key string) (*Principal, error) {
+			switch key {
+			case "revoked":
+				return nil, ErrInvalidKey
+			case "outage":
+				return nil, errors.New("database down")
+			}
+			return &Principal{ID: key}, nil
+		},
+		Scopes:       []string{"admin"},
+		Unauthorized: quick.NewError(quick.StatusUnauthorized, "api key required"),
+		Forbidden:    quick.NewError(quick.StatusForbidden, "admin only"),
+		Next: func(c *quick.Ctx) bool {
+			return c.Path() == "/public"
+		},
+	}))
+	q.Get("/admin", func(c *quick.Ctx) error { return c.Status(quick.StatusOK).String("admin") })
+	q.Get("/public", func(c *quick.Ctx) error { return c.Status(quick.StatusOK).String("public") })
+
+	tests := []struct {
+		name string
+		path string
+		key  string
+		code int
+		msg  string
+	}{
+		{"revoked", "/admin", "revoked", http.StatusUnauthorized, "api key required"},
+		{"outage", "/admin", "outage", http.StatusInternalServerError, "Internal Server Error"},
+		{"no_scope", "/admin", "someone", http.StatusForbidden, "admin only"},
+		{"skipped", "/public", "", http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(q, quick.MethodGet, tt.path, http.Header{"X-Api-Key": {tt.key}}, nil)
+			if rec.Code != tt.code {
+				t.Fatalf("expected %d, got %d", tt.code, rec.Code)
+			}
+			var e quick.Error
+			if tt.msg != "" && (json.Unmarshal(rec.Body.Bytes(), &e) != nil || e.Message != tt.msg) {
+				t.Errorf("expected message %q, got %q", tt.msg, rec.Body.String())
+			}
+		})
+	}
+}
+
+// go test -v -failfast -count=1 -run ^TestRequireScopesWithoutMiddleware$
+func TestRequireScopesWithoutMiddleware(t *testing.T) {
+	q := quick.New()
+	q.Get("/", RequireScopes("x")(func(c *quick.Ctx) error { return c.String("ok") }))
+	if rec := serve(q, quick.MethodGet, "/", nil, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", rec.Code)
+	}
+}
+
+// go test -v -failfast -count=1 -run ^TestAppErrorHandler$
+func TestAppErrorHandler(t *testing.T) {
+	q := quick.New()
+	q.SetErrorHandler(func(c *quick.Ctx, err error) error {
+		var qerr *quick.Error
+		if errors.As(err, &qerr) {
+			return c.Status(qerr.Code).String("app: " + qerr.Message)
+		}
+		return quick.DefaultErrorHandler(c, err)
+	})
+	q.Use(New(Config{Lookup: StaticKeys(testKeys), Scopes: []string{"orders:write"}}))
+	q.Get("/orders", func(c *quick.Ctx) error {
+		return c.Status(quick.StatusOK).String("ok")
+	})
+
+	rec := serve(q, quick.MethodGet, "/orders", nil, nil)
+	if rec.Code != http.StatusUnauthorized || rec.Body.String() != "app: missing or invalid API key" {
+		t.Errorf("expected the app error handler to render 401, got %d %q", rec.Code, rec.Body.String())
+	}
+	rec = serve(q, quick.MethodGet, "/orders", http.Header{"X-Api-Key": {"reader-key"}}, nil)
+	if rec.Code != http.StatusForbidden || rec.Body.String() != "app: insufficient scope" {
+		t.Errorf("expected the app error handler to render 403, got %d %q", rec.Code, rec.Body.String())
+	}
+}
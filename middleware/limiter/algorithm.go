@@ -0,0 +1,276 @@
+package limiter
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// Algorithm selects how requests are counted against Config.Max per Config.Expiration.
+type Algorithm int
+
+const (
+	// FixedWindow counts requests in a window starting at the client's first
+	// request. It is the cheapest algorithm but allows up to 2x Max around
+	// window boundaries.
+	FixedWindow Algorithm = iota
+
+	// SlidingLog stores the timestamp of every accepted request and allows a
+	// new one when fewer than Max fall within the last Expiration. It is exact
+	// but uses memory proportional to Max.
+	SlidingLog
+
+	// SlidingWindow approximates a sliding log with two counters, weighting the
+	// previous window by its overlap with the last Expiration.
+	SlidingWindow
+
+	// TokenBucket holds up to Max tokens, refilled at Max per Expiration.
+	// Each request takes one token.
+	TokenBucket
+
+	// GCRA is the generic cell rate algorithm: requests are spaced
+	// Expiration/Max apart, with bursts of up to Max. It stores a single
+	// timestamp per client.
+	GCRA
+)
+
+// String returns the name of the algorithm.
+func (a Algorithm) String() string {
+	switch a {
+	case FixedWindow:
+		return "fixed-window"
+	case SlidingLog:
+		return "sliding-log"
+	case SlidingWindow:
+		return "sliding-window"
+	case TokenBucket:
+		return "token-bucket"
+	case GCRA:
+		return "gcra"
+	}
+	return "unknown"
+}
+
+// Result describes the outcome of a request against its limit.
+type Result struct {
+	Allowed    bool          // Whether the request is within the limit
+	Limit      int           // Config.Max
+	Remaining  int           // Requests still allowed right now
+	Reset      time.Duration // Time until the limit is fully restored
+	RetryAfter time.Duration // Time until the next request is allowed, when denied
+}
+
+// take applies the algorithm to the stored state of a client.
+//
+// It returns the new state, how long the state must be kept and the result.
+// A nil or malformed state is treated as a client without previous requests.
+func (a Algorithm) take(state []byte, now time.Time, max int, window time.Duration) ([]byte, time.Duration, Result) {
+	switch a {
+	case SlidingLog:
+		return slidingLog(state, now, max, window)
+	case SlidingWindow:
+		return slidingWindow(state, now, max, window)
+	case TokenBucket:
+		return tokenBucket(state, now, max, window)
+	case GCRA:
+		return gcra(state, now, max, window)
+	}
+	return fixedWindow(state, now, max, window)
+}
+
+// refund gives back a request accepted at the given time, for requests that
+// must not be counted once their response is known.
+//
+// It returns nil if there is nothing to give back.
+func (a Algorithm) refund(state []byte, at time.Time, max int, window time.Duration) []byte {
+	t, w := at.UnixNano(), int64(window)
+	switch a {
+	case FixedWindow:
+		if v := decode(state, 2); v != nil && t >= v[0] && t < v[0]+w && v[1] > 0 {
+			return encode(v[0], v[1]-1)
+		}
+	case SlidingLog:
+		log := decode(state, len(state)/8)
+		for i := len(log) - 1; i >= 0; i-- {
+			if log[i] == t {
+				return encode(append(log[:i], log[i+1:]...)...)
+			}
+		}
+	case SlidingWindow:
+		if v := decode(state, 3); v != nil {
+			switch start := t - t%w; {
+			case v[0] == start && v[1] > 0:
+				return encode(v[0], v[1]-1, v[2])
+			case v[0] == start+w && v[2] > 0:
+				return encode(v[0], v[1], v[2]-1)
+			}
+		}
+	case TokenBucket:
+		if v := decode(state, 2); v != nil {
+			tokens := math.Min(float64(max), math.Float64frombits(uint64(v[0]))+1)
+			return encode(int64(math.Float64bits(tokens)), v[1])
+		}
+	case GCRA:
+		if v := decode(state, 1); v != nil {
+			return encode(v[0] - max64(w/int64(max), 1))
+		}
+	}
+	return nil
+}
+
+// fixedWindow keeps [window start, count].
+func fixedWindow(state []byte, now time.Time, max int, window time.Duration) ([]byte, time.Duration, Result) {
+	start, count := now.UnixNano(), int64(0)
+	if v := decode(state, 2); v != nil && now.UnixNano() < v[0]+int64(window) {
+		start, count = v[0], v[1]
+	}
+	reset := time.Duration(start + int64(window) - now.UnixNano())
+
+	res := Result{Limit: max, Reset: reset}
+	if count < int64(max) {
+		count++
+		res.Allowed = true
+	} else {
+		res.RetryAfter = reset
+	}
+	res.Remaining = max - int(count)
+	return encode(start, count), reset, res
+}
+
+// slidingLog keeps the timestamps of the accepted requests, oldest first.
+func slidingLog(state []byte, now time.Time, max int, window time.Duration) ([]byte, time.Duration, Result) {
+	from := now.UnixNano() - int64(window)
+	log := decode(state, len(state)/8)
+	for len(log) > 0 && log[0] <= from {
+		log = log[1:]
+	}
+
+	res := Result{Limit: max}
+	if len(log) < max {
+		log = append(log, now.UnixNano())
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration(log[0] - from)
+	}
+	res.Remaining = max - len(log)
+	if len(log) > 0 {
+		res.Reset = time.Duration(log[len(log)-1] - from)
+	}
+	return encode(log...), window, res
+}
+
+// slidingWindow keeps [current window start, current count, previous count].
+// Windows are aligned to multiples of the window duration.
+func slidingWindow(state []byte, now time.Time, max int, window time.Duration) ([]byte, time.Duration, Result) {
+	w := int64(window)
+	start := now.UnixNano() - now.UnixNano()%w
+	var curr, prev int64
+	if v := decode(state, 3); v != nil {
+		switch start {
+		case v[0]:
+			curr, prev = v[1], v[2]
+		case v[0] + w:
+			prev = v[1]
+		}
+	}
+
+	elapsed := now.UnixNano() - start
+	weight := float64(w-elapsed) / float64(w)
+	estimate := float64(prev)*weight + float64(curr)
+
+	res := Result{Limit: max}
+	if estimate+1 <= float64(max) {
+		curr++
+		estimate++
+		res.Allowed = true
+	} else {
+		// The previous window weighs less as time passes: wait until
+		// prev*weight + curr + 1 <= max, in this window or the next one.
+		if curr+1 <= int64(max) {
+			need := float64(int64(max)-curr-1) / float64(prev)
+			res.RetryAfter = time.Duration(math.Ceil(float64(w)*(1-need))) - time.Duration(elapsed)
+		} else {
+			need := float64(max-1) / float64(curr)
+			res.RetryAfter = time.Duration(w-elapsed) + time.Duration(math.Ceil(float64(w)*(1-need)))
+		}
+	}
+	res.Remaining = max - int(math.Ceil(estimate))
+	if res.Remaining < 0 {
+		res.Remaining = 0
+	}
+	// The current window still counts, partly, until the end of the next one
+	res.Reset = time.Duration(2*w - elapsed)
+	return encode(start, curr, prev), res.Reset, res
+}
+
+// tokenBucket keeps [tokens as float64 bits, last refill].
+func tokenBucket(state []byte, now time.Time, max int, window time.Duration) ([]byte, time.Duration, Result) {
+	rate := float64(max) / float64(window) // tokens per nanosecond
+	tokens := float64(max)
+	if v := decode(state, 2); v != nil {
+		elapsed := float64(now.UnixNano() - v[1])
+		tokens = math.Min(float64(max), math.Float64frombits(uint64(v[0]))+elapsed*rate)
+	}
+
+	res := Result{Limit: max}
+	if tokens >= 1 {
+		tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration(math.Ceil((1 - tokens) / rate))
+	}
+	res.Remaining = int(tokens)
+	res.Reset = time.Duration(math.Ceil((float64(max) - tokens) / rate))
+	return encode(int64(math.Float64bits(tokens)), now.UnixNano()), window, res
+}
+
+// gcra keeps the theoretical arrival time (TAT) of the next request.
+func gcra(state []byte, now time.Time, max int, window time.Duration) ([]byte, time.Duration, Result) {
+	interval := max64(int64(window)/int64(max), 1)
+	t := now.UnixNano()
+	tat := t
+	if v := decode(state, 1); v != nil && v[0] > t {
+		tat = v[0]
+	}
+
+	res := Result{Limit: max}
+	next := tat + interval
+	if allowAt := next - int64(window); t < allowAt {
+		res.RetryAfter = time.Duration(allowAt - t)
+		next = tat
+	} else {
+		res.Allowed = true
+	}
+	res.Remaining = int((int64(window) - (next - t)) / interval)
+	res.Reset = time.Duration(next - t)
+	return encode(next), res.Reset, res
+}
+
+// max64 returns the larger of a and b.
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// encode serializes values as big-endian int64s.
+func encode(v ...int64) []byte {
+	b := make([]byte, 8*len(v))
+	for i, n := range v {
+		binary.BigEndian.PutUint64(b[8*i:], uint64(n))
+	}
+	return b
+}
+
+// decode reads n big-endian int64s, or returns nil if state does not hold exactly n.
+func decode(state []byte, n int) []int64 {
+	if n == 0 || len(state) != 8*n {
+		return nil
+	}
+	v := make([]int64, n)
+	for i := range v {
+		v[i] = int64(binary.BigEndian.Uint64(state[8*i:]))
+	}
+	return v
+}
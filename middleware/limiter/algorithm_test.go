@@ -0,0 +1,79 @@
+package limiter
+
+import (
+	"testing"
+	"time"
+)
+
+// run sends requests at the given offsets from t0 and returns which were allowed.
+func run(a Algorithm, max int, window time.Duration, offsets ...time.Duration) []bool {
+	t0 := time.Unix(1_700_000_000, 0)
+	var state []byte
+	allowed := make([]bool, len(offsets))
+	for i, off := range offsets {
+		var res Result
+		state, _, res = a.take(state, t0.Add(off), max, window)
+		allowed[i] = res.Allowed
+	}
+	return allowed
+}
+
+// TestAlgorithms verifies the behavior of each algorithm with 3 requests per
+// second, including the burst at a window boundary that FixedWindow allows
+// and the other algorithms reject.
+//
+// go test -v -failfast -count=1 -run ^TestAlgorithms$
+func TestAlgorithms(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		algo    Algorithm
+		offsets []time.Duration
+		want    []bool
+	}{
+		// 5 requests accepted within 100ms around the window boundary
+		{FixedWindow, []time.Duration{0, 900 * ms, 900 * ms, 900 * ms, 1000 * ms, 1000 * ms, 1000 * ms, 1000 * ms}, []bool{true, true, true, false, true, true, true, false}},
+		{SlidingLog, []time.Duration{0, 900 * ms, 900 * ms, 900 * ms, 1000 * ms, 1001 * ms, 1901 * ms}, []bool{true, true, true, false, true, false, true}},
+		{SlidingWindow, []time.Duration{0, 100 * ms, 200 * ms, 300 * ms, 1000 * ms, 1400 * ms, 2000 * ms}, []bool{true, true, true, false, false, true, true}},
+		{TokenBucket, []time.Duration{0, 0, 0, 0, 100 * ms, 334 * ms, 334 * ms}, []bool{true, true, true, false, false, true, false}},
+		{GCRA, []time.Duration{0, 0, 0, 0, 100 * ms, 334 * ms, 334 * ms}, []bool{true, true, true, false, false, true, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.algo.String(), func(t *testing.T) {
+			got := run(tt.algo, 3, time.Second, tt.offsets...)
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("request %d at %v: allowed=%v, want %v (all: %v)", i, tt.offsets[i], got[i], tt.want[i], got)
+				}
+			}
+		})
+	}
+}
+
+// TestAlgorithmsResult verifies Remaining and RetryAfter for each algorithm.
+//
+// go test -v -failfast -count=1 -run ^TestAlgorithmsResult$
+func TestAlgorithmsResult(t *testing.T) {
+	t0 := time.Unix(1_700_000_000, 0)
+	for _, algo := range []Algorithm{FixedWindow, SlidingLog, SlidingWindow, TokenBucket, GCRA} {
+		t.Run(algo.String(), func(t *testing.T) {
+			var state []byte
+			var res Result
+			for i := 0; i < 2; i++ {
+				state, _, res = algo.take(state, t0, 2, time.Second)
+			}
+			if !res.Allowed || res.Remaining != 0 || res.Limit != 2 {
+				t.Fatalf("unexpected result after the last allowed request: %+v", res)
+			}
+			state, ttl, res := algo.take(state, t0, 2, time.Second)
+			if res.Allowed || res.RetryAfter <= 0 || res.RetryAfter > 2*time.Second {
+				t.Fatalf("unexpected result when denied: %+v", res)
+			}
+			if ttl <= 0 || len(state) == 0 {
+				t.Errorf("state must be kept: ttl=%v len=%d", ttl, len(state))
+			}
+			if _, _, res = algo.take(state, t0.Add(res.RetryAfter), 2, time.Second); !res.Allowed {
+				t.Errorf("expected a request after RetryAfter to be allowed: %+v", res)
+			}
+		})
+	}
+}
@@ -1,170 +1,352 @@
-	// Package limiter provides middleware for rate limiting HTTP requests in Quick.
-	//
-	// This middleware controls the number of requests a client can make within a specified time window,
-	// helping to prevent abuse, protect APIs from excessive traffic, and improve overall system stability.
-	//
-	// Features:
-	// - Configurable maximum requests per time window.
-	// - Customizable key generator (e.g., per-IP, per-user, etc.).
-	// - Flexible expiration time for rate-limited requests.
-	// - Custom handler when the request limit is exceeded.
-	// - Uses sharded maps for efficient concurrency handling.
-	// - Periodic cleanup of expired request records to optimize memory usage.
-	package limiter
-
-	import (
-		"fmt"
-		"hash/fnv"
-		"net/http"
-		"sync"
-		"time"
-
-		"github.com/jeffotoni/quick"
-	)
-
-	// Config defines the rate limiting configuration.
-	//
-	// Max: maximum number of requests allowed within the expiration window.
-	// Expiration: duration before the request counter resets.
-	// KeyGenerator: function to generate a unique key for each client (e.g., IP-based).
-	// LimitReached: function called when the client exceeds the request limit.
-	type Config struct {
-		Max          int                     // Maximum requests allowed in the time window
-		Expiration   time.Duration           // Time window for rate limiting
-		KeyGenerator func(*quick.Ctx) string // Function to generate a unique key per client
-		LimitReached func(*quick.Ctx) error  // Function executed when rate limit is exceeded
-	}
-
-	// client tracks individual request data for rate limiting.
-	type client struct {
-		mu       sync.Mutex
-		requests int       // Number of requests made in the current window
-		expires  time.Time // When the current window expires
-	}
-
-	// RateLimiter manages all rate limiting logic, storing request counters across multiple shards.
-	type RateLimiter struct {
-		config     Config      // Rate limiting settings
-		shards     []*sync.Map // Sharded maps for distributing load and reducing contention
-		shardCount uint32      // Number of shards for concurrency
-	}
-
-	// New creates a middleware constructor that returns a standard http.Handler wrapper.
-	//
-	// Usage:
-	//
-	//	q.Use(limiter.New(limiter.Config{
-	//	    Max:        3,
-	//	    Expiration: 2 * time.Second,
-	//	    KeyGenerator: func(c *quick.Ctx) string {
-	//	        // Return IP without port, or a fixed test key.
-	//	        return "testKey"
-	//	    },
-	//	    LimitReached: func(c *quick.Ctx) error {
-	//	        return c.Status(http.StatusTooManyRequests).SendString("Too many requests")
-	//	    },
-	//	}))
-	//
-	// The returned function integrates with Quick's middleware chain and enforces rate limits.
-	func New(config Config) func(http.Handler) http.Handler {
-		rl := &RateLimiter{
-			config:     config,
-			shardCount: 256, // Default shard count for concurrency
-		}
+// Package limiter provides middleware for rate limiting HTTP requests in Quick.
+//
+// This middleware controls the number of requests a client can make within a specified time window,
+// helping to prevent abuse, protect APIs from excessive traffic, and improve overall system stability.
+//
+// Features:
+// - Configurable maximum requests per time window.
+// - Customizable key generator (e.g., per-IP, per-user, etc.).
+// - Selectable algorithms: fixed window, sliding log, sliding window, token bucket and GCRA.
+// - Pluggable storage: in-memory by default, Redis for limits shared across instances.
+// - Limits per app, per group (Group.Use) or per route (Route).
+// - RateLimit-* (IETF draft) or X-RateLimit-* response headers, and Retry-After on rejection.
+// - Optionally skips counting failed or successful requests.
+// - Custom handler when the request limit is exceeded.
+package limiter
 
-		// Initialize the shards
-		rl.shards = make([]*sync.Map, rl.shardCount)
-		for i := 0; i < int(rl.shardCount); i++ {
-			rl.shards[i] = &sync.Map{}
-		}
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
 
-		// Start the background cleanup to remove expired clients
-		go rl.startCleanup()
+	"github.com/jeffotoni/quick"
+)
 
-		// Return the middleware constructor
-		return func(next http.Handler) http.Handler {
-			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				// Create a Quick context to allow KeyGenerator or LimitReached usage.
-				c := &quick.Ctx{
-					Response: w,
-					Request:  r,
-				}
+// Config defines the rate limiting configuration.
+//
+// Max: maximum number of requests allowed within the expiration window.
+// Expiration: duration of the window.
+// KeyGenerator: function to generate a unique key for each client (e.g., IP-based).
+// LimitReached: function called when the client exceeds the request limit; returned errors are rendered by the error handler of the app.
+// Algorithm: how requests are counted (FixedWindow by default).
+// Storage: where the state of each client is kept (in-memory by default).
+// KeyPrefix: namespace of the keys, required to share a Storage between limiters.
+// Next: function that determines whether to skip the middleware, e.g. to exempt keys.
+// Headers: which rate limit headers are sent (HeadersDraft by default).
+// SkipFailedRequests: do not count requests answered with a status >= 400.
+// SkipSuccessfulRequests: do not count requests answered with a status < 400.
+type Config struct {
+	Max          int                     // Maximum requests allowed in the time window (default 5)
+	Expiration   time.Duration           // Time window for rate limiting (default 1 minute)
+	KeyGenerator func(*quick.Ctx) string // Function to generate a unique key per client (default c.RemoteIP)
+	LimitReached func(*quick.Ctx) error  // Function executed when rate limit is exceeded (default 429 quick.Error)
+	Algorithm    Algorithm               // Counting algorithm (default FixedWindow)
+	Storage      Storage                 // Client state storage (default NewMemoryStorage())
+	KeyPrefix    string                  // Prefix of the storage keys
+	Next         func(*quick.Ctx) bool   // Skip the limiter when it returns true
 
-				// Generate the key (e.g., IP) for this client.
-				key := rl.config.KeyGenerator(c)
-				//fmt.Println("[DEBUG] Key:", key)
-
-				// Find the shard and load the client's request counter.
-				shard := rl.getShard(key)
-				now := time.Now()
-
-				val, _ := shard.LoadOrStore(key, &client{
-					requests: 0,
-					expires:  now.Add(rl.config.Expiration),
-				})
-				cl := val.(*client)
-
-				// Lock to safely update the request counter.
-				cl.mu.Lock()
-				defer cl.mu.Unlock()
-
-				// Reset if the current window expired.
-				if now.After(cl.expires) {
-					//fmt.Println("[DEBUG] Resetting requests")
-					cl.requests = 0
-					cl.expires = now.Add(rl.config.Expiration)
-				}
+	Headers                HeaderStyle // Rate limit headers (default HeadersDraft)
+	SkipFailedRequests     bool        // Give back requests answered with a status >= 400
+	SkipSuccessfulRequests bool        // Give back requests answered with a status < 400
+}
 
-				// Increment the request count.
-				cl.requests++
-				//fmt.Printf("[DEBUG] requests=%d, max=%d\n", cl.requests, rl.config.Max)
-
-				// If the client exceeded the limit, call LimitReached and stop.
-				if cl.requests > rl.config.Max {
-					//fmt.Println("[DEBUG] Limit reached: calling LimitReached")
-					err := rl.config.LimitReached(c)
-					if err != nil {
-						fmt.Println("[DEBUG] LimitReached error:", err)
-					}
-					return
-				}
+// HeaderStyle selects the rate limit headers sent with each response.
+type HeaderStyle int
+
+const (
+	// HeadersDraft sends RateLimit-Limit, RateLimit-Remaining and
+	// RateLimit-Reset (seconds until reset), from the IETF RateLimit
+	// header fields draft.
+	HeadersDraft HeaderStyle = iota
+
+	// HeadersXRateLimit sends X-RateLimit-Limit, X-RateLimit-Remaining and
+	// X-RateLimit-Reset (Unix time of the reset in seconds).
+	HeadersXRateLimit
 
-				// Otherwise, pass to the next handler.
+	// HeadersNone sends no rate limit headers. Retry-After is still sent
+	// on rejection.
+	HeadersNone
+)
+
+// lockShards is the number of locks serializing updates of the same key.
+const lockShards = 256
+
+// casAttempts bounds the retries of an update with a CompareAndSwapper storage
+// that other processes keep changing; the last result is then used as is.
+const casAttempts = 8
+
+// RateLimiter applies a Config to incoming requests.
+type RateLimiter struct {
+	config Config                 // Rate limiting settings
+	locks  [lockShards]sync.Mutex // Serialize the read-modify-write of a key within the process
+}
+
+// New creates a middleware constructor that returns a standard http.Handler wrapper.
+//
+// Usage:
+//
+//	q.Use(limiter.New(limiter.Config{
+//	    Max:        3,
+//	    Expiration: 2 * time.Second,
+//	    Algorithm:  limiter.SlidingWindow,
+//	    KeyGenerator: func(c *quick.Ctx) string {
+//	        // Return IP without port, or a fixed test key.
+//	        return "testKey"
+//	    },
+//	    LimitReached: func(c *quick.Ctx) error {
+//	        return c.Status(http.StatusTooManyRequests).SendString("Too many requests")
+//	    },
+//	}))
+//
+// The returned function integrates with Quick's middleware chain and enforces rate limits.
+// Apply it with Group.Use to limit a group of routes.
+func New(config Config) func(http.Handler) http.Handler {
+	rl := newRateLimiter(config)
+
+	// Return the middleware constructor
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Create a Quick context to allow KeyGenerator or LimitReached usage.
+			c := &quick.Ctx{
+				Response: w,
+				Request:  r,
+			}
+
+			allowed, counted := rl.limit(c)
+			if !allowed {
+				// The client exceeded the limit: call LimitReached and stop.
+				if err := rl.config.LimitReached(c); err != nil {
+					quick.HandleError(c, err)
+				}
+				return
+			}
+			if counted == nil || !rl.skips() {
 				next.ServeHTTP(w, r)
-			})
+				return
+			}
+
+			sw := &statusWriter{ResponseWriter: w}
+			next.ServeHTTP(sw, r)
+			rl.settle(counted, sw.status)
+		})
+	}
+}
+
+// Route creates a limiter for a single route.
+//
+// Each call has its own limit, so routes can be given different limits
+// than the app or group they belong to.
+//
+// Usage:
+//
+//	q.Post("/login", limiter.Route(limiter.Config{
+//	    Max:        5,
+//	    Expiration: time.Minute,
+//	    Algorithm:  limiter.GCRA,
+//	})(loginHandler))
+func Route(config Config) func(quick.HandleFunc) quick.HandleFunc {
+	rl := newRateLimiter(config)
+	return func(h quick.HandleFunc) quick.HandleFunc {
+		return func(c *quick.Ctx) error {
+			allowed, counted := rl.limit(c)
+			if !allowed {
+				return rl.config.LimitReached(c)
+			}
+			if counted == nil || !rl.skips() {
+				return h(c)
+			}
+
+			sw := &statusWriter{ResponseWriter: c.Response}
+			c.Response = sw
+			err := h(c)
+			c.Response = sw.ResponseWriter
+
+			status := sw.status
+			if err != nil && status == 0 {
+				// The error is rendered by the error handler after this returns
+				status = http.StatusInternalServerError
+			}
+			rl.settle(counted, status)
+			return err
 		}
 	}
+}
 
-	// getShard selects which shard map is used for the given key.
-	func (rl *RateLimiter) getShard(key string) *sync.Map {
-		h := fnv.New32a()
-		h.Write([]byte(key))
-		return rl.shards[h.Sum32()%rl.shardCount]
+// newRateLimiter applies the defaults of config.
+func newRateLimiter(config Config) *RateLimiter {
+	if config.Max <= 0 {
+		config.Max = 5
+	}
+	if config.Expiration <= 0 {
+		config.Expiration = time.Minute
+	}
+	if config.KeyGenerator == nil {
+		config.KeyGenerator = func(c *quick.Ctx) string {
+			return c.RemoteIP()
+		}
+	}
+	if config.LimitReached == nil {
+		config.LimitReached = func(c *quick.Ctx) error {
+			return quick.NewError(quick.StatusTooManyRequests)
+		}
+	}
+	if config.Storage == nil {
+		config.Storage = NewMemoryStorage()
 	}
+	return &RateLimiter{config: config}
+}
 
-	// startCleanup periodically removes expired client entries.
-	func (rl *RateLimiter) startCleanup() {
-		tick := time.NewTicker(30 * time.Second)
-		defer tick.Stop()
-		for range tick.C {
-			rl.cleanup()
+// hit identifies a counted request, so it can be given back.
+type hit struct {
+	key string
+	at  time.Time
+}
+
+// limit counts the request and sets the rate limit headers.
+//
+// It reports whether the request may proceed and returns the counted hit,
+// or nil if the request was not counted. Requests skipped by Next are
+// allowed. Storage errors fail open, so an unavailable storage does not
+// take the application down.
+func (rl *RateLimiter) limit(c *quick.Ctx) (bool, *hit) {
+	if rl.config.Next != nil && rl.config.Next(c) {
+		return true, nil
+	}
+	h := &hit{key: rl.config.KeyGenerator(c), at: time.Now()}
+	if rl.config.KeyPrefix != "" {
+		h.key = rl.config.KeyPrefix + ":" + h.key
+	}
+	res, err := rl.take(h.key, h.at)
+	if err != nil {
+		return true, nil
+	}
+	rl.setHeaders(c.Response.Header(), res, h.at)
+	if !res.Allowed {
+		return false, nil
+	}
+	return true, h
+}
+
+// skips reports whether some requests are given back once answered.
+func (rl *RateLimiter) skips() bool {
+	return rl.config.SkipFailedRequests || rl.config.SkipSuccessfulRequests
+}
+
+// settle gives back the hit if its response status must not be counted.
+func (rl *RateLimiter) settle(h *hit, status int) {
+	if status == 0 {
+		status = http.StatusOK
+	}
+	failed := status >= http.StatusBadRequest
+	if (failed && !rl.config.SkipFailedRequests) || (!failed && !rl.config.SkipSuccessfulRequests) {
+		return
+	}
+
+	mu := &rl.locks[hashKey(h.key)%lockShards]
+	mu.Lock()
+	defer mu.Unlock()
+
+	cas, atomic := rl.config.Storage.(CompareAndSwapper)
+	for attempt := 1; ; attempt++ {
+		state, err := rl.config.Storage.Get(h.key)
+		if err != nil {
+			return
+		}
+		next := rl.config.Algorithm.refund(state, h.at, rl.config.Max, rl.config.Expiration)
+		if next == nil {
+			return
+		}
+		if !atomic {
+			_ = rl.config.Storage.Set(h.key, next, 2*rl.config.Expiration)
+			return
+		}
+		swapped, err := cas.CompareAndSwap(h.key, state, next, 2*rl.config.Expiration)
+		if err != nil || swapped || attempt == casAttempts {
+			return
 		}
 	}
+}
 
-	// cleanup checks every shard and deletes clients whose expiration time has passed.
-	func (rl *RateLimiter) cleanup() {
-		now := time.Now()
-		for _, shard := range rl.shards {
-			shard.Range(func(k, v interface{}) bool {
-				cl := v.(*client)
-				cl.mu.Lock()
-				expired := now.After(cl.expires)
-				cl.mu.Unlock()
+// setHeaders writes the rate limit headers of res, and Retry-After when denied.
+func (rl *RateLimiter) setHeaders(header http.Header, res Result, now time.Time) {
+	if !res.Allowed {
+		header.Set("Retry-After", strconv.FormatInt(seconds(res.RetryAfter), 10))
+	}
+	switch rl.config.Headers {
+	case HeadersDraft:
+		header.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		header.Set("RateLimit-Reset", strconv.FormatInt(seconds(res.Reset), 10))
+	case HeadersXRateLimit:
+		header.Set("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+		header.Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		header.Set("X-RateLimit-Reset", strconv.FormatInt(now.Add(res.Reset).Unix(), 10))
+	}
+}
 
-				if expired {
-					shard.Delete(k)
-				}
-				return true
-			})
+// seconds rounds d up to whole seconds.
+func seconds(d time.Duration) int64 {
+	return int64(math.Ceil(d.Seconds()))
+}
+
+// take counts a request of the client identified by key.
+func (rl *RateLimiter) take(key string, now time.Time) (Result, error) {
+	mu := &rl.locks[hashKey(key)%lockShards]
+	mu.Lock()
+	defer mu.Unlock()
+
+	cas, atomic := rl.config.Storage.(CompareAndSwapper)
+	for attempt := 1; ; attempt++ {
+		state, err := rl.config.Storage.Get(key)
+		if err != nil {
+			return Result{}, err
+		}
+		next, ttl, res := rl.config.Algorithm.take(state, now, rl.config.Max, rl.config.Expiration)
+		if !atomic {
+			return res, rl.config.Storage.Set(key, next, ttl)
+		}
+		swapped, err := cas.CompareAndSwap(key, state, next, ttl)
+		if err != nil {
+			return Result{}, err
+		}
+		if swapped || attempt == casAttempts {
+			return res, nil
 		}
 	}
+}
+
+// statusWriter records the status code of the response.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records the status code, then writes it.
+func (w *statusWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write records an implicit 200 status, then writes the body.
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher for streaming responses.
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the original ResponseWriter, for http.ResponseController.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
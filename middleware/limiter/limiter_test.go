@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
@@ -101,3 +102,263 @@ func TestLimiterMiddleware(t *testing.T) {
 		t.Errorf("Expected 200 OK after expiration, got %d", resp.StatusCode)
 	}
 }
+
+// TestRoute verifies per-route limits and the default 429 response.
+//
+// go test -v -failfast -count=1 -run ^TestRoute$
+func TestRoute(t *testing.T) {
+	q := quick.New()
+	q.Post("/login", Route(Config{
+		Max:          2,
+		Expiration:   time.Minute,
+		Algorithm:    GCRA,
+		KeyGenerator: func(c *quick.Ctx) string { return "client" },
+	})(func(c *quick.Ctx) error {
+		return c.Status(quick.StatusOK).String("ok")
+	}))
+	q.Get("/free", func(c *quick.Ctx) error {
+		return c.Status(quick.StatusOK).String("ok")
+	})
+
+	send := func(method, path string) int {
+		rec := httptest.NewRecorder()
+		q.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec.Code
+	}
+
+	for i, want := range []int{200, 200, 429} {
+		if got := send(quick.MethodPost, "/login"); got != want {
+			t.Fatalf("request %d: expected %d, got %d", i+1, want, got)
+		}
+	}
+	if got := send(quick.MethodGet, "/free"); got != http.StatusOK {
+		t.Errorf("expected other routes to be unaffected, got %d", got)
+	}
+}
+
+// TestNext verifies that keys exempted by Next are neither limited nor
+// counted, and get no rate limit headers, with the middleware and with Route.
+//
+// go test -v -failfast -count=1 -run ^TestNext$
+func TestNext(t *testing.T) {
+	cfg := Config{
+		Max:          1,
+		Expiration:   time.Minute,
+		KeyGenerator: func(c *quick.Ctx) string { return c.Request.Header.Get("X-Key") },
+		Next: func(c *quick.Ctx) bool {
+			return c.Request.Header.Get("X-Key") == "internal"
+		},
+	}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	q := quick.New()
+	q.Post("/login", Route(cfg)(func(c *quick.Ctx) error {
+		return c.Status(quick.StatusOK).String("ok")
+	}))
+
+	for name, h := range map[string]http.Handler{"middleware": New(cfg)(ok), "route": q} {
+		var codes []int
+		for _, key := range []string{"internal", "internal", "client", "internal", "client"} {
+			req := httptest.NewRequest(quick.MethodPost, "/login", nil)
+			req.Header.Set("X-Key", key)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if key == "internal" && rec.Header().Get("RateLimit-Limit") != "" {
+				t.Errorf("%s: expected no rate limit headers for an exempted key", name)
+			}
+			codes = append(codes, rec.Code)
+		}
+		if fmt.Sprint(codes) != "[200 200 200 200 429]" {
+			t.Errorf("%s: unexpected status codes %v", name, codes)
+		}
+	}
+}
+
+// TestSharedStorage verifies that limiters sharing a Storage, as instances
+// of an application sharing Redis do, enforce a single limit.
+//
+// go test -v -failfast -count=1 -run ^TestSharedStorage$
+func TestSharedStorage(t *testing.T) {
+	store, err := NewRedisStorage(RedisConfig{Client: newMockRedis()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := Config{
+		Max:          3,
+		Expiration:   time.Minute,
+		Algorithm:    SlidingWindow,
+		Storage:      store,
+		KeyGenerator: func(c *quick.Ctx) string { return "client" },
+	}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	instances := []http.Handler{New(cfg)(ok), New(cfg)(ok)}
+
+	var codes []int
+	for i := 0; i < 4; i++ {
+		rec := httptest.NewRecorder()
+		instances[i%2].ServeHTTP(rec, httptest.NewRequest(quick.MethodGet, "/", nil))
+		codes = append(codes, rec.Code)
+	}
+	if fmt.Sprint(codes) != "[200 200 200 429]" {
+		t.Errorf("unexpected status codes: %v", codes)
+	}
+}
+
+// TestSharedStorageAtomic verifies that a request counted by another instance
+// between the read and the write of a key is not lost when the Redis client
+// can run scripts.
+//
+// go test -v -failfast -count=1 -run ^TestSharedStorageAtomic$
+func TestSharedStorageAtomic(t *testing.T) {
+	redis := &evalRedis{mockRedis: newMockRedis()}
+	store, err := NewRedisStorage(RedisConfig{Client: redis})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, algo := range []Algorithm{FixedWindow, SlidingLog, SlidingWindow, TokenBucket, GCRA} {
+		t.Run(algo.String(), func(t *testing.T) {
+			cfg := Config{
+				Max:          1,
+				Expiration:   time.Minute,
+				Algorithm:    algo,
+				Storage:      store,
+				KeyPrefix:    algo.String(),
+				KeyGenerator: func(c *quick.Ctx) string { return "client" },
+			}
+			ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			a, b := New(cfg)(ok), New(cfg)(ok)
+			serve := func(h http.Handler) int {
+				rec := httptest.NewRecorder()
+				h.ServeHTTP(rec, httptest.NewRequest(quick.MethodGet, "/", nil))
+				return rec.Code
+			}
+
+			// b counts the only allowed request while a is between its read and write
+			var other int
+			redis.before = func() { other = serve(b) }
+			if got := serve(a); other != http.StatusOK || got != http.StatusTooManyRequests {
+				t.Errorf("expected one request to be accepted, got %d and %d", other, got)
+			}
+		})
+	}
+}
+
+// TestHeaders verifies the RateLimit-* and X-RateLimit-* headers and
+// Retry-After on rejection.
+//
+// go test -v -failfast -count=1 -run ^TestHeaders$
+func TestHeaders(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	key := func(c *quick.Ctx) string { return "client" }
+
+	t.Run("draft", func(t *testing.T) {
+		h := New(Config{Max: 2, Expiration: 10 * time.Second, KeyGenerator: key})(ok)
+		want := []map[string]string{
+			{"RateLimit-Limit": "2", "RateLimit-Remaining": "1", "RateLimit-Reset": "10", "Retry-After": ""},
+			{"RateLimit-Limit": "2", "RateLimit-Remaining": "0", "RateLimit-Reset": "10", "Retry-After": ""},
+			{"RateLimit-Limit": "2", "RateLimit-Remaining": "0", "RateLimit-Reset": "10", "Retry-After": "10"},
+		}
+		for i, headers := range want {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(quick.MethodGet, "/", nil))
+			for name, value := range headers {
+				if got := rec.Header().Get(name); got != value {
+					t.Errorf("request %d: %s = %q, want %q", i+1, name, got, value)
+				}
+			}
+		}
+	})
+
+	t.Run("x-ratelimit", func(t *testing.T) {
+		h := New(Config{Max: 2, Expiration: 10 * time.Second, KeyGenerator: key, Headers: HeadersXRateLimit})(ok)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(quick.MethodGet, "/", nil))
+		reset, _ := strconv.ParseInt(rec.Header().Get("X-RateLimit-Reset"), 10, 64)
+		if rec.Header().Get("X-RateLimit-Limit") != "2" || rec.Header().Get("X-RateLimit-Remaining") != "1" ||
+			reset < time.Now().Unix()+9 || rec.Header().Get("RateLimit-Limit") != "" {
+			t.Errorf("unexpected headers: %v", rec.Header())
+		}
+	})
+
+	t.Run("none", func(t *testing.T) {
+		h := New(Config{Max: 1, KeyGenerator: key, Headers: HeadersNone})(ok)
+		var rec *httptest.ResponseRecorder
+		for i := 0; i < 2; i++ {
+			rec = httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(quick.MethodGet, "/", nil))
+		}
+		if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" || rec.Header().Get("RateLimit-Limit") != "" {
+			t.Errorf("unexpected response: %d %v", rec.Code, rec.Header())
+		}
+	})
+}
+
+// TestSkipRequests verifies that failed or successful requests can be left
+// out of the count, with the middleware and with Route.
+//
+// go test -v -failfast -count=1 -run ^TestSkipRequests$
+func TestSkipRequests(t *testing.T) {
+	for _, algo := range []Algorithm{FixedWindow, SlidingLog, SlidingWindow, TokenBucket, GCRA} {
+		t.Run(algo.String(), func(t *testing.T) {
+			q := quick.New()
+			cfg := Config{
+				Max:                2,
+				Expiration:         time.Minute,
+				Algorithm:          algo,
+				KeyGenerator:       func(c *quick.Ctx) string { return "client" },
+				SkipFailedRequests: true,
+			}
+			// Failed logins count, successful ones do not
+			login := Route(Config{
+				Max:                    2,
+				Expiration:             time.Minute,
+				Algorithm:              algo,
+				KeyGenerator:           func(c *quick.Ctx) string { return "client" },
+				SkipSuccessfulRequests: true,
+			})
+			api := q.Group("/api")
+			api.Use(New(cfg))
+			api.Get("/item", func(c *quick.Ctx) error {
+				if c.Query["fail"] == "1" {
+					return c.Status(quick.StatusNotFound).String("missing")
+				}
+				return c.Status(quick.StatusOK).String("ok")
+			})
+			q.Post("/login", login(func(c *quick.Ctx) error {
+				if c.Query["ok"] == "1" {
+					return c.Status(quick.StatusOK).String("welcome")
+				}
+				return quick.NewError(quick.StatusUnauthorized)
+			}))
+
+			send := func(method, target string) int {
+				rec := httptest.NewRecorder()
+				q.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+				return rec.Code
+			}
+
+			var codes []int
+			for _, target := range []string{"/api/item?fail=1", "/api/item?fail=1", "/api/item?fail=1", "/api/item", "/api/item", "/api/item"} {
+				codes = append(codes, send(quick.MethodGet, target))
+			}
+			if fmt.Sprint(codes) != "[404 404 404 200 200 429]" {
+				t.Errorf("SkipFailedRequests: unexpected status codes %v", codes)
+			}
+
+			codes = codes[:0]
+			for _, target := range []string{"/login?ok=1", "/login?ok=1", "/login?ok=1", "/login", "/login", "/login"} {
+				codes = append(codes, send(quick.MethodPost, target))
+			}
+			if fmt.Sprint(codes) != "[200 200 200 401 401 429]" {
+				t.Errorf("SkipSuccessfulRequests: unexpected status codes %v", codes)
+			}
+		})
+	}
+}
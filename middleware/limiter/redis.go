@@ -0,0 +1,116 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jeffotoni/quick/middleware/cache"
+)
+
+// RedisStorage is a Storage backed by Redis, shared by every instance of an
+// application so that limits apply to the whole deployment.
+//
+// It uses the same RedisClient interface as the cache middleware, so one
+// adapter serves both. That interface has no atomic read-modify-write: when
+// the adapter also implements RedisEvaler, updates go through a Lua script
+// and limits are exact across processes. Otherwise two instances updating
+// the same key at the same moment may both accept a request, and limits are
+// enforced exactly within a process and approximately across processes.
+//
+// RedisClient does not distinguish a missing key from a failure either;
+// Get errors are treated as a missing key, so the limiter fails open while
+// Redis is unavailable.
+type RedisStorage struct {
+	client    cache.RedisClient
+	keyPrefix string
+	ctx       context.Context
+}
+
+// RedisEvaler is implemented by Redis clients able to run Lua scripts, such as
+// an adapter over go-redis's Eval. Integer replies must be returned as int64.
+type RedisEvaler interface {
+	Eval(ctx context.Context, script string, keys []string, args ...any) (any, error)
+}
+
+// casScript sets KEYS[1] to ARGV[2] for ARGV[3] milliseconds if its value is
+// still ARGV[1], an empty ARGV[1] standing for a missing key.
+const casScript = `
+local cur = redis.call('GET', KEYS[1])
+if (cur == false and ARGV[1] == '') or cur == ARGV[1] then
+  redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+  return 1
+end
+return 0`
+
+// RedisConfig defines the configuration for Redis storage.
+type RedisConfig struct {
+	// Client is the Redis client implementation to use.
+	Client cache.RedisClient
+
+	// KeyPrefix is an optional prefix for all keys.
+	// Default is "limiter".
+	KeyPrefix string
+}
+
+// NewRedisStorage creates a new Redis storage instance.
+//
+// Parameters:
+//   - config: The Redis client and key prefix.
+//
+// Returns:
+//   - *RedisStorage: The storage.
+//   - error: If no client is configured.
+//
+// Example Usage:
+//
+//	store, err := limiter.NewRedisStorage(limiter.RedisConfig{Client: myRedisAdapter})
+func NewRedisStorage(config RedisConfig) (*RedisStorage, error) {
+	if config.Client == nil {
+		return nil, errors.New("redis client is required")
+	}
+	if config.KeyPrefix == "" {
+		config.KeyPrefix = "limiter"
+	}
+	return &RedisStorage{
+		client:    config.Client,
+		keyPrefix: config.KeyPrefix,
+		ctx:       context.Background(),
+	}, nil
+}
+
+// Get retrieves a value from Redis.
+func (r *RedisStorage) Get(key string) ([]byte, error) {
+	data, err := r.client.Get(r.ctx, r.keyPrefix+":"+key)
+	if err != nil || data == "" {
+		return nil, nil
+	}
+	return []byte(data), nil
+}
+
+// Set stores a value in Redis with the specified TTL.
+func (r *RedisStorage) Set(key string, value []byte, ttl time.Duration) error {
+	return r.client.Set(r.ctx, r.keyPrefix+":"+key, string(value), ttl)
+}
+
+// CompareAndSwap stores value only if the key still holds old, with a Lua
+// script. Clients that do not implement RedisEvaler fall back to Set.
+func (r *RedisStorage) CompareAndSwap(key string, old, value []byte, ttl time.Duration) (bool, error) {
+	ev, ok := r.client.(RedisEvaler)
+	if !ok {
+		return true, r.Set(key, value, ttl)
+	}
+	res, err := ev.Eval(r.ctx, casScript, []string{r.keyPrefix + ":" + key},
+		string(old), string(value), max(ttl.Milliseconds(), 1))
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.(int64)
+	return n == 1, nil
+}
+
+// Delete removes a value from Redis.
+func (r *RedisStorage) Delete(key string) error {
+	_, err := r.client.Del(r.ctx, r.keyPrefix+":"+key)
+	return err
+}
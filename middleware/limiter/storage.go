@@ -0,0 +1,131 @@
+package limiter
+
+import (
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// Storage holds the state of each client's limit.
+//
+// Values are opaque byte slices produced by the algorithms. Get returns
+// (nil, nil) for a missing or expired key. Implementations must be safe for
+// concurrent use; the middleware serializes updates of a key within a process.
+type Storage interface {
+	Get(key string) ([]byte, error)
+	Set(key string, value []byte, ttl time.Duration) error
+	Delete(key string) error
+}
+
+// CompareAndSwapper is implemented by storages shared between processes that
+// can update a key atomically. The limiter then retries its read-modify-write
+// when another process changed the key in between, so that limits are exact
+// across instances too.
+type CompareAndSwapper interface {
+	// CompareAndSwap stores value under key for ttl only if the current value
+	// is old, nil standing for a missing key, and reports whether it did.
+	CompareAndSwap(key string, old, value []byte, ttl time.Duration) (bool, error)
+}
+
+// memoryShards is the number of partitions of a MemoryStorage.
+const memoryShards = 256
+
+// memoryEntry is a value stored in a MemoryStorage.
+type memoryEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+// memoryShard is a partition of a MemoryStorage with its own lock.
+type memoryShard struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+// MemoryStorage is the default in-process Storage.
+//
+// It uses sharded maps to reduce contention, and a background goroutine
+// periodically removes expired entries.
+type MemoryStorage struct {
+	shards [memoryShards]*memoryShard
+}
+
+// NewMemoryStorage creates an in-memory storage.
+//
+// Returns:
+//   - *MemoryStorage: The storage, ready to use.
+func NewMemoryStorage() *MemoryStorage {
+	m := &MemoryStorage{}
+	for i := range m.shards {
+		m.shards[i] = &memoryShard{entries: make(map[string]memoryEntry)}
+	}
+
+	// Start the background cleanup to remove expired clients
+	go m.startCleanup()
+	return m
+}
+
+// Get returns the value of key, or nil if it is missing or expired.
+func (m *MemoryStorage) Get(key string) ([]byte, error) {
+	s := m.shard(key)
+	s.mu.Lock()
+	e, ok := s.entries[key]
+	s.mu.Unlock()
+	if !ok || time.Now().After(e.expires) {
+		return nil, nil
+	}
+	return e.value, nil
+}
+
+// Set stores value under key for ttl.
+func (m *MemoryStorage) Set(key string, value []byte, ttl time.Duration) error {
+	s := m.shard(key)
+	s.mu.Lock()
+	s.entries[key] = memoryEntry{value: value, expires: time.Now().Add(ttl)}
+	s.mu.Unlock()
+	return nil
+}
+
+// Delete removes key.
+func (m *MemoryStorage) Delete(key string) error {
+	s := m.shard(key)
+	s.mu.Lock()
+	delete(s.entries, key)
+	s.mu.Unlock()
+	return nil
+}
+
+// shard selects which partition holds the given key.
+func (m *MemoryStorage) shard(key string) *memoryShard {
+	return m.shards[hashKey(key)%memoryShards]
+}
+
+// startCleanup periodically removes expired entries.
+func (m *MemoryStorage) startCleanup() {
+	tick := time.NewTicker(30 * time.Second)
+	defer tick.Stop()
+	for range tick.C {
+		m.cleanup()
+	}
+}
+
+// cleanup checks every shard and deletes entries whose expiration time has passed.
+func (m *MemoryStorage) cleanup() {
+	now := time.Now()
+	for _, s := range m.shards {
+		s.mu.Lock()
+		for k, e := range s.entries {
+			if now.After(e.expires) {
+				delete(s.entries, k)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// hashKey hashes a key with FNV-1a to select a shard.
+func hashKey(key string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return h.Sum32()
+}
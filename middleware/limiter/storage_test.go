@@ -0,0 +1,116 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mockRedis is an in-memory RedisClient.
+type mockRedis struct {
+	mu   sync.Mutex
+	data map[string]string
+}
+
+func newMockRedis() *mockRedis {
+	return &mockRedis{data: make(map[string]string)}
+}
+
+func (m *mockRedis) Get(_ context.Context, key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.data[key]
+	if !ok {
+		return "", errors.New("redis: nil")
+	}
+	return v, nil
+}
+
+func (m *mockRedis) Set(_ context.Context, key, value string, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key] = value
+	return nil
+}
+
+func (m *mockRedis) Del(_ context.Context, keys ...string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, k := range keys {
+		delete(m.data, k)
+	}
+	return int64(len(keys)), nil
+}
+
+// evalRedis is a mockRedis that also runs the compare-and-swap script.
+type evalRedis struct {
+	*mockRedis
+	before func() // Runs once before the next script, as another process would
+}
+
+func (m *evalRedis) Eval(_ context.Context, script string, keys []string, args ...any) (any, error) {
+	if f := m.before; f != nil {
+		m.before = nil
+		f()
+	}
+	if script != casScript {
+		return nil, errors.New("unexpected script")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cur, ok := m.data[keys[0]]
+	if (!ok && args[0] == "") || (ok && cur == args[0]) {
+		m.data[keys[0]] = args[1].(string)
+		return int64(1), nil
+	}
+	return int64(0), nil
+}
+
+// TestStorage verifies Get, Set, Delete and expiration of the storages.
+//
+// go test -v -failfast -count=1 -run ^TestStorage$
+func TestStorage(t *testing.T) {
+	redis := newMockRedis()
+	rs, err := NewRedisStorage(RedisConfig{Client: redis})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, s := range map[string]Storage{"memory": NewMemoryStorage(), "redis": rs} {
+		t.Run(name, func(t *testing.T) {
+			if v, err := s.Get("k"); v != nil || err != nil {
+				t.Fatalf("expected a missing key, got %q, %v", v, err)
+			}
+			if err := s.Set("k", []byte{0, 1, 2}, time.Minute); err != nil {
+				t.Fatal(err)
+			}
+			if v, _ := s.Get("k"); string(v) != "\x00\x01\x02" {
+				t.Errorf("unexpected value %q", v)
+			}
+			if err := s.Delete("k"); err != nil {
+				t.Fatal(err)
+			}
+			if v, _ := s.Get("k"); v != nil {
+				t.Errorf("expected the key to be deleted, got %q", v)
+			}
+		})
+	}
+
+	if _, ok := redis.data["limiter:k"]; ok {
+		t.Error("expected the redis key to be prefixed and deleted")
+	}
+	if _, err := NewRedisStorage(RedisConfig{}); err == nil {
+		t.Error("expected an error without a client")
+	}
+
+	m := NewMemoryStorage()
+	_ = m.Set("old", []byte("x"), -time.Second)
+	if v, _ := m.Get("old"); v != nil {
+		t.Error("expected an expired key to be missing")
+	}
+	m.cleanup()
+	if len(m.shard("old").entries) != 0 {
+		t.Error("expected cleanup to remove expired keys")
+	}
+}
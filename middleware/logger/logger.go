@@ -11,6 +11,7 @@
 // - Customizable logging patterns with placeholders.
 // - Captures request latency, status, user agent, and more.
 // - Supports adding custom fields to logs.
+// - Logs the request ID of middleware/requestid as "request_id".
 package logger
 
 import (
@@ -30,6 +31,7 @@ import (
 	"time"
 
 	"github.com/jeffotoni/quick"
+	"github.com/jeffotoni/quick/middleware/requestid"
 )
 
 // Global storage for context data per request
@@ -355,6 +357,17 @@ func New(config ...Config) func(http.Handler) http.Handler {
 				}
 			}
 
+			// The request ID is usually in the context data; when a writer
+			// between this middleware and requestid hid the updated request,
+			// fall back to the response header
+			if _, ok := dynamicContextData[requestid.Field]; !ok {
+				if id := requestid.FromContext(ctx); id != "" {
+					dynamicContextData[requestid.Field] = id
+				} else if id := lrw.Header().Get(requestid.HeaderName); id != "" {
+					dynamicContextData[requestid.Field] = id
+				}
+			}
+
 			// Prepare response body (limit size for logging)
 			responseBody := string(lrw.body)
 			if len(responseBody) > 1000 {
@@ -13,6 +13,7 @@ import (
 	"testing"
 
 	"github.com/jeffotoni/quick"
+	"github.com/jeffotoni/quick/middleware/requestid"
 )
 
 // TestNew validates the Logger middleware by simulating various HTTP requests.
@@ -324,3 +325,35 @@ func TestLoggerMiddlewareDebug(t *testing.T) {
 		t.Errorf("Expected '[DEBUG]' log message, but got: %s", output)
 	}
 }
+
+// TestLoggerRequestID ensures the ID of the requestid middleware is logged,
+// whether the logger runs before or after it.
+func TestLoggerRequestID(t *testing.T) {
+	for _, loggerFirst := range []bool{true, false} {
+		q := quick.New()
+		if loggerFirst {
+			q.Use(New(Config{Format: "json"}))
+			q.Use(requestid.New())
+		} else {
+			q.Use(requestid.New())
+			q.Use(New(Config{Format: "json"}))
+		}
+		q.Get("/logger-id", func(c *quick.Ctx) error {
+			return c.Status(200).String(requestid.FromContext(c.Ctx()))
+		})
+
+		output := captureOutput(func() {
+			req := httptest.NewRequest(http.MethodGet, "/logger-id", nil)
+			req.Header.Set(requestid.HeaderName, "req-42")
+			q.ServeHTTP(httptest.NewRecorder(), req)
+		})
+
+		var jsonOutput map[string]interface{}
+		if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &jsonOutput); err != nil {
+			t.Fatalf("JSON output is not valid: %s\nOutput: %s", err, output)
+		}
+		if jsonOutput[requestid.Field] != "req-42" {
+			t.Errorf("loggerFirst=%v: expected request_id req-42, got %v", loggerFirst, jsonOutput[requestid.Field])
+		}
+	}
+}
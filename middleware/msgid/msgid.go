@@ -8,6 +8,7 @@
 // - Automatically generates a MsgID if the request does not already have one.
 // - Allows customization of the MsgID format using a user-defined algorithm.
 // - Adds the MsgID to both the request and response headers for tracking.
+// - Stores the MsgID in the request context, like middleware/requestid.
 // - Supports configuring the MsgID name, range, and generation strategy.
 package msgid
 
@@ -17,6 +18,8 @@ import (
 	"math/big"
 	"net/http"
 	"strconv"
+
+	"github.com/jeffotoni/quick/middleware/requestid"
 )
 
 // Default values for the generated message ID (MsgID) range
@@ -74,18 +77,17 @@ func New(config ...Config) func(http.Handler) http.Handler {
 						cfd.End = DefaultEndConfig
 					}
 					// Generate a new MsgID using the default algorithm
-					algo := AlgoDefault(cfd.Start, cfd.End)
-					// Set the generated MsgID in request and response headers
-					r.Header.Set(cfd.Name, algo)
-					w.Header().Set(cfd.Name, algo)
+					msgId = AlgoDefault(cfd.Start, cfd.End)
 				} else {
 					// If a custom algorithm is provided, use it to generate the MsgID
-					algo := cfd.Algo()
-					r.Header.Set(cfd.Name, algo)
-					w.Header().Set(cfd.Name, algo)
+					msgId = cfd.Algo()
 				}
 			}
 
+			// Set the MsgID in the request and response headers and in the
+			// request context, where the logger middleware and glog find it
+			r = requestid.Propagate(w, r, cfd.Name, msgId)
+
 			// Pass the request to the next handler in the chain
 			next.ServeHTTP(w, r)
 		})
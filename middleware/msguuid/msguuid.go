@@ -4,7 +4,8 @@
 // If a request already has a UUID in the specified header, it remains unchanged.
 // Otherwise, a new UUID is generated and assigned based on the configured version (1 to 4).
 //
-// The UUID can be retrieved from the request and response headers using the configured key.
+// The UUID can be retrieved from the request and response headers using the configured key,
+// and from the request context with requestid.FromContext, as middleware/requestid does.
 // This is useful for tracking requests across services, logging, and debugging.
 package msguuid
 
@@ -12,6 +13,7 @@ import (
 	"log"
 	"net/http"
 
+	"github.com/jeffotoni/quick/middleware/requestid"
 	"github.com/jeffotoni/quick/uuid"
 )
 
@@ -69,11 +71,11 @@ func New(config ...Config) func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			msgUuId := r.Header.Get(cfd.Name)
 			if len(msgUuId) == 0 {
-				uuid := generateDefaultUUID(cfd)
-				r.Header.Set(cfd.Name, uuid)
-				w.Header().Set(cfd.Name, uuid)
+				msgUuId = generateDefaultUUID(cfd)
 			}
-			next.ServeHTTP(w, r)
+			// Set the UUID in the request and response headers and in the
+			// request context, where the logger middleware and glog find it
+			next.ServeHTTP(w, requestid.Propagate(w, r, cfd.Name, msgUuId))
 		})
 	}
 }
@@ -0,0 +1,166 @@
+package proxy
+
+import (
+	"hash/fnv"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Balancer selects the upstream of each request.
+type Balancer int
+
+const (
+	// RoundRobin sends requests to each upstream in turn.
+	RoundRobin Balancer = iota
+	// LeastConnections sends requests to the upstream with the fewest
+	// requests in progress.
+	LeastConnections
+	// ConsistentHash sends requests with the same key, the client IP by
+	// default, to the same upstream, and moves few keys when upstreams
+	// come and go.
+	ConsistentHash
+)
+
+// String returns the name of the balancer.
+func (b Balancer) String() string {
+	switch b {
+	case RoundRobin:
+		return "round-robin"
+	case LeastConnections:
+		return "least-connections"
+	case ConsistentHash:
+		return "consistent-hash"
+	}
+	return "Balancer(" + strconv.Itoa(int(b)) + ")"
+}
+
+// virtualNodes is the number of points of each upstream on the hash ring.
+const virtualNodes = 160
+
+// upstream is a parsed Upstream with its passive health state.
+type upstream struct {
+	url     *url.URL
+	timeout time.Duration
+	active  atomic.Int64 // Requests in progress
+
+	mu        sync.Mutex
+	fails     int       // Consecutive failures
+	downUntil time.Time // Not selected before this time
+}
+
+// healthy reports whether the upstream may be selected at now.
+func (u *upstream) healthy(now time.Time) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return !now.Before(u.downUntil)
+}
+
+// report records the outcome of a request. After maxFails consecutive
+// failures the upstream is skipped for failTimeout.
+func (u *upstream) report(ok bool, maxFails int, failTimeout time.Duration) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if ok {
+		u.fails = 0
+		return
+	}
+	u.fails++
+	if u.fails >= maxFails {
+		u.fails = 0
+		u.downUntil = time.Now().Add(failTimeout)
+	}
+}
+
+// hashPoint is a point of the consistent hash ring.
+type hashPoint struct {
+	hash     uint32
+	upstream int
+}
+
+// balancer selects upstreams with one Balancer.
+type balancer struct {
+	algo      Balancer
+	upstreams []*upstream
+	next      atomic.Uint64
+	ring      []hashPoint // ConsistentHash only, sorted by hash
+}
+
+// newBalancer creates a balancer over upstreams.
+func newBalancer(algo Balancer, upstreams []*upstream) *balancer {
+	b := &balancer{algo: algo, upstreams: upstreams}
+	if algo == ConsistentHash {
+		for i, u := range upstreams {
+			for v := 0; v < virtualNodes; v++ {
+				b.ring = append(b.ring, hashPoint{hash: hash32(u.url.String() + "#" + strconv.Itoa(v)), upstream: i})
+			}
+		}
+		sort.Slice(b.ring, func(i, j int) bool { return b.ring[i].hash < b.ring[j].hash })
+	}
+	return b
+}
+
+// pick returns the upstream for a request with key, skipping the upstreams
+// in tried. Unhealthy upstreams are only used when no healthy one is left,
+// so a request is never refused because every upstream failed recently.
+func (b *balancer) pick(key string, tried []bool) *upstream {
+	now := time.Now()
+	if i := b.choose(key, func(i int) bool { return !tried[i] && b.upstreams[i].healthy(now) }); i >= 0 {
+		tried[i] = true
+		return b.upstreams[i]
+	}
+	if i := b.choose(key, func(i int) bool { return !tried[i] }); i >= 0 {
+		tried[i] = true
+		return b.upstreams[i]
+	}
+	return nil
+}
+
+// choose returns the index of the upstream selected among those accepted
+// by usable, or -1.
+func (b *balancer) choose(key string, usable func(i int) bool) int {
+	n := len(b.upstreams)
+	switch b.algo {
+	case LeastConnections:
+		best := -1
+		var least int64
+		start := int(b.next.Add(1) % uint64(n)) // Rotate between ties
+		for k := 0; k < n; k++ {
+			i := (start + k) % n
+			if !usable(i) {
+				continue
+			}
+			if active := b.upstreams[i].active.Load(); best < 0 || active < least {
+				best, least = i, active
+			}
+		}
+		return best
+	case ConsistentHash:
+		h := hash32(key)
+		start := sort.Search(len(b.ring), func(i int) bool { return b.ring[i].hash >= h })
+		for k := 0; k < len(b.ring); k++ {
+			if p := b.ring[(start+k)%len(b.ring)]; usable(p.upstream) {
+				return p.upstream
+			}
+		}
+		return -1
+	default:
+		start := int((b.next.Add(1) - 1) % uint64(n))
+		for k := 0; k < n; k++ {
+			if i := (start + k) % n; usable(i) {
+				return i
+			}
+		}
+		return -1
+	}
+}
+
+// hash32 returns the FNV-1a hash of s.
+func hash32(s string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return h.Sum32()
+}
@@ -0,0 +1,342 @@
+// Package proxy provides a reverse proxy and load balancer for Quick.
+//
+// Requests are forwarded to one or more upstreams chosen by round-robin,
+// least-connections or consistent hashing. Upstreams that keep failing are
+// skipped for a while (passive health checks), and failed requests are
+// retried on the next upstream with the retry and failover semantics of
+// http/client. The proxy is built on net/http/httputil.ReverseProxy, so
+// hop-by-hop headers, WebSocket upgrades and Server-Sent Events are handled
+// as the standard library does.
+//
+// Features:
+//   - RoundRobin, LeastConnections and ConsistentHash balancing.
+//   - Passive health tracking with MaxFails and FailTimeout.
+//   - X-Forwarded-For, X-Forwarded-Host, X-Forwarded-Proto and Forwarded (RFC 7239).
+//   - Path rewriting by prefix, request and response header rewriting.
+//   - WebSocket and SSE passthrough.
+//   - Per-upstream timeouts and retries configured with client.RetryConfig.
+package proxy
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jeffotoni/quick"
+	"github.com/jeffotoni/quick/http/client"
+)
+
+// Upstream is a server requests are forwarded to.
+type Upstream struct {
+	// URL is the address of the upstream, e.g. "http://10.0.0.1:8080".
+	// Its path, if any, is prepended to the path of the requests.
+	URL string
+
+	// Timeout is the time the upstream has to send the response headers.
+	// Default is Config.Timeout.
+	Timeout time.Duration
+}
+
+// Config defines the configuration options for the proxy.
+type Config struct {
+	// Upstreams are the servers requests are forwarded to. Required.
+	Upstreams []Upstream
+
+	// Balancer selects the upstream of each request.
+	// Default is RoundRobin.
+	Balancer Balancer
+
+	// HashKey returns the key of ConsistentHash.
+	// Default is the client IP.
+	HashKey func(c *quick.Ctx) string
+
+	// Timeout is the time upstreams have to send the response headers.
+	// Streams and WebSockets may last longer. A negative value disables it.
+	// Default is 30 seconds.
+	Timeout time.Duration
+
+	// Retry retries failed requests on the next upstream, as the client
+	// of http/client does: on errors and on Retry.Statuses, waiting
+	// Retry.Delay, doubled at each attempt with Retry.UseBackoff.
+	// FailoverURLs and EnableLog are not used; failover goes through the
+	// upstreams. Default is no retry.
+	Retry client.RetryConfig
+
+	// MaxFails is the number of consecutive failures (errors, timeouts,
+	// 502, 503 and 504) after which an upstream is skipped for FailTimeout.
+	// Default is 3.
+	MaxFails int
+
+	// FailTimeout is how long a failing upstream is skipped.
+	// Default is 10 seconds.
+	FailTimeout time.Duration
+
+	// Rewrite replaces path prefixes before forwarding, e.g.
+	// {"/api/v1": "/v1"} or {"/api": ""}. The longest matching prefix wins.
+	Rewrite map[string]string
+
+	// PreserveHost sends the Host header of the client instead of the
+	// host of the upstream.
+	// Default is false.
+	PreserveHost bool
+
+	// Forwarded adds the Forwarded header of RFC 7239, besides the
+	// X-Forwarded-* headers that are always set.
+	// Default is false.
+	Forwarded bool
+
+	// RequestHeaders are set on forwarded requests; an empty value removes
+	// the header.
+	RequestHeaders map[string]string
+
+	// ResponseHeaders are set on responses; an empty value removes the header.
+	ResponseHeaders map[string]string
+
+	// ModifyResponse is called with each upstream response, after
+	// ResponseHeaders. Returning an error answers 502.
+	ModifyResponse func(*http.Response) error
+
+	// FlushInterval is how often the response is flushed to the client.
+	// Event streams and responses of unknown length are always flushed
+	// at once. Default is 0, no periodic flush.
+	FlushInterval time.Duration
+
+	// Transport sends the requests to upstreams.
+	// Default is a clone of http.DefaultTransport.
+	Transport http.RoundTripper
+
+	// Next is a function that determines whether to skip the proxy and
+	// serve the request locally (New only).
+	Next func(c *quick.Ctx) bool
+
+	// OnError writes the response when no upstream could answer. Returned
+	// errors are rendered by the error handler of the app.
+	// Default is 502 Bad Gateway, or 504 Gateway Timeout on timeouts.
+	OnError func(c *quick.Ctx, err error) error
+}
+
+// ConfigDefault is the default configuration.
+var ConfigDefault = Config{
+	Balancer:    RoundRobin,
+	Timeout:     30 * time.Second,
+	MaxFails:    3,
+	FailTimeout: 10 * time.Second,
+}
+
+// New creates a middleware that forwards every request to the upstreams.
+//
+// Requests for which Next returns true are served by the application.
+//
+// Parameters:
+//   - config: The configuration; Upstreams is required.
+//
+// Returns:
+//   - func(http.Handler) http.Handler: The middleware, usable with q.Use and Group.Use.
+//
+// Example Usage:
+//
+//	q.Use(proxy.New(proxy.Config{
+//	    Upstreams: []proxy.Upstream{{URL: "http://10.0.0.1:8080"}, {URL: "http://10.0.0.2:8080"}},
+//	    Balancer:  proxy.LeastConnections,
+//	}))
+func New(config Config) func(http.Handler) http.Handler {
+	p := newProxy(config)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if p.cfg.Next != nil && p.cfg.Next(&quick.Ctx{Response: w, Request: r}) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			p.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Forward creates a handler that forwards the requests of a route.
+//
+// Example Usage:
+//
+//	q.Get("/api/*", proxy.Forward(proxy.Config{
+//	    Upstreams: []proxy.Upstream{{URL: "http://api.internal"}},
+//	    Rewrite:   map[string]string{"/api": ""},
+//	}))
+func Forward(config Config) quick.HandleFunc {
+	p := newProxy(config)
+	return func(c *quick.Ctx) error {
+		p.ServeHTTP(c.Response, c.Request)
+		return nil
+	}
+}
+
+// reverseProxy is the http.Handler shared by New and Forward.
+type reverseProxy struct {
+	*httputil.ReverseProxy
+	cfg      Config
+	prefixes []string // Keys of Rewrite, longest first
+}
+
+// newProxy applies the defaults of config and builds the proxy.
+func newProxy(config Config) *reverseProxy {
+	cfg := config
+	if len(cfg.Upstreams) == 0 {
+		panic("proxy: Config.Upstreams is required")
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = ConfigDefault.Timeout
+	}
+	if cfg.MaxFails <= 0 {
+		cfg.MaxFails = ConfigDefault.MaxFails
+	}
+	if cfg.FailTimeout <= 0 {
+		cfg.FailTimeout = ConfigDefault.FailTimeout
+	}
+	if cfg.Retry.MaxRetries < 0 {
+		cfg.Retry.MaxRetries = 0
+	}
+	if cfg.HashKey == nil {
+		cfg.HashKey = func(c *quick.Ctx) string {
+			return clientIP(c.Request)
+		}
+	}
+	if cfg.OnError == nil {
+		cfg.OnError = func(c *quick.Ctx, err error) error {
+			if errors.Is(err, errTimeout) {
+				return quick.NewError(quick.StatusGatewayTimeout)
+			}
+			return quick.NewError(quick.StatusBadGateway)
+		}
+	}
+	base := cfg.Transport
+	if base == nil {
+		base = http.DefaultTransport.(*http.Transport).Clone()
+	}
+
+	upstreams := make([]*upstream, len(cfg.Upstreams))
+	for i, u := range cfg.Upstreams {
+		target, err := url.Parse(u.URL)
+		if err != nil || target.Scheme == "" || target.Host == "" {
+			panic("proxy: invalid upstream URL " + u.URL)
+		}
+		upstreams[i] = &upstream{url: target, timeout: u.Timeout}
+	}
+
+	p := &reverseProxy{cfg: cfg}
+	for prefix := range cfg.Rewrite {
+		p.prefixes = append(p.prefixes, prefix)
+	}
+	sort.Slice(p.prefixes, func(i, j int) bool { return len(p.prefixes[i]) > len(p.prefixes[j]) })
+
+	p.ReverseProxy = &httputil.ReverseProxy{
+		Rewrite:       p.rewrite,
+		Transport:     &transport{cfg: &p.cfg, base: base, balancer: newBalancer(cfg.Balancer, upstreams)},
+		FlushInterval: cfg.FlushInterval,
+		ModifyResponse: func(resp *http.Response) error {
+			setHeaders(resp.Header, cfg.ResponseHeaders)
+			if cfg.ModifyResponse != nil {
+				return cfg.ModifyResponse(resp)
+			}
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			if r.Context().Err() != nil {
+				// The client went away: nobody is waiting for a response
+				return
+			}
+			c := &quick.Ctx{Response: w, Request: r}
+			if err := cfg.OnError(c, err); err != nil {
+				quick.HandleError(c, err)
+			}
+		},
+	}
+	return p
+}
+
+// ServeHTTP forwards r to an upstream.
+func (p *reverseProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if p.cfg.Balancer == ConsistentHash {
+		key := p.cfg.HashKey(&quick.Ctx{Response: w, Request: r})
+		r = r.WithContext(context.WithValue(r.Context(), keyContext{}, key))
+	}
+	p.ReverseProxy.ServeHTTP(w, r)
+}
+
+// rewrite prepares the outgoing request; the upstream is set by the transport.
+func (p *reverseProxy) rewrite(pr *httputil.ProxyRequest) {
+	// Append to the X-Forwarded-For received, as the Forwarded header does
+	if prior := pr.In.Header.Values("X-Forwarded-For"); len(prior) > 0 {
+		pr.Out.Header["X-Forwarded-For"] = prior
+	}
+	pr.SetXForwarded()
+	if p.cfg.Forwarded {
+		pr.Out.Header.Set("Forwarded", forwarded(pr.In))
+	}
+	if p.cfg.PreserveHost {
+		pr.Out.Host = pr.In.Host
+	} else {
+		pr.Out.Host = ""
+	}
+
+	for _, prefix := range p.prefixes {
+		if path, ok := strings.CutPrefix(pr.Out.URL.Path, prefix); ok && (path == "" || path[0] == '/' || strings.HasSuffix(prefix, "/")) {
+			pr.Out.URL.Path = p.cfg.Rewrite[prefix] + path
+			if pr.Out.URL.Path == "" {
+				pr.Out.URL.Path = "/"
+			}
+			pr.Out.URL.RawPath = ""
+			break
+		}
+	}
+	setHeaders(pr.Out.Header, p.cfg.RequestHeaders)
+}
+
+// forwarded returns the Forwarded header of r, appending this hop to the
+// one received (RFC 7239).
+func forwarded(r *http.Request) string {
+	ip := clientIP(r)
+	if strings.Contains(ip, ":") {
+		ip = `"[` + ip + `]"`
+	}
+	proto := "http"
+	if r.TLS != nil {
+		proto = "https"
+	}
+	hop := "for=" + ip + ";host=" + quoteForwarded(r.Host) + ";proto=" + proto
+	if prior := strings.Join(r.Header.Values("Forwarded"), ", "); prior != "" {
+		return prior + ", " + hop
+	}
+	return hop
+}
+
+// quoteForwarded quotes v when it is not a valid token, e.g. host:port.
+func quoteForwarded(v string) string {
+	if strings.ContainsAny(v, `:[]"`) {
+		return `"` + strings.ReplaceAll(v, `"`, `\"`) + `"`
+	}
+	return v
+}
+
+// clientIP returns the IP address of the client of r.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// setHeaders sets headers on h; an empty value removes the header.
+func setHeaders(h http.Header, headers map[string]string) {
+	for k, v := range headers {
+		if v == "" {
+			h.Del(k)
+		} else {
+			h.Set(k, v)
+		}
+	}
+}
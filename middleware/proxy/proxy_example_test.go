@@ -0,0 +1,37 @@
+package proxy
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/jeffotoni/quick"
+)
+
+// This function is named ExampleForward()
+// it with the Examples type.
+func ExampleForward() {
+	// The upstream service
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "upstream got %s", r.URL.Path)
+	}))
+	defer api.Close()
+
+	q := quick.New()
+
+	// Forward /api/* to the upstream without the /api prefix
+	q.Get("/api/*", Forward(Config{
+		Upstreams: []Upstream{{URL: api.URL}},
+		Rewrite:   map[string]string{"/api": ""},
+	}))
+
+	res, err := q.Qtest(quick.QuickTestOptions{Method: quick.MethodGet, URI: "/api/v1/user"})
+	if err != nil {
+		log.Fatalf("Error running test request: %v", err)
+	}
+	fmt.Println(res.StatusCode(), res.BodyStr())
+
+	// Output:
+	// 200 upstream got /v1/user
+}
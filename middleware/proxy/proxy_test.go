@@ -0,0 +1,338 @@
+package proxy
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jeffotoni/quick"
+	"github.com/jeffotoni/quick/http/client"
+)
+
+// backend starts an upstream that answers with its name and the request it received.
+func backend(t *testing.T, name string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Backend", name)
+		w.Header().Set("X-Internal", "secret")
+		fmt.Fprintf(w, "%s %s %s", name, r.URL.RequestURI(), r.Host)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// get sends a GET request through h.
+func get(h http.Handler, path string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(quick.MethodGet, path, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// TestNew verifies round-robin balancing, path rewriting and header rewriting.
+//
+// go test -v -failfast -count=1 -run ^TestNew$
+func TestNew(t *testing.T) {
+	a, b := backend(t, "a"), backend(t, "b")
+
+	var seen http.Header
+	q := quick.New()
+	q.Use(New(Config{
+		Upstreams:       []Upstream{{URL: a.URL + "/base"}, {URL: b.URL + "/base"}},
+		Rewrite:         map[string]string{"/api": "", "/api/v1": "/v2"},
+		Forwarded:       true,
+		RequestHeaders:  map[string]string{"X-Gateway": "quick", "Cookie": ""},
+		ResponseHeaders: map[string]string{"X-Internal": ""},
+		ModifyResponse: func(resp *http.Response) error {
+			seen = resp.Request.Header
+			return nil
+		},
+		Next: func(c *quick.Ctx) bool { return c.Path() == "/local" },
+	}))
+	q.Get("/local", func(c *quick.Ctx) error {
+		return c.Status(quick.StatusOK).String("local")
+	})
+	// Middlewares run on registered routes: match every path
+	q.Get("/*", func(c *quick.Ctx) error { return nil })
+
+	if rec := get(q, "/local"); rec.Body.String() != "local" {
+		t.Errorf("Next should serve /local locally, got %q", rec.Body.String())
+	}
+
+	counts := map[string]int{}
+	for i := 0; i < 4; i++ {
+		rec := get(q, "/api/users?id=1", "Cookie", "session=1", "X-Forwarded-For", "10.0.0.9")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("unexpected status %d %q", rec.Code, rec.Body.String())
+		}
+		counts[rec.Header().Get("X-Backend")]++
+		if !strings.Contains(rec.Body.String(), " /base/users?id=1 127.0.0.1:") {
+			t.Errorf("unexpected upstream request %q", rec.Body.String())
+		}
+		if rec.Header().Get("X-Internal") != "" {
+			t.Error("X-Internal should be removed from the response")
+		}
+	}
+	if counts["a"] != 2 || counts["b"] != 2 {
+		t.Errorf("expected round-robin, got %v", counts)
+	}
+
+	if got := seen.Get("X-Forwarded-For"); got != "10.0.0.9, 192.0.2.1" {
+		t.Errorf("unexpected X-Forwarded-For %q", got)
+	}
+	if seen.Get("X-Forwarded-Host") != "example.com" || seen.Get("X-Forwarded-Proto") != "http" {
+		t.Errorf("unexpected X-Forwarded headers %v", seen)
+	}
+	if got := seen.Get("Forwarded"); got != "for=192.0.2.1;host=example.com;proto=http" {
+		t.Errorf("unexpected Forwarded %q", got)
+	}
+	if seen.Get("X-Gateway") != "quick" || seen.Get("Cookie") != "" {
+		t.Errorf("request headers were not rewritten: %v", seen)
+	}
+
+	if rec := get(q, "/api/v1/users"); !strings.Contains(rec.Body.String(), " /base/v2/users ") {
+		t.Errorf("the longest prefix should win, got %q", rec.Body.String())
+	}
+	if rec := get(q, "/apiary"); !strings.Contains(rec.Body.String(), " /base/apiary ") {
+		t.Errorf("prefixes should match whole segments, got %q", rec.Body.String())
+	}
+}
+
+// TestForward verifies the route handler and PreserveHost.
+//
+// go test -v -failfast -count=1 -run ^TestForward$
+func TestForward(t *testing.T) {
+	a := backend(t, "a")
+	q := quick.New()
+	q.Post("/svc/*", Forward(Config{
+		Upstreams:    []Upstream{{URL: a.URL}},
+		Rewrite:      map[string]string{"/svc": ""},
+		PreserveHost: true,
+	}))
+
+	req := httptest.NewRequest(quick.MethodPost, "/svc/orders", strings.NewReader(`{"id":1}`))
+	rec := httptest.NewRecorder()
+	q.ServeHTTP(rec, req)
+	if rec.Body.String() != "a /orders example.com" {
+		t.Errorf("unexpected response %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+// TestBalancers verifies least-connections and consistent hashing.
+//
+// go test -v -failfast -count=1 -run ^TestBalancers$
+func TestBalancers(t *testing.T) {
+	release := make(chan struct{})
+	entered := make(chan struct{}, 1)
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Backend", "slow")
+		entered <- struct{}{}
+		<-release
+	}))
+	defer slow.Close()
+	fast := backend(t, "fast")
+
+	h := New(Config{Upstreams: []Upstream{{URL: slow.URL}, {URL: fast.URL}}, Balancer: LeastConnections})(nil)
+	var once sync.Once
+	defer once.Do(func() { close(release) })
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// Send requests until one keeps the slow upstream busy
+		for get(h, "/").Header().Get("X-Backend") != "slow" {
+		}
+	}()
+	<-entered
+	for i := 0; i < 5; i++ {
+		if rec := get(h, "/"); rec.Header().Get("X-Backend") != "fast" {
+			t.Fatalf("expected the idle upstream, got %q %d", rec.Header().Get("X-Backend"), rec.Code)
+		}
+	}
+	once.Do(func() { close(release) })
+	wg.Wait()
+
+	upstreams := []Upstream{{URL: backend(t, "a").URL}, {URL: backend(t, "b").URL}, {URL: backend(t, "c").URL}}
+	h = New(Config{
+		Upstreams: upstreams,
+		Balancer:  ConsistentHash,
+		HashKey:   func(c *quick.Ctx) string { return c.Request.Header.Get("X-User") },
+	})(nil)
+	spread := map[string]bool{}
+	for user := 0; user < 300; user++ {
+		id := fmt.Sprint(user)
+		first := get(h, "/", "X-User", id).Header().Get("X-Backend")
+		if got := get(h, "/", "X-User", id).Header().Get("X-Backend"); got != first {
+			t.Fatalf("user %s moved from %s to %s", id, first, got)
+		}
+		spread[first] = true
+	}
+	if len(spread) != 3 {
+		t.Errorf("expected the keys to spread over all upstreams, got %v", spread)
+	}
+}
+
+// TestFailover verifies retries, passive health tracking and timeouts.
+//
+// go test -v -failfast -count=1 -run ^TestFailover$
+func TestFailover(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close() // Connections are refused
+
+	var mu sync.Mutex
+	hits := 0
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits++
+		mu.Unlock()
+		b, _ := io.ReadAll(r.Body)
+		fmt.Fprintf(w, "up %s", b)
+	}))
+	defer up.Close()
+
+	h := New(Config{
+		Upstreams: []Upstream{{URL: down.URL}, {URL: up.URL}},
+		Retry:     client.RetryConfig{MaxRetries: 2, Delay: time.Millisecond},
+		MaxFails:  1,
+	})(nil)
+	for i := 0; i < 4; i++ {
+		req := httptest.NewRequest(quick.MethodPost, "/", strings.NewReader("body"))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK || rec.Body.String() != "up body" {
+			t.Fatalf("request %d: expected the retry to reach the healthy upstream, got %d %q", i, rec.Code, rec.Body.String())
+		}
+	}
+
+	// Without retries, requests fail until the upstream is marked down
+	h = New(Config{Upstreams: []Upstream{{URL: down.URL}, {URL: up.URL}}, MaxFails: 1, FailTimeout: time.Minute})(nil)
+	codes := []int{}
+	for i := 0; i < 4; i++ {
+		codes = append(codes, get(h, "/").Code)
+	}
+	if fmt.Sprint(codes) != "[502 200 200 200]" {
+		t.Errorf("expected the failed upstream to be skipped, got %v", codes)
+	}
+
+	// A slow upstream times out with 504, or fails over with retries
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer slow.Close()
+	h = New(Config{Upstreams: []Upstream{{URL: slow.URL, Timeout: 50 * time.Millisecond}}})(nil)
+	if rec := get(h, "/"); rec.Code != http.StatusGatewayTimeout {
+		t.Errorf("expected 504, got %d", rec.Code)
+	}
+	h = New(Config{
+		Upstreams: []Upstream{{URL: slow.URL, Timeout: 50 * time.Millisecond}, {URL: up.URL}},
+		Retry:     client.RetryConfig{MaxRetries: 1},
+	})(nil)
+	for i := 0; i < 2; i++ {
+		if rec := get(h, "/"); rec.Code != http.StatusOK {
+			t.Errorf("expected the retry to succeed, got %d", rec.Code)
+		}
+	}
+
+	// Retry.Statuses retries responses too
+	busy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer busy.Close()
+	h = New(Config{
+		Upstreams: []Upstream{{URL: busy.URL}, {URL: up.URL}},
+		Retry:     client.RetryConfig{MaxRetries: 1, Statuses: []int{http.StatusTooManyRequests}},
+	})(nil)
+	for i := 0; i < 2; i++ {
+		if rec := get(h, "/"); rec.Code != http.StatusOK {
+			t.Errorf("expected 429 to be retried, got %d", rec.Code)
+		}
+	}
+}
+
+// TestStreaming verifies that WebSocket upgrades and Server-Sent Events pass
+// through Quick and the proxy.
+//
+// go test -v -failfast -count=1 -run ^TestStreaming$
+func TestStreaming(t *testing.T) {
+	next := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") == "echo" {
+			conn, brw, err := http.NewResponseController(w).Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer conn.Close()
+			brw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: echo\r\nConnection: Upgrade\r\n\r\n")
+			brw.Flush()
+			io.Copy(conn, brw)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: one\n\n")
+		w.(http.Flusher).Flush()
+		<-next
+		io.WriteString(w, "data: two\n\n")
+	}))
+	defer upstream.Close()
+
+	q := quick.New()
+	q.Use(New(Config{Upstreams: []Upstream{{URL: upstream.URL}}, Timeout: 100 * time.Millisecond}))
+	q.Get("/*", func(c *quick.Ctx) error { return nil })
+	srv := httptest.NewServer(q)
+	defer srv.Close()
+
+	// WebSocket-style upgrade, longer than the timeout
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	io.WriteString(conn, "GET /ws HTTP/1.1\r\nHost: test\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil || resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected 101, got %v %v", resp, err)
+	}
+	time.Sleep(150 * time.Millisecond)
+	io.WriteString(conn, "ping")
+	buf := make([]byte, 4)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := io.ReadFull(br, buf); err != nil || string(buf) != "ping" {
+		t.Fatalf("expected the echo, got %q %v", buf, err)
+	}
+
+	// Server-Sent Events
+	res, err := http.Get(srv.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	line := make(chan string, 1)
+	go func() {
+		s, _ := bufio.NewReader(res.Body).ReadString('\n')
+		line <- s
+	}()
+	select {
+	case s := <-line:
+		if s != "data: one\n" {
+			t.Errorf("unexpected event %q", s)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the first event was not flushed")
+	}
+	close(next)
+}
@@ -0,0 +1,198 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errTimeout is returned when an upstream does not answer within its timeout.
+var errTimeout = errors.New("proxy: upstream timeout")
+
+// transport sends each request to an upstream chosen by the balancer,
+// retrying on other upstreams like the RetryTransport of http/client.
+type transport struct {
+	cfg      *Config
+	base     http.RoundTripper
+	balancer *balancer
+}
+
+// keyContext carries the consistent hash key from the handler to RoundTrip.
+type keyContext struct{}
+
+// RoundTrip implements http.RoundTripper.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	retry := t.cfg.Retry
+	upgrade := req.Header.Get("Upgrade") != ""
+	if upgrade {
+		// The connection of an upgrade cannot be replayed
+		retry.MaxRetries = 0
+	}
+	if retry.MaxRetries > 0 && req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("proxy: reading request body: %w", err)
+		}
+		_ = req.Body.Close()
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+		req.Body, _ = req.GetBody()
+	}
+
+	key, _ := req.Context().Value(keyContext{}).(string)
+	tried := make([]bool, len(t.balancer.upstreams))
+	var resp *http.Response
+	var err error
+	for attempt := 0; attempt <= retry.MaxRetries; attempt++ {
+		up := t.balancer.pick(key, tried)
+		if up == nil {
+			// Every upstream was tried: start over
+			clear(tried)
+			up = t.balancer.pick(key, tried)
+		}
+		if attempt > 0 && req.GetBody != nil {
+			req.Body, _ = req.GetBody()
+		}
+
+		resp, err = t.send(req, up, upgrade)
+		if req.Context().Err() != nil {
+			// The client went away: not a failure of the upstream
+			return resp, err
+		}
+		failed := err != nil || resp.StatusCode == http.StatusBadGateway ||
+			resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == http.StatusGatewayTimeout
+		up.report(!failed, t.cfg.MaxFails, t.cfg.FailTimeout)
+
+		if attempt == retry.MaxRetries || !shouldRetry(retry.Statuses, resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+		delay := retry.Delay
+		if retry.UseBackoff {
+			delay = time.Duration(math.Pow(2, float64(attempt))) * retry.Delay
+		}
+		select {
+		case <-time.After(delay):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+	return resp, err
+}
+
+// send forwards req to up.
+func (t *transport) send(req *http.Request, up *upstream, upgrade bool) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	out.Body = req.Body
+	out.URL.Scheme = up.url.Scheme
+	out.URL.Host = up.url.Host
+	escaped := joinPath(up.url.EscapedPath(), req.URL.EscapedPath())
+	out.URL.Path, _ = url.PathUnescape(escaped)
+	out.URL.RawPath = escaped
+	switch {
+	case up.url.RawQuery == "":
+	case out.URL.RawQuery == "":
+		out.URL.RawQuery = up.url.RawQuery
+	default:
+		out.URL.RawQuery = up.url.RawQuery + "&" + out.URL.RawQuery
+	}
+
+	up.active.Add(1)
+	done := sync.OnceFunc(func() { up.active.Add(-1) })
+
+	timeout := up.timeout
+	if timeout <= 0 {
+		timeout = t.cfg.Timeout
+	}
+	var timer *time.Timer
+	if timeout > 0 {
+		// The timeout covers the response headers only, so streams and
+		// upgraded connections may last longer
+		ctx, cancel := context.WithCancel(out.Context())
+		timer = time.AfterFunc(timeout, cancel)
+		out = out.WithContext(ctx)
+	}
+
+	resp, err := t.base.RoundTrip(out)
+	if timer != nil && !timer.Stop() {
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		resp, err = nil, errTimeout
+	}
+	if err != nil {
+		done()
+		return nil, err
+	}
+
+	// Track the request until its body is closed, for LeastConnections
+	if rwc, ok := resp.Body.(io.ReadWriteCloser); ok && upgrade {
+		resp.Body = &trackedConn{ReadWriteCloser: rwc, done: done}
+	} else {
+		resp.Body = &trackedBody{ReadCloser: resp.Body, done: done}
+	}
+	return resp, nil
+}
+
+// shouldRetry reports whether a request is retried, as http/client does:
+// on errors and on the statuses of RetryConfig.Statuses.
+func shouldRetry(statuses []int, resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return slices.Contains(statuses, resp.StatusCode)
+}
+
+// joinPath joins the path of an upstream and the path of a request with
+// exactly one slash between them.
+func joinPath(base, path string) string {
+	switch {
+	case base == "" || base == "/":
+		return path
+	case path == "" || path == "/":
+		if strings.HasSuffix(base, "/") || path == "" {
+			return base
+		}
+		return base + "/"
+	case strings.HasSuffix(base, "/") && strings.HasPrefix(path, "/"):
+		return base + path[1:]
+	case !strings.HasSuffix(base, "/") && !strings.HasPrefix(path, "/"):
+		return base + "/" + path
+	}
+	return base + path
+}
+
+// trackedBody calls done when the response body is closed.
+type trackedBody struct {
+	io.ReadCloser
+	done func()
+}
+
+func (b *trackedBody) Close() error {
+	b.done()
+	return b.ReadCloser.Close()
+}
+
+// trackedConn calls done when an upgraded connection is closed. It keeps
+// io.ReadWriteCloser, which httputil.ReverseProxy needs to switch protocols.
+type trackedConn struct {
+	io.ReadWriteCloser
+	done func()
+}
+
+func (c *trackedConn) Close() error {
+	c.done()
+	return c.ReadWriteCloser.Close()
+}
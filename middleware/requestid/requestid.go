@@ -0,0 +1,159 @@
+// Package requestid provides a middleware that gives every request an ID.
+//
+// The ID is taken from the request header when the client, or a proxy in
+// front of Quick, already sent one; otherwise a UUIDv7 is generated, which
+// sorts by creation time. The ID is then propagated everywhere it is needed:
+//
+//   - The request header, so handlers and the proxy middleware forward it.
+//   - The response header, so clients can report it.
+//   - The request context, read with FromContext(c.Ctx()).
+//   - The fields of middleware/logger and of glog's Entry.Ctx, as "request_id".
+//
+// The msgid and msguuid middlewares propagate their IDs the same way.
+//
+// Features:
+//   - Accepts incoming IDs and rejects malformed ones.
+//   - UUIDv7 by default, or any Generator.
+//   - Picked up by middleware/logger and glog without extra code.
+package requestid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/jeffotoni/quick"
+	"github.com/jeffotoni/quick/glog"
+	"github.com/jeffotoni/quick/uuid"
+)
+
+const (
+	// HeaderName is the default header of the request ID.
+	HeaderName = "X-Request-ID"
+
+	// Field is the name of the request ID in the context fields read by
+	// middleware/logger and glog.
+	Field = "request_id"
+)
+
+// Config defines the configuration options for the requestid middleware.
+type Config struct {
+	// Header is the request and response header holding the ID.
+	// Default is "X-Request-ID".
+	Header string
+
+	// Generator creates the ID of requests without a valid one.
+	// Default is a UUIDv7.
+	Generator func() string
+
+	// MaxLength is the maximum length of an incoming ID. Longer IDs, and IDs
+	// with characters other than visible ASCII, are replaced so they cannot
+	// forge log lines. Default is 128.
+	MaxLength int
+
+	// Next is a function that determines whether to skip the middleware.
+	Next func(c *quick.Ctx) bool
+}
+
+// ConfigDefault is the default configuration.
+var ConfigDefault = Config{
+	Header:    HeaderName,
+	MaxLength: 128,
+}
+
+// contextKey is the key of the request ID in the request context.
+type contextKey struct{}
+
+// New creates the requestid middleware.
+//
+// Parameters:
+//   - config: Optional configuration; ConfigDefault is used when omitted.
+//
+// Returns:
+//   - func(http.Handler) http.Handler: The middleware, usable with q.Use and Group.Use.
+//
+// Example Usage:
+//
+//	q.Use(requestid.New())
+//	q.Get("/", func(c *quick.Ctx) error {
+//	    return c.String(requestid.FromContext(c.Ctx()))
+//	})
+func New(config ...Config) func(http.Handler) http.Handler {
+	cfg := ConfigDefault
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Header == "" {
+		cfg.Header = ConfigDefault.Header
+	}
+	if cfg.MaxLength <= 0 {
+		cfg.MaxLength = ConfigDefault.MaxLength
+	}
+	if cfg.Generator == nil {
+		cfg.Generator = func() string {
+			return uuid.Must(uuid.NewV7()).String()
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if cfg.Next != nil && cfg.Next(&quick.Ctx{Response: w, Request: r}) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			id := r.Header.Get(cfg.Header)
+			if !valid(id, cfg.MaxLength) {
+				id = cfg.Generator()
+			}
+			next.ServeHTTP(w, Propagate(w, r, cfg.Header, id))
+		})
+	}
+}
+
+// Propagate sets id in the header of the request and of the response, and
+// returns a copy of r whose context carries it for FromContext,
+// middleware/logger and glog. It is shared by the ID middlewares and can be
+// used by custom ones.
+//
+// Example Usage:
+//
+//	next.ServeHTTP(w, requestid.Propagate(w, r, "X-Correlation-ID", id))
+func Propagate(w http.ResponseWriter, r *http.Request, header, id string) *http.Request {
+	r.Header.Set(header, id)
+	w.Header().Set(header, id)
+
+	ctx := context.WithValue(r.Context(), contextKey{}, id)
+	ctx = glog.SetCtx(ctx, Field, id)
+
+	// The context data of Quick is logged by middleware/logger, which is also
+	// told about the new request when it wraps this middleware
+	c := &quick.Ctx{Response: w, Request: r.WithContext(ctx)}
+	c.SetContext().Str(Field, id)
+	return c.Request
+}
+
+// FromContext returns the request ID stored in ctx, or "" when there is none.
+//
+// Example Usage:
+//
+//	id := requestid.FromContext(c.Ctx())
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// valid reports whether an incoming id may be used as is.
+func valid(id string, maxLength int) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
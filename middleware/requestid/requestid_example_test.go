@@ -0,0 +1,34 @@
+package requestid
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/jeffotoni/quick"
+)
+
+// This function is named ExampleNew()
+// it with the Examples type.
+func ExampleNew() {
+	q := quick.New()
+
+	// Accept the X-Request-ID of the client or generate a UUIDv7
+	q.Use(New())
+
+	q.Get("/v1/user", func(c *quick.Ctx) error {
+		return c.Status(quick.StatusOK).String("request " + FromContext(c.Ctx()))
+	})
+
+	res, err := q.Qtest(quick.QuickTestOptions{
+		Method:  quick.MethodGet,
+		URI:     "/v1/user",
+		Headers: map[string]string{HeaderName: "req-42"},
+	})
+	if err != nil {
+		log.Fatalf("Error running test request: %v", err)
+	}
+	fmt.Println(res.StatusCode(), res.BodyStr(), res.Response().Header.Get(HeaderName))
+
+	// Output:
+	// 200 request req-42 req-42
+}
@@ -0,0 +1,122 @@
+package requestid
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jeffotoni/quick"
+	"github.com/jeffotoni/quick/glog"
+	"github.com/jeffotoni/quick/uuid"
+)
+
+// go test -v -failfast -count=1 -run ^TestNew$
+func TestNew(t *testing.T) {
+	q := quick.New()
+	q.Use(New())
+	q.Get("/", func(c *quick.Ctx) error {
+		return c.Status(quick.StatusOK).String(FromContext(c.Ctx()) + " " + c.Get(HeaderName))
+	})
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{name: "generated", incoming: "", keep: false},
+		{name: "incoming", incoming: "req-42", keep: true},
+		{name: "too long", incoming: strings.Repeat("a", 129), keep: false},
+		{name: "log injection", incoming: "req\nlevel=ERROR", keep: false},
+		{name: "space", incoming: "req 42", keep: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.incoming != "" {
+				req.Header.Set(HeaderName, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			q.ServeHTTP(rec, req)
+
+			id := rec.Header().Get(HeaderName)
+			if tt.keep && id != tt.incoming {
+				t.Fatalf("expected the incoming ID %q, got %q", tt.incoming, id)
+			}
+			if !tt.keep {
+				u, err := uuid.Parse(id)
+				if err != nil || u.Version() != 7 {
+					t.Fatalf("expected a UUIDv7, got %q", id)
+				}
+			}
+			if body := rec.Body.String(); body != id+" "+id {
+				t.Errorf("expected the ID in the context and request header, got %q", body)
+			}
+		})
+	}
+}
+
+// go test -v -failfast -count=1 -run ^TestNewConfig$
+func TestNewConfig(t *testing.T) {
+	q := quick.New()
+	q.Use(New(Config{
+		Header:    "X-Correlation-ID",
+		Generator: func() string { return "generated" },
+		MaxLength: 4,
+		Next: func(c *quick.Ctx) bool {
+			return c.Path() == "/health"
+		},
+	}))
+	handler := func(c *quick.Ctx) error {
+		return c.Status(quick.StatusOK).String(FromContext(c.Ctx()))
+	}
+	q.Get("/", handler)
+	q.Get("/health", handler)
+
+	for _, tt := range []struct{ path, incoming, want string }{
+		{"/", "", "generated"},
+		{"/", "abcd", "abcd"},
+		{"/", "abcde", "generated"},
+		{"/health", "", ""},
+	} {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.incoming != "" {
+			req.Header.Set("X-Correlation-ID", tt.incoming)
+		}
+		rec := httptest.NewRecorder()
+		q.ServeHTTP(rec, req)
+		if rec.Body.String() != tt.want || rec.Header().Get("X-Correlation-ID") != tt.want {
+			t.Errorf("%s %q: expected %q, got body %q and header %q",
+				tt.path, tt.incoming, tt.want, rec.Body.String(), rec.Header().Get("X-Correlation-ID"))
+		}
+	}
+}
+
+// go test -v -failfast -count=1 -run ^TestPropagate$
+func TestPropagate(t *testing.T) {
+	var buf bytes.Buffer
+	logger := glog.Set(glog.Config{Format: "json", Writer: &buf})
+
+	q := quick.New()
+	q.Use(New())
+	q.Get("/", func(c *quick.Ctx) error {
+		logger.Info().Ctx(c.Ctx()).Msg("handled").Send()
+		return c.Status(quick.StatusOK).JSON(c.GetAllContextData())
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(HeaderName, "req-42")
+	rec := httptest.NewRecorder()
+	q.ServeHTTP(rec, req)
+
+	if !strings.Contains(buf.String(), `"request_id":"req-42"`) {
+		t.Errorf("expected glog to log the request ID, got %s", buf.String())
+	}
+	if !strings.Contains(rec.Body.String(), `"request_id":"req-42"`) {
+		t.Errorf("expected the request ID in the context data, got %s", rec.Body.String())
+	}
+	if FromContext(nil) != "" || FromContext(req.Context()) != "" {
+		t.Errorf("expected no request ID outside the middleware")
+	}
+}
@@ -0,0 +1,381 @@
+// Package session provides session management middleware for the Quick web framework.
+//
+// Each client receives a cookie holding a session ID generated with the
+// project's uuid package (or, with the cookie store, the signed session itself).
+// Handlers access the session with session.Get(c):
+//
+//	q := quick.New()
+//	q.Use(session.New())
+//
+//	q.Post("/login", func(c *quick.Ctx) error {
+//	    s := session.Get(c)
+//	    if err := s.Regenerate(); err != nil { // prevent session fixation
+//	        return err
+//	    }
+//	    s.Set("user", "jeff")
+//	    return c.String("welcome")
+//	})
+//
+// Features:
+//   - Get, Set, Delete, Regenerate and Destroy on the session.
+//   - Idle timeout (since the last request) and absolute timeout (since creation).
+//   - Pluggable Store: in-memory (default), signed cookie and file stores.
+//   - The session is saved and its cookie written right before the response
+//     headers are sent, only when the session was used by the handler.
+package session
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jeffotoni/quick"
+	"github.com/jeffotoni/quick/uuid"
+)
+
+// Default values applied by New when a Config field is empty.
+const (
+	DefaultCookieName      = "quick_session"
+	DefaultIdleTimeout     = 30 * time.Minute
+	DefaultAbsoluteTimeout = 24 * time.Hour
+)
+
+// Config defines the configuration for the session middleware.
+//
+// Fields:
+//   - Store: Where sessions are persisted. Defaults to NewMemoryStore().
+//   - CookieName: The session cookie name. Defaults to "quick_session".
+//   - CookiePath: The cookie path. Defaults to "/".
+//   - CookieDomain: The cookie domain. Empty means the request host.
+//   - CookieSecure: Sends the cookie only over HTTPS.
+//   - CookieSameSite: The SameSite attribute. Defaults to http.SameSiteLaxMode.
+//   - DisableHTTPOnly: Makes the cookie readable by JavaScript (not recommended).
+//   - IdleTimeout: Expires sessions not used for this long. Defaults to 30 minutes.
+//   - AbsoluteTimeout: Expires sessions this long after creation. Defaults to 24 hours.
+//   - KeyGenerator: Generates session IDs. Defaults to uuid.NewString (UUID v4).
+//     New panics if the Store rejects its IDs (FileStore only accepts UUIDs).
+type Config struct {
+	Store           Store
+	CookieName      string
+	CookiePath      string
+	CookieDomain    string
+	CookieSecure    bool
+	CookieSameSite  http.SameSite
+	DisableHTTPOnly bool
+	IdleTimeout     time.Duration
+	AbsoluteTimeout time.Duration
+	KeyGenerator    func() string
+}
+
+// contextKey is the request context key holding the *Session.
+type contextKey struct{}
+
+// Session is the session of the current request.
+//
+// It is safe for concurrent use by the goroutines of a request.
+type Session struct {
+	mu        sync.Mutex
+	cfg       *Config
+	record    *Record
+	token     string // token received in the request cookie
+	fresh     bool   // created in this request
+	used      bool   // accessed by the handler
+	modified  bool   // values changed
+	destroyed bool
+}
+
+// New creates the session middleware.
+//
+// Parameters:
+//   - config ...Config (optional): Custom configuration; empty fields use the defaults.
+//
+// Returns:
+//   - func(http.Handler) http.Handler: The middleware.
+//
+// Example Usage:
+//
+//	store, _ := session.NewFileStore("/var/lib/myapp/sessions")
+//	q.Use(session.New(session.Config{
+//	    Store:        store,
+//	    CookieSecure: true,
+//	    IdleTimeout:  15 * time.Minute,
+//	}))
+func New(config ...Config) func(http.Handler) http.Handler {
+	cfg := Config{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryStore()
+	}
+	if cfg.CookieName == "" {
+		cfg.CookieName = DefaultCookieName
+	}
+	if cfg.CookiePath == "" {
+		cfg.CookiePath = "/"
+	}
+	if cfg.CookieSameSite == 0 {
+		cfg.CookieSameSite = http.SameSiteLaxMode
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = DefaultIdleTimeout
+	}
+	if cfg.AbsoluteTimeout <= 0 {
+		cfg.AbsoluteTimeout = DefaultAbsoluteTimeout
+	}
+	if cfg.KeyGenerator == nil {
+		cfg.KeyGenerator = uuid.NewString
+	}
+	if v, ok := cfg.Store.(interface{ ValidID(string) bool }); ok && !v.ValidID(cfg.KeyGenerator()) {
+		// Every session would fail to save, so fail at startup instead
+		panic("session: Config.KeyGenerator returns IDs the Store does not accept")
+	}
+	if cs, ok := cfg.Store.(*CookieStore); ok {
+		cs.setMaxAge(cfg.AbsoluteTimeout)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			s := load(&cfg, r)
+			sw := &sessionWriter{ResponseWriter: w, session: s}
+			next.ServeHTTP(sw, r.WithContext(context.WithValue(r.Context(), contextKey{}, s)))
+			sw.commit()
+		})
+	}
+}
+
+// Get returns the session of the request.
+//
+// Parameters:
+//   - c: The Quick context.
+//
+// Returns:
+//   - *Session: The session, or nil if the session middleware is not installed.
+func Get(c *quick.Ctx) *Session {
+	s, _ := c.Request.Context().Value(contextKey{}).(*Session)
+	if s != nil {
+		s.mu.Lock()
+		s.used = true
+		s.mu.Unlock()
+	}
+	return s
+}
+
+// load restores the session referenced by the request cookie, or starts a new one.
+func load(cfg *Config, r *http.Request) *Session {
+	s := &Session{cfg: cfg}
+	if ck, err := r.Cookie(cfg.CookieName); err == nil && ck.Value != "" {
+		s.token = ck.Value
+		record, err := cfg.Store.Load(ck.Value)
+		if err != nil {
+			log.Printf("session: load: %v", err)
+		}
+		if record != nil && !s.expired(record, time.Now()) {
+			s.record = record
+			return s
+		}
+		if record != nil {
+			_ = cfg.Store.Delete(ck.Value)
+		}
+	}
+	s.reset()
+	return s
+}
+
+// expired reports whether record exceeded the idle or absolute timeout.
+func (s *Session) expired(record *Record, now time.Time) bool {
+	return now.Sub(record.LastAccess) > s.cfg.IdleTimeout || now.Sub(record.Created) > s.cfg.AbsoluteTimeout
+}
+
+// reset replaces the record with a new, empty one.
+func (s *Session) reset() {
+	now := time.Now()
+	s.record = &Record{
+		ID:         s.cfg.KeyGenerator(),
+		Values:     make(map[string]any),
+		Created:    now,
+		LastAccess: now,
+	}
+	s.fresh = true
+}
+
+// ID returns the session identifier.
+func (s *Session) ID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.record.ID
+}
+
+// Fresh reports whether the session was created by the current request.
+func (s *Session) Fresh() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fresh
+}
+
+// Get returns the value stored under key, or nil.
+//
+// Example Usage:
+//
+//	user, _ := session.Get(c).Get("user").(string)
+func (s *Session) Get(key string) any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.record.Values[key]
+}
+
+// Set stores value under key.
+//
+// With the cookie and file stores, the value must be encodable by encoding/gob.
+func (s *Session) Set(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.record.Values[key] = value
+	s.modified = true
+}
+
+// Delete removes the value stored under key.
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.record.Values, key)
+	s.modified = true
+}
+
+// Regenerate gives the session a new ID and creation time, keeping its values.
+//
+// Call it after a privilege change such as login, to prevent session fixation.
+//
+// Returns:
+//   - error: An error if the previous session cannot be deleted from the store.
+func (s *Session) Regenerate() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.deleteStored(); err != nil {
+		return err
+	}
+	now := time.Now()
+	s.record.ID = s.cfg.KeyGenerator()
+	s.record.Created = now
+	s.record.LastAccess = now
+	s.modified = true
+	s.destroyed = false
+	return nil
+}
+
+// Destroy deletes the session from the store and clears its cookie.
+//
+// Returns:
+//   - error: An error if the session cannot be deleted from the store.
+func (s *Session) Destroy() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.deleteStored(); err != nil {
+		return err
+	}
+	s.record.Values = make(map[string]any)
+	s.destroyed = true
+	return nil
+}
+
+// deleteStored removes the record received in the request from the store.
+func (s *Session) deleteStored() error {
+	if s.token == "" {
+		return nil
+	}
+	if err := s.cfg.Store.Delete(s.token); err != nil {
+		return err
+	}
+	s.token = ""
+	return nil
+}
+
+// save persists the session and writes its cookie. It runs once, right
+// before the response headers are sent.
+func (s *Session) save(w http.ResponseWriter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.used {
+		return
+	}
+	cookie := &http.Cookie{
+		Name:     s.cfg.CookieName,
+		Path:     s.cfg.CookiePath,
+		Domain:   s.cfg.CookieDomain,
+		Secure:   s.cfg.CookieSecure,
+		HttpOnly: !s.cfg.DisableHTTPOnly,
+		SameSite: s.cfg.CookieSameSite,
+	}
+
+	if s.destroyed {
+		if s.fresh && s.token == "" && !s.modified {
+			return
+		}
+		cookie.MaxAge = -1
+		cookie.Expires = time.Unix(0, 0)
+		http.SetCookie(w, cookie)
+		return
+	}
+
+	// Do not create sessions for visitors that never stored anything
+	if s.fresh && !s.modified {
+		return
+	}
+
+	now := time.Now()
+	s.record.LastAccess = now
+	ttl := min(s.cfg.IdleTimeout, s.record.Created.Add(s.cfg.AbsoluteTimeout).Sub(now))
+	token, err := s.cfg.Store.Save(s.record, ttl)
+	if err != nil {
+		log.Printf("session: save: %v", err)
+		return
+	}
+
+	cookie.Value = token
+	cookie.Expires = s.record.Created.Add(s.cfg.AbsoluteTimeout)
+	http.SetCookie(w, cookie)
+}
+
+// sessionWriter saves the session before the first byte of the response is written.
+type sessionWriter struct {
+	http.ResponseWriter
+	session   *Session
+	committed bool
+}
+
+// commit saves the session once.
+func (w *sessionWriter) commit() {
+	if w.committed {
+		return
+	}
+	w.committed = true
+	w.session.save(w.ResponseWriter)
+}
+
+// WriteHeader saves the session, then writes the status code.
+func (w *sessionWriter) WriteHeader(code int) {
+	w.commit()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write saves the session, then writes the body.
+func (w *sessionWriter) Write(b []byte) (int, error) {
+	w.commit()
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher for streaming responses.
+func (w *sessionWriter) Flush() {
+	w.commit()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the original ResponseWriter, for http.ResponseController.
+func (w *sessionWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,52 @@
+package session
+
+import (
+	"fmt"
+
+	"github.com/jeffotoni/quick"
+)
+
+// This function is named ExampleNew()
+//
+//	it with the Examples type.
+func ExampleNew() {
+	q := quick.New()
+
+	// Apply the session middleware before registering routes
+	q.Use(New())
+
+	q.Get("/visits", func(c *quick.Ctx) error {
+		s := Get(c)
+		visits, _ := s.Get("visits").(int)
+		s.Set("visits", visits+1)
+		return c.Status(quick.StatusOK).String(fmt.Sprintf("visits: %d", visits+1))
+	})
+
+	// First request creates the session
+	resp, err := q.Qtest(quick.QuickTestOptions{
+		Method: quick.MethodGet,
+		URI:    "/visits",
+	})
+	if err != nil {
+		fmt.Println("Test execution error:", err)
+		return
+	}
+	fmt.Println(resp.BodyStr())
+
+	// Second request sends the session cookie back
+	cookie := resp.Response().Header.Get("Set-Cookie")
+	resp, err = q.Qtest(quick.QuickTestOptions{
+		Method:  quick.MethodGet,
+		URI:     "/visits",
+		Headers: map[string]string{"Cookie": cookie},
+	})
+	if err != nil {
+		fmt.Println("Test execution error:", err)
+		return
+	}
+	fmt.Println(resp.BodyStr())
+
+	// Output:
+	// visits: 1
+	// visits: 2
+}
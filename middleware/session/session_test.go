@@ -0,0 +1,293 @@
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jeffotoni/quick"
+)
+
+// newTestApp registers routes that exercise the session API.
+func newTestApp(cfg Config) *quick.Quick {
+	q := quick.New()
+	q.Use(New(cfg))
+	q.Get("/set", func(c *quick.Ctx) error {
+		s := Get(c)
+		s.Set("user", c.Query["user"])
+		return c.Status(quick.StatusOK).String(s.ID())
+	})
+	q.Get("/get", func(c *quick.Ctx) error {
+		user, _ := Get(c).Get("user").(string)
+		return c.Status(quick.StatusOK).String(user)
+	})
+	q.Get("/delete", func(c *quick.Ctx) error {
+		Get(c).Delete("user")
+		return c.Status(quick.StatusOK).String("ok")
+	})
+	q.Get("/regenerate", func(c *quick.Ctx) error {
+		s := Get(c)
+		if err := s.Regenerate(); err != nil {
+			return err
+		}
+		return c.Status(quick.StatusOK).String(s.ID())
+	})
+	q.Get("/destroy", func(c *quick.Ctx) error {
+		if err := Get(c).Destroy(); err != nil {
+			return err
+		}
+		return c.Status(quick.StatusOK).String("ok")
+	})
+	q.Get("/none", func(c *quick.Ctx) error {
+		return c.Status(quick.StatusOK).String("ok")
+	})
+	return q
+}
+
+// do serves path with the given cookies.
+func do(q *quick.Quick, path string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for _, ck := range cookies {
+		req.AddCookie(ck)
+	}
+	rec := httptest.NewRecorder()
+	q.ServeHTTP(rec, req)
+	return rec
+}
+
+// sessionCookie returns the session cookie set by rec, or nil.
+func sessionCookie(rec *httptest.ResponseRecorder) *http.Cookie {
+	for _, ck := range rec.Result().Cookies() {
+		if ck.Name == DefaultCookieName {
+			return ck
+		}
+	}
+	return nil
+}
+
+// go test -v -failfast -count=1 -run ^TestSessionStores$
+func TestSessionStores(t *testing.T) {
+	fileStore, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stores := map[string]Store{
+		"memory": NewMemoryStore(),
+		"cookie": NewCookieStore(quick.NewKeyRing([]byte("session-secret"))),
+		"file":   fileStore,
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			q := newTestApp(Config{Store: store})
+
+			rec := do(q, "/set?user=jeff")
+			ck := sessionCookie(rec)
+			if ck == nil {
+				t.Fatalf("expected a session cookie, got %v", rec.Result().Cookies())
+			}
+			if !ck.HttpOnly || ck.SameSite != http.SameSiteLaxMode || ck.Path != "/" {
+				t.Errorf("unexpected cookie attributes: %+v", ck)
+			}
+			if body := do(q, "/get", ck).Body.String(); body != "jeff" {
+				t.Errorf("expected %q, got %q", "jeff", body)
+			}
+			if body := do(q, "/get").Body.String(); body != "" {
+				t.Errorf("expected an empty session without cookie, got %q", body)
+			}
+
+			rec = do(q, "/delete", ck)
+			if next := sessionCookie(rec); next != nil {
+				ck = next
+			}
+			if body := do(q, "/get", ck).Body.String(); body != "" {
+				t.Errorf("expected value to be deleted, got %q", body)
+			}
+		})
+	}
+}
+
+// go test -v -failfast -count=1 -run ^TestSessionLazyCreation$
+func TestSessionLazyCreation(t *testing.T) {
+	q := newTestApp(Config{})
+	if ck := sessionCookie(do(q, "/none")); ck != nil {
+		t.Errorf("unexpected cookie for a route not using sessions: %v", ck)
+	}
+	if ck := sessionCookie(do(q, "/get")); ck != nil {
+		t.Errorf("unexpected cookie for an empty session: %v", ck)
+	}
+}
+
+// go test -v -failfast -count=1 -run ^TestSessionRegenerate$
+func TestSessionRegenerate(t *testing.T) {
+	for name, store := range map[string]Store{
+		"memory": NewMemoryStore(),
+		"cookie": NewCookieStore(quick.NewKeyRing([]byte("session-secret"))),
+	} {
+		t.Run(name, func(t *testing.T) {
+			q := newTestApp(Config{Store: store})
+
+			rec := do(q, "/set?user=jeff")
+			old := sessionCookie(rec)
+			oldID := rec.Body.String()
+
+			rec = do(q, "/regenerate", old)
+			ck := sessionCookie(rec)
+			if ck == nil || ck.Value == old.Value || rec.Body.String() == oldID {
+				t.Fatalf("expected a new session id, got %v", ck)
+			}
+			if body := do(q, "/get", ck).Body.String(); body != "jeff" {
+				t.Errorf("expected values to survive Regenerate, got %q", body)
+			}
+			if body := do(q, "/get", old).Body.String(); body != "" {
+				t.Errorf("expected the old cookie to be rejected, got %q", body)
+			}
+		})
+	}
+}
+
+// go test -v -failfast -count=1 -run ^TestSessionDestroy$
+func TestSessionDestroy(t *testing.T) {
+	for name, store := range map[string]Store{
+		"memory": NewMemoryStore(),
+		"cookie": NewCookieStore(quick.NewKeyRing([]byte("session-secret"))),
+	} {
+		t.Run(name, func(t *testing.T) {
+			q := newTestApp(Config{Store: store})
+			ck := sessionCookie(do(q, "/set?user=jeff"))
+
+			cleared := sessionCookie(do(q, "/destroy", ck))
+			if cleared == nil || cleared.MaxAge >= 0 {
+				t.Fatalf("expected the cookie to be cleared, got %v", cleared)
+			}
+			if body := do(q, "/get", ck).Body.String(); body != "" {
+				t.Errorf("expected a replayed cookie of a destroyed session to be empty, got %q", body)
+			}
+		})
+	}
+}
+
+// go test -v -failfast -count=1 -run ^TestSessionKeyGenerator$
+func TestSessionKeyGenerator(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected New to reject IDs the FileStore cannot save")
+		}
+	}()
+	New(Config{Store: store, KeyGenerator: func() string { return "user-42" }})
+}
+
+// go test -v -failfast -count=1 -run ^TestSessionExpiry$
+func TestSessionExpiry(t *testing.T) {
+	keys := quick.NewKeyRing([]byte("session-secret"))
+
+	t.Run("idle", func(t *testing.T) {
+		q := newTestApp(Config{Store: NewCookieStore(keys), IdleTimeout: 20 * time.Millisecond})
+		ck := sessionCookie(do(q, "/set?user=jeff"))
+		time.Sleep(40 * time.Millisecond)
+		if body := do(q, "/get", ck).Body.String(); body != "" {
+			t.Errorf("expected idle session to expire, got %q", body)
+		}
+	})
+
+	t.Run("idle_refreshed", func(t *testing.T) {
+		q := newTestApp(Config{IdleTimeout: 60 * time.Millisecond})
+		ck := sessionCookie(do(q, "/set?user=jeff"))
+		for range 3 {
+			time.Sleep(30 * time.Millisecond)
+			if body := do(q, "/get", ck).Body.String(); body != "jeff" {
+				t.Fatalf("expected activity to keep the session alive, got %q", body)
+			}
+		}
+	})
+
+	t.Run("absolute", func(t *testing.T) {
+		q := newTestApp(Config{
+			Store:           NewCookieStore(keys),
+			IdleTimeout:     time.Hour,
+			AbsoluteTimeout: 20 * time.Millisecond,
+		})
+		ck := sessionCookie(do(q, "/set?user=jeff"))
+		if ck.Expires.IsZero() {
+			t.Errorf("expected cookie expiry to follow the absolute timeout")
+		}
+		time.Sleep(40 * time.Millisecond)
+		if body := do(q, "/get", ck).Body.String(); body != "" {
+			t.Errorf("expected session to expire after the absolute timeout, got %q", body)
+		}
+	})
+}
+
+// go test -v -failfast -count=1 -run ^TestFileStore$
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r, err := store.Load("../../etc/passwd"); r != nil || err != nil {
+		t.Errorf("expected invalid token to be ignored, got %v, %v", r, err)
+	}
+
+	now := time.Now()
+	record := &Record{ID: "6ba7b810-9dad-41d1-80b4-00c04fd430c8", Values: map[string]any{"n": 1}, Created: now, LastAccess: now}
+	if _, err := store.Save(record, time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if err := store.Cleanup(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("expected Cleanup to remove expired files, got %d", len(entries))
+	}
+}
+
+// go test -v -failfast -count=1 -run ^TestCookieStoreTooLarge$
+func TestCookieStoreTooLarge(t *testing.T) {
+	store := NewCookieStore(quick.NewKeyRing([]byte("session-secret")))
+	big := make([]byte, 5000)
+	record := &Record{ID: "id", Values: map[string]any{"big": big}}
+	if _, err := store.Save(record, time.Minute); err != ErrCookieTooLarge {
+		t.Errorf("expected ErrCookieTooLarge, got %v", err)
+	}
+}
+
+// go test -v -failfast -count=1 -run ^TestMemoryStore$
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	record := &Record{ID: "active", Values: map[string]any{"user": "jeff"}}
+
+	// Saving again replaces the expiry of the first save
+	if _, err := store.Save(record, 60*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(40 * time.Millisecond)
+	if _, err := store.Save(record, 60*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(40 * time.Millisecond)
+	if r, _ := store.Load("active"); r == nil || r.Values["user"] != "jeff" {
+		t.Fatalf("expected a session saved again to outlive its first TTL, got %v", r)
+	}
+	time.Sleep(40 * time.Millisecond)
+	if r, _ := store.Load("active"); r != nil {
+		t.Errorf("expected the session to expire after its last TTL, got %v", r)
+	}
+
+	// Expired sessions that are never loaded again are swept by Save
+	store.Save(&Record{ID: "idle"}, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	store.swept = time.Time{}
+	store.Save(&Record{ID: "other"}, time.Minute)
+	if _, ok := store.records["idle"]; ok || len(store.records) != 1 {
+		t.Errorf("expected expired sessions to be swept, got %d records", len(store.records))
+	}
+}
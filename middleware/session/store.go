@@ -0,0 +1,409 @@
+package session
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jeffotoni/quick"
+	"github.com/jeffotoni/quick/uuid"
+)
+
+// Record is the persisted state of a session.
+//
+// Values stored in sessions are encoded with encoding/gob by the cookie and
+// file stores, so custom types must be registered with gob.Register.
+type Record struct {
+	ID         string         // Session identifier
+	Values     map[string]any // Session values
+	Created    time.Time      // Creation time, used by the absolute timeout
+	LastAccess time.Time      // Last request time, used by the idle timeout
+}
+
+// Store persists session records.
+//
+// The token is the value kept in the session cookie: the session ID for
+// server-side stores, or the encoded record itself for the cookie store.
+//
+// Implementations must be safe for concurrent use. Stores that only accept some
+// session IDs can implement ValidID(id string) bool, which New checks against
+// Config.KeyGenerator.
+type Store interface {
+	// Load returns the record referenced by token, or (nil, nil) if it does not exist.
+	Load(token string) (*Record, error)
+	// Save persists record for ttl and returns the token to send in the cookie.
+	Save(record *Record, ttl time.Duration) (string, error)
+	// Delete removes the record referenced by token.
+	Delete(token string) error
+}
+
+// ErrCookieTooLarge is returned by the cookie store when the encoded session
+// does not fit in a browser cookie.
+var ErrCookieTooLarge = errors.New("session: encoded session exceeds 4KB cookie limit")
+
+// encodeRecord serializes a record with encoding/gob.
+func encodeRecord(r *Record) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(r); err != nil {
+		return nil, fmt.Errorf("session: encode: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeRecord deserializes a record written by encodeRecord.
+func decodeRecord(b []byte) (*Record, error) {
+	var r Record
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&r); err != nil {
+		return nil, fmt.Errorf("session: decode: %w", err)
+	}
+	return &r, nil
+}
+
+// copyRecord returns a copy of r with its own Values map.
+func copyRecord(r *Record) *Record {
+	cp := *r
+	cp.Values = make(map[string]any, len(r.Values))
+	for k, v := range r.Values {
+		cp.Values[k] = v
+	}
+	return &cp
+}
+
+// MemoryStore keeps sessions in a map keyed by the session ID.
+//
+// Expired sessions are removed when loaded, and by a sweep of the whole map
+// done by Save at most once a minute. Sessions are lost on restart and are
+// not shared between instances.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]memoryRecord
+	swept   time.Time // Last removal of expired sessions
+}
+
+// memoryRecord is a record of MemoryStore with its expiry.
+type memoryRecord struct {
+	record  *Record
+	expires time.Time // Zero when the record does not expire
+}
+
+// expired reports whether the record has expired at now.
+func (m memoryRecord) expired(now time.Time) bool {
+	return !m.expires.IsZero() && now.After(m.expires)
+}
+
+// NewMemoryStore creates an in-memory store.
+//
+// Returns:
+//   - *MemoryStore: The store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]memoryRecord)}
+}
+
+// Load returns a copy of the record stored under token.
+func (s *MemoryStore) Load(token string) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.records[token]
+	if !ok {
+		return nil, nil
+	}
+	if m.expired(time.Now()) {
+		delete(s.records, token)
+		return nil, nil
+	}
+	return copyRecord(m.record), nil
+}
+
+// Save stores a copy of record under its ID for ttl, replacing the previous
+// record and its expiry. A ttl <= 0 keeps the record until it is deleted.
+func (s *MemoryStore) Save(record *Record, ttl time.Duration) (string, error) {
+	now := time.Now()
+	m := memoryRecord{record: copyRecord(record)}
+	if ttl > 0 {
+		m.expires = now.Add(ttl)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.swept) > time.Minute {
+		for id, old := range s.records {
+			if old.expired(now) {
+				delete(s.records, id)
+			}
+		}
+		s.swept = now
+	}
+	s.records[record.ID] = m
+	return record.ID, nil
+}
+
+// Delete removes the record stored under token.
+func (s *MemoryStore) Delete(token string) error {
+	s.mu.Lock()
+	delete(s.records, token)
+	s.mu.Unlock()
+	return nil
+}
+
+// CookieStore keeps the whole session in the cookie, signed with a quick.KeyRing.
+//
+// The values are not stored on the server, so sessions survive restarts and work
+// across instances, but they are readable by the client and limited to about 4KB.
+//
+// A signed cookie stays valid until it expires, so the IDs of regenerated and
+// destroyed sessions are remembered in memory until their absolute timeout and
+// cookies carrying them are rejected. The list is kept by each instance: with
+// several instances, a revoked cookie is only rejected by the instance that
+// revoked it, and a restart forgets it. Use a server-side store when logins and
+// logouts must be enforced everywhere.
+type CookieStore struct {
+	keys   *quick.KeyRing
+	name   string
+	maxAge atomic.Int64 // Longest absolute timeout of the middlewares using the store
+
+	mu      sync.Mutex
+	revoked map[string]time.Time // IDs of regenerated and destroyed sessions, until their expiry
+	pruned  time.Time            // Last removal of expired IDs
+}
+
+// NewCookieStore creates a store that signs sessions with keys.
+//
+// Parameters:
+//   - keys: The key ring used to sign and verify cookies; supports rotation.
+//
+// Returns:
+//   - *CookieStore: The store.
+func NewCookieStore(keys *quick.KeyRing) *CookieStore {
+	s := &CookieStore{keys: keys, name: "session", revoked: make(map[string]time.Time)}
+	s.maxAge.Store(int64(DefaultAbsoluteTimeout))
+	return s
+}
+
+// setMaxAge raises how long revoked IDs are remembered to the absolute timeout
+// of a middleware using the store.
+func (s *CookieStore) setMaxAge(d time.Duration) {
+	for {
+		cur := s.maxAge.Load()
+		if int64(d) <= cur || s.maxAge.CompareAndSwap(cur, int64(d)) {
+			return
+		}
+	}
+}
+
+// Load verifies and decodes the session carried by token.
+//
+// Expiry is checked by the middleware using the record times, so a replayed
+// cookie cannot outlive the idle and absolute timeouts. Cookies of regenerated
+// and destroyed sessions are rejected.
+func (s *CookieStore) Load(token string) (*Record, error) {
+	r, err := s.decode(token)
+	if r == nil || err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	until, revoked := s.revoked[r.ID]
+	s.mu.Unlock()
+	if revoked && time.Now().Before(until) {
+		return nil, nil
+	}
+	return r, nil
+}
+
+// decode verifies and decodes token, returning nil for invalid tokens.
+func (s *CookieStore) decode(token string) (*Record, error) {
+	signed, err := s.keys.Verify(s.name, token)
+	if err != nil {
+		// A tampered or outdated cookie starts a new session
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(signed)
+	if err != nil {
+		return nil, nil
+	}
+	return decodeRecord(b)
+}
+
+// Save encodes and signs record.
+func (s *CookieStore) Save(record *Record, _ time.Duration) (string, error) {
+	b, err := encodeRecord(record)
+	if err != nil {
+		return "", err
+	}
+	token, err := s.keys.Sign(s.name, base64.RawURLEncoding.EncodeToString(b))
+	if err != nil {
+		return "", err
+	}
+	if len(token) > 4096 {
+		return "", ErrCookieTooLarge
+	}
+	return token, nil
+}
+
+// Delete revokes the session carried by token until its absolute timeout,
+// so the cookie cannot be replayed after Regenerate or Destroy.
+func (s *CookieStore) Delete(token string) error {
+	r, err := s.decode(token)
+	if r == nil || err != nil {
+		return nil
+	}
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Forget the IDs whose cookies have expired anyway, at most once a minute
+	if now.Sub(s.pruned) > time.Minute {
+		for id, until := range s.revoked {
+			if now.After(until) {
+				delete(s.revoked, id)
+			}
+		}
+		s.pruned = now
+	}
+	if until := r.Created.Add(time.Duration(s.maxAge.Load())); now.Before(until) {
+		s.revoked[r.ID] = until
+	}
+	return nil
+}
+
+// FileStore keeps one gob-encoded file per session in a directory.
+//
+// Expired files are removed when loaded. Call Cleanup periodically to remove
+// sessions that are never requested again.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// fileRecord is the on-disk format of FileStore.
+type fileRecord struct {
+	Expires time.Time
+	Data    []byte
+}
+
+// NewFileStore creates a store that writes sessions to dir, creating it if needed.
+//
+// Parameters:
+//   - dir: The directory where session files are written.
+//
+// Returns:
+//   - *FileStore: The store.
+//   - error: An error if the directory cannot be created.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("session: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// ValidID reports whether id can be stored: FileStore only accepts UUIDs.
+// New checks the KeyGenerator against it.
+func (s *FileStore) ValidID(id string) bool {
+	_, ok := s.path(id)
+	return ok
+}
+
+// path returns the file of a session, rejecting tokens that are not UUIDs
+// so that a cookie can never point outside the directory.
+func (s *FileStore) path(token string) (string, bool) {
+	if _, err := uuid.Parse(token); err != nil || len(token) != 36 {
+		return "", false
+	}
+	return filepath.Join(s.dir, "sess_"+token), true
+}
+
+// Load reads the record stored under token.
+func (s *FileStore) Load(token string) (*Record, error) {
+	p, ok := s.path(token)
+	if !ok {
+		return nil, nil
+	}
+	s.mu.Lock()
+	b, err := os.ReadFile(p)
+	s.mu.Unlock()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("session: %w", err)
+	}
+
+	var fr fileRecord
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&fr); err != nil {
+		return nil, fmt.Errorf("session: decode: %w", err)
+	}
+	if !fr.Expires.IsZero() && time.Now().After(fr.Expires) {
+		return nil, s.Delete(token)
+	}
+	return decodeRecord(fr.Data)
+}
+
+// Save writes record to its file, replacing it atomically.
+func (s *FileStore) Save(record *Record, ttl time.Duration) (string, error) {
+	p, ok := s.path(record.ID)
+	if !ok {
+		return "", fmt.Errorf("session: invalid session id %q", record.ID)
+	}
+	data, err := encodeRecord(record)
+	if err != nil {
+		return "", err
+	}
+	fr := fileRecord{Data: data}
+	if ttl > 0 {
+		fr.Expires = time.Now().Add(ttl)
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(fr); err != nil {
+		return "", fmt.Errorf("session: encode: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return "", fmt.Errorf("session: %w", err)
+	}
+	if err := os.Rename(tmp, p); err != nil {
+		return "", fmt.Errorf("session: %w", err)
+	}
+	return record.ID, nil
+}
+
+// Delete removes the file stored under token.
+func (s *FileStore) Delete(token string) error {
+	p, ok := s.path(token)
+	if !ok {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("session: %w", err)
+	}
+	return nil
+}
+
+// Cleanup removes every expired session file.
+//
+// Returns:
+//   - error: The first error encountered while reading the directory.
+func (s *FileStore) Cleanup() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("session: %w", err)
+	}
+	for _, e := range entries {
+		if token, ok := strings.CutPrefix(e.Name(), "sess_"); ok && !strings.HasSuffix(token, ".tmp") {
+			if _, err := s.Load(token); err != nil {
+				continue
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+// Package timeout provides request timeout middleware for Quick.
+//
+// The handler runs with a deadline attached to c.Request.Context() and
+// c.Ctx(), so database calls, HTTP clients and anything else honoring the
+// context stop when time is up. The response is buffered while the handler
+// runs: if the deadline passes first, the client receives the timeout
+// response and whatever the handler writes afterwards is discarded.
+//
+// Features:
+//   - Deadline on the request context, canceled when the response is sent.
+//   - Configurable 503/504 status and body.
+//   - Late writes from the handler goroutine are safely discarded.
+//   - Per-route timeouts with Route or TimeoutFunc.
+package timeout
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jeffotoni/quick"
+)
+
+// Config defines the configuration options for the timeout middleware.
+type Config struct {
+	// Timeout is the time a handler has to complete.
+	// Default is 5 seconds.
+	Timeout time.Duration
+
+	// TimeoutFunc returns the timeout of a request, overriding Timeout for
+	// some routes. A value <= 0 uses Timeout.
+	TimeoutFunc func(c *quick.Ctx) time.Duration
+
+	// StatusCode is the status of the timeout response.
+	// Default is 503 Service Unavailable; 504 Gateway Timeout is common for proxies.
+	StatusCode int
+
+	// Message is the message of the timeout response.
+	// Default is the status text of StatusCode.
+	Message string
+
+	// OnTimeout writes the timeout response, replacing StatusCode and Message.
+	// Returned errors are rendered by the error handler of the app.
+	// Default returns quick.NewError(StatusCode, Message).
+	OnTimeout func(c *quick.Ctx) error
+
+	// Next is a function that determines whether to skip the middleware.
+	Next func(c *quick.Ctx) bool
+}
+
+// ConfigDefault is the default configuration.
+var ConfigDefault = Config{
+	Timeout:    5 * time.Second,
+	StatusCode: quick.StatusServiceUnavailable,
+}
+
+// New creates the timeout middleware.
+//
+// Parameters:
+//   - config: Optional configuration; ConfigDefault is used when omitted.
+//
+// Returns:
+//   - func(http.Handler) http.Handler: The middleware, usable with q.Use and Group.Use.
+//
+// Example Usage:
+//
+//	q.Use(timeout.New(timeout.Config{
+//	    Timeout:    2 * time.Second,
+//	    StatusCode: quick.StatusGatewayTimeout,
+//	}))
+func New(config ...Config) func(http.Handler) http.Handler {
+	cfg := configDefault(config...)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c := &quick.Ctx{Response: w, Request: r}
+			if cfg.Next != nil && cfg.Next(c) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			err := run(c, cfg, cfg.timeout(c), func(tw http.ResponseWriter, r *http.Request) error {
+				next.ServeHTTP(tw, r)
+				return nil
+			})
+			if err != nil {
+				quick.HandleError(c, err)
+			}
+		})
+	}
+}
+
+// Route sets the timeout of a single route.
+//
+// A route behind New is bound by both deadlines: Route can shorten the
+// global timeout but not extend it. To give a route more time than the
+// rest of the application, use TimeoutFunc or Next on the global middleware.
+//
+// Parameters:
+//   - timeout: The time the handler has to complete.
+//   - config: Optional configuration for the timeout response.
+//
+// Returns:
+//   - func(quick.HandleFunc) quick.HandleFunc: The handler wrapper.
+//
+// Example Usage:
+//
+//	q.Get("/search", timeout.Route(300*time.Millisecond)(search))
+func Route(timeout time.Duration, config ...Config) func(quick.HandleFunc) quick.HandleFunc {
+	cfg := configDefault(config...)
+	if timeout > 0 {
+		cfg.Timeout = timeout
+	}
+	return func(h quick.HandleFunc) quick.HandleFunc {
+		return func(c *quick.Ctx) error {
+			if cfg.Next != nil && cfg.Next(c) {
+				return h(c)
+			}
+			// The handler may outlive the request, after c returns to the
+			// pool: give it its own copy.
+			hc := *c
+			hc.Params = maps.Clone(c.Params)
+			hc.Query = maps.Clone(c.Query)
+			hc.Headers = maps.Clone(c.Headers)
+			return run(c, cfg, cfg.timeout(c), func(tw http.ResponseWriter, r *http.Request) error {
+				hc.Response = tw
+				hc.Request = r
+				if hc.Context != nil {
+					hc.Context = r.Context()
+				}
+				return h(&hc)
+			})
+		}
+	}
+}
+
+// configDefault applies the defaults of config.
+func configDefault(config ...Config) Config {
+	cfg := ConfigDefault
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = ConfigDefault.Timeout
+	}
+	if cfg.StatusCode == 0 {
+		cfg.StatusCode = ConfigDefault.StatusCode
+	}
+	if cfg.OnTimeout == nil {
+		qerr := quick.NewError(cfg.StatusCode)
+		if cfg.Message != "" {
+			qerr.Message = cfg.Message
+		}
+		cfg.OnTimeout = func(c *quick.Ctx) error {
+			return qerr
+		}
+	}
+	return cfg
+}
+
+// timeout returns the timeout of the request.
+func (cfg *Config) timeout(c *quick.Ctx) time.Duration {
+	if cfg.TimeoutFunc != nil {
+		if d := cfg.TimeoutFunc(c); d > 0 {
+			return d
+		}
+	}
+	return cfg.Timeout
+}
+
+// run calls handler in its own goroutine with a deadline of d, and sends
+// either its buffered response or the timeout response.
+func run(c *quick.Ctx, cfg Config, d time.Duration, handler func(tw http.ResponseWriter, r *http.Request) error) error {
+	parent := c.Request.Context()
+	if c.Context != nil {
+		parent = c.Context
+	}
+	ctx, cancel := context.WithTimeout(parent, d)
+	defer cancel()
+	r := c.Request.WithContext(ctx)
+
+	tw := &timeoutWriter{header: c.Response.Header().Clone()}
+	done := make(chan error, 1)
+	panicked := make(chan any, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				panicked <- p
+			}
+		}()
+		done <- handler(tw, r)
+	}()
+
+	select {
+	case p := <-panicked:
+		// Re-panic in the serving goroutine, for the recover middleware
+		panic(p)
+	case err := <-done:
+		tw.flush(c.Response)
+		return err
+	case <-ctx.Done():
+		tw.mu.Lock()
+		tw.timedOut = true
+		tw.mu.Unlock()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return cfg.OnTimeout(c)
+		}
+		// The client went away: nobody is waiting for a response
+		return nil
+	}
+}
+
+// timeoutWriter buffers the response of the handler until it completes.
+//
+// Writes after the timeout fail with http.ErrHandlerTimeout and are discarded.
+type timeoutWriter struct {
+	mu          sync.Mutex
+	header      http.Header
+	buf         bytes.Buffer
+	code        int
+	wroteHeader bool
+	timedOut    bool
+}
+
+// Header returns the buffered response headers.
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+// WriteHeader buffers the status code.
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.code = code
+	tw.wroteHeader = true
+}
+
+// Write buffers the body, or fails once the timeout has passed.
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !tw.wroteHeader {
+		tw.code = http.StatusOK
+		tw.wroteHeader = true
+	}
+	return tw.buf.Write(b)
+}
+
+// flush sends the buffered response to w.
+func (tw *timeoutWriter) flush(w http.ResponseWriter) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	dst := w.Header()
+	for k := range dst {
+		if _, ok := tw.header[k]; !ok {
+			delete(dst, k)
+		}
+	}
+	for k, v := range tw.header {
+		dst[k] = v
+	}
+	if tw.wroteHeader {
+		w.WriteHeader(tw.code)
+	}
+	if tw.buf.Len() > 0 {
+		_, _ = w.Write(tw.buf.Bytes())
+	}
+}
@@ -0,0 +1,42 @@
+package timeout
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jeffotoni/quick"
+)
+
+// This function is named ExampleNew()
+//
+//	it with the Examples type.
+func ExampleNew() {
+	q := quick.New()
+
+	q.Use(New(Config{
+		Timeout:    20 * time.Millisecond,
+		StatusCode: quick.StatusGatewayTimeout,
+	}))
+
+	q.Get("/slow", func(c *quick.Ctx) error {
+		select {
+		case <-time.After(time.Second):
+			return c.String("too late")
+		case <-c.Ctx().Done():
+			return c.Ctx().Err()
+		}
+	})
+
+	resp, err := q.Qtest(quick.QuickTestOptions{
+		Method: quick.MethodGet,
+		URI:    "/slow",
+	})
+	if err != nil {
+		fmt.Println("Test execution error:", err)
+		return
+	}
+	fmt.Println(resp.StatusCode(), resp.BodyStr())
+
+	// Output:
+	// 504 {"message":"Gateway Timeout","code":504}
+}
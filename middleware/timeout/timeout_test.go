@@ -0,0 +1,177 @@
+package timeout
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jeffotoni/quick"
+)
+
+// serve sends a GET request to q.
+func serve(q *quick.Quick, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	q.ServeHTTP(rec, httptest.NewRequest(quick.MethodGet, path, nil))
+	return rec
+}
+
+// TestNew verifies completed handlers, the timeout response, context
+// cancellation and discarded late writes.
+//
+// go test -v -failfast -count=1 -run ^TestNew$
+func TestNew(t *testing.T) {
+	lateWrite := make(chan error, 1)
+	canceled := make(chan error, 1)
+	responded := make(chan struct{})
+
+	q := quick.New()
+	q.Use(New(Config{Timeout: 50 * time.Millisecond}))
+	q.Get("/fast", func(c *quick.Ctx) error {
+		c.Set("X-Handler", "fast")
+		return c.Status(quick.StatusCreated).String("done")
+	})
+	q.Get("/slow", func(c *quick.Ctx) error {
+		select {
+		case <-c.Ctx().Done():
+			canceled <- c.Ctx().Err()
+		case <-time.After(time.Second):
+			canceled <- nil
+		}
+		<-responded
+		_, err := c.Response.Write([]byte("late"))
+		lateWrite <- err
+		return nil
+	})
+
+	rec := serve(q, "/fast")
+	if rec.Code != http.StatusCreated || rec.Body.String() != "done" || rec.Header().Get("X-Handler") != "fast" {
+		t.Fatalf("unexpected response: %d %q %v", rec.Code, rec.Body.String(), rec.Header())
+	}
+
+	rec = serve(q, "/slow")
+	close(responded)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rec.Code)
+	}
+	var e quick.Error
+	if err := json.Unmarshal(rec.Body.Bytes(), &e); err != nil || e.Message != "Service Unavailable" {
+		t.Errorf("expected a quick.Error payload, got %q", rec.Body.String())
+	}
+	if err := <-canceled; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the handler context to expire, got %v", err)
+	}
+	if err := <-lateWrite; !errors.Is(err, http.ErrHandlerTimeout) {
+		t.Errorf("expected late writes to fail with ErrHandlerTimeout, got %v", err)
+	}
+	if rec.Body.String() != `{"message":"Service Unavailable","code":503}` {
+		t.Errorf("late write leaked into the response: %q", rec.Body.String())
+	}
+}
+
+// TestRoute verifies per-route timeouts, TimeoutFunc and a custom response.
+//
+// go test -v -failfast -count=1 -run ^TestRoute$
+func TestRoute(t *testing.T) {
+	sleep := func(d time.Duration) quick.HandleFunc {
+		return func(c *quick.Ctx) error {
+			select {
+			case <-time.After(d):
+			case <-c.Ctx().Done():
+				return c.Ctx().Err()
+			}
+			return c.Status(quick.StatusOK).String(c.Params["name"])
+		}
+	}
+
+	q := quick.New()
+	q.Use(New(Config{
+		Timeout: 50 * time.Millisecond,
+		TimeoutFunc: func(c *quick.Ctx) time.Duration {
+			if c.Path() == "/report" {
+				return time.Second
+			}
+			return 0
+		},
+	}))
+	q.Get("/report", sleep(100*time.Millisecond))
+	q.Get("/search/:name", Route(10*time.Millisecond, Config{
+		StatusCode: quick.StatusGatewayTimeout,
+		Message:    "search took too long",
+	})(sleep(30*time.Millisecond)))
+	q.Get("/hello/:name", Route(time.Second)(sleep(0)))
+
+	if rec := serve(q, "/report"); rec.Code != http.StatusOK {
+		t.Errorf("expected TimeoutFunc to extend the timeout, got %d", rec.Code)
+	}
+	rec := serve(q, "/search/go")
+	if rec.Code != http.StatusGatewayTimeout || rec.Body.String() != `{"message":"search took too long","code":504}` {
+		t.Errorf("unexpected route timeout response: %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := serve(q, "/hello/quick"); rec.Code != http.StatusOK || rec.Body.String() != "quick" {
+		t.Errorf("unexpected response: %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+// TestPanic verifies that a panic in the handler reaches the serving goroutine.
+//
+// go test -v -failfast -count=1 -run ^TestPanic$
+func TestPanic(t *testing.T) {
+	h := New()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+	defer func() {
+		if p := recover(); p != "boom" {
+			t.Errorf("expected the panic to propagate, got %v", p)
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(quick.MethodGet, "/", nil))
+}
+
+// TestLateParams verifies that a handler still running after the timeout
+// response reads the path parameters of its own request, while later
+// requests reuse the pooled state of the router.
+//
+// go test -v -failfast -count=1 -run ^TestLateParams$
+func TestLateParams(t *testing.T) {
+	q := quick.New()
+	q.Use(New(Config{Timeout: 20 * time.Millisecond}))
+	q.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/a/slow" {
+				time.Sleep(60 * time.Millisecond)
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	late := make(chan string, 1)
+	q.Get("/a/:id", func(c *quick.Ctx) error {
+		if c.Path() == "/a/slow" {
+			late <- c.Param("id")
+		}
+		return c.Status(quick.StatusOK).String(c.Param("id"))
+	})
+
+	if rec := serve(q, "/a/slow"); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected the timeout response, got %d", rec.Code)
+	}
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case id := <-late:
+			if id != "slow" {
+				t.Errorf("expected the late handler to see id=slow, got %q", id)
+			}
+			return
+		case <-deadline:
+			t.Fatal("the late handler did not run")
+		default:
+			if rec := serve(q, "/a/fast"); rec.Body.String() != "fast" {
+				t.Fatalf("expected fast, got %q", rec.Body.String())
+			}
+		}
+	}
+}
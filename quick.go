@@ -11,8 +11,6 @@ import (
 	"context"
 	"crypto/tls"
 	"embed"
-	"encoding/json"
-	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
@@ -25,6 +23,7 @@ import (
 	"regexp"
 	"runtime"
 	"runtime/debug"
+	"slices"
 	"strings"
 	"sync"
 	"syscall"
@@ -39,9 +38,13 @@ const SO_REUSEPORT = 0x0F
 
 // Content-Type constants used for response headers
 const (
-	ContentTypeAppJSON = `application/json`
-	ContentTypeAppXML  = `application/xml`
-	ContentTypeTextXML = `text/xml`
+	ContentTypeAppJSON     = `application/json`
+	ContentTypeAppXML      = `application/xml`
+	ContentTypeTextXML     = `text/xml`
+	ContentTypeProblemJSON = `application/problem+json` // RFC 9457 problem details
+	ContentTypeProblemXML  = `application/problem+xml`  // RFC 9457 problem details
+	ContentTypeForm        = `application/x-www-form-urlencoded`
+	ContentTypeMultipart   = `multipart/form-data`
 )
 
 // Error represents a custom HTTP error that can be returned from a handler.
@@ -211,10 +214,11 @@ type Route struct {
 
 // ctxServeHttp represents the structure for handling HTTP requests
 type ctxServeHttp struct {
-	Path      string            // Requested URL path
-	Params    string            // Query parameters from the request
-	Method    string            // HTTP method of the request
-	ParamsMap map[string]string // Parsed parameters mapped as key-value pairs
+	Path   string       // Requested URL path
+	Params string       // Query parameters from the request
+	Method string       // HTTP method of the request
+	params []routeParam // Path parameters extracted by the router
+	app    *Quick       // App serving the request, see HandleError
 }
 
 // Config defines various configuration options for the Quick server
@@ -238,6 +242,10 @@ type Config struct {
 	CorsConfig        *CorsConfig   // Specific type for CORS
 	Views             template.TemplateEngine
 
+	// CookieKeys signs and encrypts cookies (see Ctx.SetSignedCookie and Ctx.SetEncryptedCookie).
+	// The first key is current; older keys are kept to accept cookies issued before a rotation.
+	CookieKeys *KeyRing
+
 	NoBanner bool // Flag to disable the Quick startup Display.
 }
 
@@ -279,6 +287,8 @@ type Quick struct {
 	handler       http.Handler                    // The primary HTTP handler.
 	mux           *http.ServeMux                  // Multiplexer for routing requests.
 	routes        []*Route                        // Registered routes.
+	router        *router                         // Per-method routing trees built from routes.
+	codecs        *codecRegistry                  // Codecs by media type, see RegisterCodec.
 	routeCapacity int                             // The maximum number of routes allowed.
 	mws2          []any                           // List of registered middlewares.
 	CorsSet       func(http.Handler) http.Handler // CORS middleware handler function.
@@ -288,6 +298,10 @@ type Quick struct {
 	hasEmbed   bool         // File system for embedded static files.
 	server     *http.Server // Http server
 	bufferPool *sync.Pool   // Reusable buffer pool to reduce allocations and improve performance
+
+	errorHandler            func(*Ctx, error) error // Renders handler errors, see SetErrorHandler.
+	notFoundHandler         HandleFunc              // Answers unmatched requests, see SetNotFoundHandler.
+	methodNotAllowedHandler HandleFunc              // Answers 405 requests, see SetMethodNotAllowedHandler.
 }
 
 // indeed to Quick
@@ -319,7 +333,7 @@ func (q *Quick) HandlerFunc(h HandlerFunc) http.HandlerFunc {
 		c := newCtx(w, req, q) // replace the Response at the time of creating the Ctx
 
 		if err := h(c); err != nil {
-			http.Error(w, err.Error(), StatusInternalServerError)
+			c.handleError(err)
 		}
 	}
 }
@@ -411,6 +425,8 @@ func New(c ...Config) *Quick {
 	// Initialize and return the Quick instance
 	return &Quick{
 		routes:        make([]*Route, 0, config.RouteCapacity),
+		router:        newRouter(),
+		codecs:        newCodecRegistry(),
 		routeCapacity: config.RouteCapacity,
 		mux:           http.NewServeMux(),
 		handler:       http.NewServeMux(),
@@ -476,6 +492,12 @@ func isCorsMiddleware(mw func(http.Handler) http.Handler) bool {
 	testRequest.Header.Set("Access-Control-Request-Headers", "Content-Type, X-App-Marca") // Add requested headers
 	testResponse := httptest.NewRecorder()
 
+	// The probe is canceled, so middlewares that forward requests, such as
+	// a reverse proxy, do not send it anywhere
+	ctx, cancel := context.WithCancel(testRequest.Context())
+	cancel()
+	testRequest = testRequest.WithContext(ctx)
+
 	mw(testHandler).ServeHTTP(testResponse, testRequest)
 
 	for header := range testResponse.Header() {
@@ -539,13 +561,6 @@ func (q *Quick) registerRoute(method, pattern string, handlerFunc HandleFunc) {
 	path, params, patternExist := extractParamsPattern(pattern)
 	formattedPath := concat.String(strings.ToLower(method), "#", clearRegex(pattern))
 
-	for _, route := range q.routes {
-		if route.Method == method && route.Path == path {
-			fmt.Printf("Warning: Route '%s %s' is already registered, ignoring duplicate registration.\n", method, path)
-			return // Ignore duplication instead of generating panic
-		}
-	}
-
 	route := Route{
 		Pattern: patternExist,
 		Path:    path,
@@ -554,7 +569,11 @@ func (q *Quick) registerRoute(method, pattern string, handlerFunc HandleFunc) {
 		Method:  method,
 	}
 
-	q.appendRoute(&route)
+	// Duplicated or invalid patterns are rejected by the router
+	// instead of generating a panic in the ServeMux
+	if !q.appendRoute(&route) {
+		return
+	}
 	q.mux.HandleFunc(formattedPath, route.handler)
 
 }
@@ -566,11 +585,13 @@ func (q *Quick) registerRoute(method, pattern string, handlerFunc HandleFunc) {
 // If CORS middleware is enabled, it applies the middleware before setting default headers.
 //
 // Headers added by this function:
+// - Allow: The methods registered for the requested path (or for any path with "OPTIONS *").
 // - Access-Control-Allow-Origin: Allows cross-origin requests (set dynamically).
-// - Access-Control-Allow-Methods: Specifies allowed HTTP methods (GET, POST, PUT, DELETE, OPTIONS).
+// - Access-Control-Allow-Methods: Specifies allowed HTTP methods for the requested path.
 // - Access-Control-Allow-Headers: Defines which headers are allowed in the request.
 //
-// If no Origin header is provided in the request, a 204 No Content response is returned.
+// If no Origin header is provided in the request, a 204 No Content response is returned
+// with the Allow header, or the not found handler answers if no route matches the path.
 //
 // Parameters:
 // - w: http.ResponseWriter – The response writer to send headers and status.
@@ -582,8 +603,27 @@ func (q *Quick) registerRoute(method, pattern string, handlerFunc HandleFunc) {
 // Example Usage:
 // This function is automatically triggered in `ServeHTTP()` when an OPTIONS request is received.
 func (q *Quick) handleOptions(w http.ResponseWriter, r *http.Request) {
+	reqPath := r.URL.Path
+	if reqPath != "*" {
+		reqPath = path.Clean(reqPath)
+	}
+	allow := strings.Join(q.router.allowed(reqPath), ", ")
+
 	origin := r.Header.Get("Origin")
 	if origin == "" {
+		if allow == "" {
+			// The package-level NotFound answers OPTIONS with 204, so it is
+			// only used through the handler set with SetNotFoundHandler
+			if q.notFoundHandler == nil {
+				http.NotFound(w, r)
+				return
+			}
+			c := newCtx(w, r, q)
+			q.notFound(c)
+			releaseCtx(c)
+			return
+		}
+		w.Header().Set("Allow", allow)
 		w.WriteHeader(StatusNoContent)
 		return
 	}
@@ -593,9 +633,12 @@ func (q *Quick) handleOptions(w http.ResponseWriter, r *http.Request) {
 		q.CorsSet(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(w, r)
 	} else {
 		// Only set default CORS headers if no CORS middleware is configured
-		w.Header().Set("Allow", "GET, POST, PUT, DELETE, OPTIONS")
+		if allow == "" {
+			allow = "GET, POST, PUT, DELETE, OPTIONS"
+		}
+		w.Header().Set("Allow", allow)
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", allow)
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 	}
 
@@ -817,9 +860,7 @@ func extractParamsOptions(q *Quick, method, path string, handlerFunc HandleFunc)
 
 		// Execute handler function if provided
 		if handlerFunc != nil {
-			if err := handlerFunc(ctx); err != nil {
-				http.Error(w, err.Error(), StatusInternalServerError)
-			}
+			execHandleFunc(ctx, handlerFunc)
 		} else {
 			w.WriteHeader(StatusNoContent) // 204 No Content if no handlerFunc
 		}
@@ -848,15 +889,51 @@ func extractHeaders(req http.Request) map[string][]string {
 	return headersMap
 }
 
-// extractParamsBind decodes request bodies for JSON/XML payloads using a pooled buffer
+// errUnsupportedContentType is wrapped by the body decoders when the request
+// Content-Type cannot be decoded.
+var errUnsupportedContentType = errors.New("unsupported content type")
+
+// bindError converts a body decoding error into a *Problem, so the default
+// error handler answers with a client error instead of 500.
+//
+// The original error is kept as the cause and its message as the detail.
+//
+// Parameters:
+//   - err error: The error returned while reading or decoding the body.
+//
+// Returns:
+//   - error: A 415, 413 or 400 *Problem wrapping err, or err itself when it
+//     is already a *Problem or a *BindError.
+func bindError(err error) error {
+	var (
+		tooLarge *http.MaxBytesError
+		prob     *Problem
+		berr     *BindError
+	)
+	switch {
+	case errors.As(err, &prob), errors.As(err, &berr):
+		return err
+	case errors.Is(err, errUnsupportedContentType):
+		return ProblemUnsupportedMediaType(err.Error()).Wrap(err)
+	case errors.As(err, &tooLarge):
+		return ProblemRequestEntityTooLarge(err.Error()).Wrap(err)
+	default:
+		return ProblemBadRequest(err.Error()).Wrap(err)
+	}
+}
+
+// extractParamsBind decodes request bodies using a pooled buffer
 // to minimize memory allocations and garbage collection overhead.
 //
-// This function checks the request's `Content-Type` and processes JSON or XML payloads accordingly.
+// This function checks the request's `Content-Type` and decodes the payload with the codec
+// registered for it (JSON and XML by default, see RegisterCodec).
+// Form bodies (urlencoded or multipart) are bound into the `form` tagged fields of v,
+// with *UploadedFile and []*UploadedFile fields filled from the multipart files.
 // It ensures efficient memory usage by leveraging buffer pools for reading request bodies.
 //
 // Parameters:
 //   - c *Ctx: The Quick context containing request information.
-//   - v interface{}: The target structure where the decoded JSON/XML data will be stored.
+//   - v interface{}: The target structure where the decoded data will be stored.
 //
 // Returns:
 //   - error: Returns any decoding errors encountered or an error for unsupported content types.
@@ -867,47 +944,36 @@ func extractHeaders(req http.Request) map[string][]string {
 func extractParamsBind(c *Ctx, v interface{}) error {
 	contentType := strings.ToLower(c.Request.Header.Get("Content-Type"))
 
-	// Check supported Content-Type
-	if !strings.HasPrefix(contentType, ContentTypeAppJSON) &&
-		!strings.HasPrefix(contentType, ContentTypeAppXML) &&
-		!strings.HasPrefix(contentType, ContentTypeTextXML) {
-		return fmt.Errorf("unsupported content type: %s", contentType)
+	// Form bodies fill the `form` tagged fields, including uploaded files
+	if strings.HasPrefix(contentType, ContentTypeForm) || strings.HasPrefix(contentType, ContentTypeMultipart) {
+		return c.bindSources(v, bindTagForm)
 	}
 
-	switch {
-	case strings.HasPrefix(contentType, ContentTypeAppJSON):
-
-		// Acquire pooled buffer
-		buf := acquireJSONBuffer()
-		defer releaseJSONBuffer(buf)
-
-		// Read body content into buffer
-		if _, err := buf.ReadFrom(c.Request.Body); err != nil {
-			return err
-		}
-
-		// Reset the Request.Body after reading, enabling re-reads if needed
-		c.Request.Body = io.NopCloser(bytes.NewReader(buf.Bytes()))
-
-		return json.Unmarshal(buf.Bytes(), v)
-	case strings.HasPrefix(contentType, ContentTypeAppXML), strings.HasPrefix(contentType, ContentTypeTextXML):
+	// Check supported Content-Type
+	codec, _ := c.codecs().lookup(contentType)
+	if codec == nil {
+		return fmt.Errorf("%w: %s", errUnsupportedContentType, contentType)
+	}
 
-		// Acquire pooled buffer
-		buf := acquireXMLBuffer()
+	// Acquire pooled buffer
+	var buf *bytes.Buffer
+	if _, isXML := codec.(XMLCodec); isXML {
+		buf = acquireXMLBuffer()
 		defer releaseXMLBuffer(buf)
+	} else {
+		buf = acquireJSONBuffer()
+		defer releaseJSONBuffer(buf)
+	}
 
-		// Read body content into buffer
-		if _, err := buf.ReadFrom(c.Request.Body); err != nil {
-			return err
-		}
+	// Read body content into buffer
+	if _, err := buf.ReadFrom(c.Request.Body); err != nil {
+		return err
+	}
 
-		// Reset the Request.Body after reading, enabling re-reads if needed
-		c.Request.Body = io.NopCloser(bytes.NewReader(buf.Bytes()))
+	// Reset the Request.Body after reading, enabling re-reads if needed
+	c.Request.Body = io.NopCloser(bytes.NewReader(buf.Bytes()))
 
-		return xml.Unmarshal(buf.Bytes(), v)
-	default:
-		return fmt.Errorf("unsupported content type: %s", contentType)
-	}
+	return codec.Unmarshal(buf.Bytes(), v)
 }
 
 // extractParamsPattern extracts the fixed path and dynamic parameters from a given route pattern.
@@ -981,7 +1047,7 @@ func extractParamsGet(q *Quick, pathTmp, paramsPath string, handlerFunc HandleFu
 		// Fill the pooled context with request-specific data
 		ctx.Response = w
 		ctx.Request = req
-		ctx.Params = cval.ParamsMap
+		ctx.setParams(cval.params)
 		ctx.App = q
 
 		// Initialize Query and Headers maps properly
@@ -1040,7 +1106,7 @@ func extractParamsHead(q *Quick, pathTmp, paramsPath string, handlerFunc HandleF
 		// Fill the pooled context with request-specific data
 		ctx.Response = w
 		ctx.Request = req
-		ctx.Params = cval.ParamsMap
+		ctx.setParams(cval.params)
 		ctx.App = q
 
 		// Initialize Query and Headers maps properly
@@ -1116,7 +1182,7 @@ func extractParamsPost(q *Quick, handlerFunc HandleFunc) http.HandlerFunc {
 		ctx.Response = w
 		ctx.Request = req
 		ctx.bodyByte = bodyBytes
-		ctx.Params = cval.ParamsMap
+		ctx.setParams(cval.params)
 		ctx.MoreRequests = q.config.MoreRequests
 
 		// Reset `Request.Body` with the new bodyReader to allow re-reading
@@ -1179,7 +1245,7 @@ func extractParamsPut(q *Quick, handlerFunc HandleFunc) http.HandlerFunc {
 		ctx.Response = w
 		ctx.Request = req
 		ctx.bodyByte = bodyBytes
-		ctx.Params = cval.ParamsMap
+		ctx.setParams(cval.params)
 		ctx.MoreRequests = q.config.MoreRequests
 
 		// Reset `Request.Body` with the new bodyReader to allow re-reading
@@ -1224,7 +1290,7 @@ func extractParamsDelete(q *Quick, handlerFunc HandleFunc) http.HandlerFunc {
 		// Populate the Ctx with relevant data
 		ctx.Response = w
 		ctx.Request = req
-		ctx.Params = cval.ParamsMap
+		ctx.setParams(cval.params)
 		ctx.MoreRequests = q.config.MoreRequests
 
 		ctx.App = q
@@ -1236,9 +1302,8 @@ func extractParamsDelete(q *Quick, handlerFunc HandleFunc) http.HandlerFunc {
 
 // execHandleFunc executes the provided handler function and handles any errors that occur.
 //
-// If the handler returns a custom *Error (created with quick.NewError), this function
-// responds with the appropriate status code and a JSON body containing the error details.
-// For any other errors, it defaults to HTTP 500 with a plain text error message.
+// Errors returned by the handler are rendered by the handler set with
+// `SetErrorHandler`, or by `DefaultErrorHandler` when none is set.
 //
 // Parameters:
 //   - c *Ctx: The Quick context instance containing request and response data.
@@ -1259,21 +1324,176 @@ func extractParamsDelete(q *Quick, handlerFunc HandleFunc) http.HandlerFunc {
 //	// Content-Type: application/json
 //	// {"message":"Custom error message","code":782}
 func execHandleFunc(c *Ctx, handleFunc HandleFunc) {
-	err := handleFunc(c)
-	if err != nil {
-		var qerr *Error
-		if errors.As(err, &qerr) {
-			// Responds with status code and custom JSON
-			c.Set("Content-Type", "application/json")
-			c.Status(qerr.Code).JSON(qerr)
-			return
+	if err := handleFunc(c); err != nil {
+		c.handleError(err)
+	}
+}
+
+// DefaultErrorHandler is the error handler used when SetErrorHandler was not called.
+//
+// If the error is ValidationErrors (returned by Bind, BodyParser or Validate), it is
+// rendered as a 422 problem listing every invalid field in the "errors" member.
+// If the error is a *BindError (returned by the Bind* methods), it is rendered as a 400 problem.
+// If the error is a *Problem (created with quick.NewProblem), it is rendered as
+// `application/problem+json` or `application/problem+xml` depending on the Accept header.
+// If the error is a custom *Error (created with quick.NewError), it responds with the
+// appropriate status code and a JSON body containing the error details.
+// For any other errors, it defaults to HTTP 500 with a plain text error message.
+//
+// Custom error handlers can call it to keep the default rendering for some errors.
+//
+// Example Usage:
+//
+//	q := quick.New()
+//	q.SetErrorHandler(func(c *quick.Ctx, err error) error {
+//	    log.Printf("%s %s: %v", c.Method(), c.Path(), err)
+//	    return quick.DefaultErrorHandler(c, err)
+//	})
+func DefaultErrorHandler(c *Ctx, err error) error {
+	var verrs ValidationErrors
+	if errors.As(err, &verrs) {
+		return c.Problem(verrs.Problem())
+	}
+
+	var berr *BindError
+	if errors.As(err, &berr) {
+		return c.Problem(berr.Problem())
+	}
+
+	var prob *Problem
+	if errors.As(err, &prob) {
+		return c.Problem(prob)
+	}
+
+	var qerr *Error
+	if errors.As(err, &qerr) {
+		// Responds with status code and custom JSON
+		c.Set("Content-Type", "application/json")
+		return c.Status(qerr.Code).JSON(qerr)
+	}
+	// Fallback: plain text, status 500
+	c.Set("Content-Type", "text/plain; charset=utf-8")
+	return c.Status(http.StatusInternalServerError).SendString(err.Error())
+}
+
+// handleError renders err with the error handler configured in the App.
+//
+// If the error handler itself fails, a generic 500 response is sent
+// so no internal error text is leaked to the client.
+//
+// Parameters:
+//   - err error: The error returned by a handler.
+func (c *Ctx) handleError(err error) {
+	handler := DefaultErrorHandler
+	if c.App != nil && c.App.errorHandler != nil {
+		handler = c.App.errorHandler
+	}
+
+	if herr := handler(c, err); herr != nil {
+		http.Error(c.Response, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+// HandleError renders err with the error handler of the app serving the
+// request, as if a handler had returned it.
+//
+// Middlewares written for net/http use it to answer with the same format as the
+// rest of the app. c.App is used when set; otherwise the app is found from the
+// request, so a bare &quick.Ctx{Response: w, Request: r} works too. Without an
+// app, DefaultErrorHandler renders err.
+//
+// Example Usage:
+//
+//	c := &quick.Ctx{Response: w, Request: r}
+//	if !allowed(r) {
+//	    quick.HandleError(c, quick.NewError(quick.StatusForbidden))
+//	    return
+//	}
+func HandleError(c *Ctx, err error) {
+	if c.App == nil && c.Request != nil {
+		if v, ok := c.Request.Context().Value(myContextKey).(ctxServeHttp); ok {
+			c.App = v.app
 		}
-		// Fallback: plain text, status 500
-		c.Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	c.handleError(err)
+}
+
+// SetErrorHandler sets the handler that renders errors returned by handlers.
+//
+// It replaces DefaultErrorHandler, which custom handlers can still call for the
+// errors they do not render themselves. Call it before serving requests.
+//
+// Example Usage:
+//
+//	q.SetErrorHandler(func(c *quick.Ctx, err error) error {
+//	    log.Printf("%s %s: %v", c.Method(), c.Path(), err)
+//	    return quick.DefaultErrorHandler(c, err)
+//	})
+func (q *Quick) SetErrorHandler(handler func(*Ctx, error) error) {
+	q.errorHandler = handler
+}
+
+// SetNotFoundHandler sets the handler called when no route matches the request.
+//
+// The status is preset to 404 when it runs, and errors it returns are rendered by
+// the error handler. A nil handler restores the package-level NotFound.
+//
+// Example Usage:
+//
+//	q.SetNotFoundHandler(func(c *quick.Ctx) error {
+//	    return c.JSON(quick.M{"error": "not found", "path": c.Path()})
+//	})
+func (q *Quick) SetNotFoundHandler(handler HandleFunc) {
+	q.notFoundHandler = handler
+}
+
+// SetMethodNotAllowedHandler sets the handler called when the path matches routes
+// of other methods.
+//
+// The Allow header is set and the status preset to 405 when it runs. A nil handler
+// restores the package-level MethodNotAllowed.
+//
+// Example Usage:
+//
+//	q.SetMethodNotAllowedHandler(func(c *quick.Ctx) error {
+//	    return quick.NewError(quick.StatusMethodNotAllowed, "use "+c.Response.Header().Get("Allow"))
+//	})
+func (q *Quick) SetMethodNotAllowedHandler(handler HandleFunc) {
+	q.methodNotAllowedHandler = handler
+}
+
+// notFound answers a request that did not match any route.
+//
+// It runs the handler set with SetNotFoundHandler, with the status preset to 404,
+// or falls back to the package-level NotFound.
+//
+// Parameters:
+//   - c *Ctx: The context of the unmatched request.
+func (q *Quick) notFound(c *Ctx) {
+	if q.notFoundHandler == nil {
+		NotFound(c.Response, c.Request)
+		return
+	}
+	c.Status(StatusNotFound)
+	execHandleFunc(c, q.notFoundHandler)
+}
 
-		// #nosec G104
-		c.Status(http.StatusInternalServerError).SendString(err.Error())
+// methodNotAllowed answers a request whose path matched routes of other methods.
+//
+// It runs the handler set with SetMethodNotAllowedHandler, with the Allow header
+// set and the status preset to 405, or falls back to the package-level MethodNotAllowed.
+//
+// Parameters:
+//   - c *Ctx: The context of the request.
+//   - allow []string: The methods registered for the requested path.
+func (q *Quick) methodNotAllowed(c *Ctx, allow []string) {
+	if q.methodNotAllowedHandler == nil {
+		MethodNotAllowed(c.Response, c.Request, allow)
+		return
 	}
+	c.Set("Allow", strings.Join(allow, ", "))
+	c.Status(StatusMethodNotAllowed)
+	execHandleFunc(c, q.methodNotAllowedHandler)
 }
 
 // extractBodyBytes reads the entire request body into a pooled buffer, then
@@ -1427,7 +1647,7 @@ func convertQuickToHttpHandler(q *Quick, h Handler) http.Handler {
 		//c := &Ctx{Response: w, Request: r, App: q}
 		c := newCtx(w, r, q)
 		if err := h.ServeQuick(c); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			c.handleError(err)
 		}
 	})
 }
@@ -1466,20 +1686,42 @@ func convertToQuickHandler(h http.Handler) HandlerFunc {
 
 // appendRoute registers a new route in the Quick router and applies middlewares.
 //
-// This function ensures that the given route's handler is wrapped with all registered
-// middlewares before being stored in the router. It optimizes performance by applying
+// This function compiles the route pattern into the routing tree of its method and
+// ensures that the given route's handler is wrapped with all registered middlewares
+// before being stored in the router. It optimizes performance by applying
 // middleware only once during route registration instead of at runtime.
 //
+// Duplicated routes (same method and pattern) and invalid patterns are ignored
+// with a warning.
+//
 // Parameters:
 //   - route *Route: The route to be registered in the Quick router.
 //
+// Returns:
+//   - bool: true if the route was registered, false if it was ignored.
+//
 // Example Usage:
 //
 //	// This function is automatically called when registering a new route.
-func (q *Quick) appendRoute(route *Route) {
+func (q *Quick) appendRoute(route *Route) bool {
+	if q.router == nil {
+		q.router = newRouter()
+	}
+
+	pattern := route.Pattern
+	if len(pattern) == 0 {
+		pattern = route.Path
+	}
+
+	if err := q.router.add(route.Method, pattern, route); err != nil {
+		fmt.Printf("Warning: Route '%s %s' %v.\n", route.Method, pattern, err)
+		return false
+	}
+
 	route.handler = q.mwWrapper(route.handler).ServeHTTP
 	//q.routes = append(q.routes, *route)
 	q.routes = append(q.routes, route)
+	return true
 }
 
 // Header retrieves the HTTP headers from the response writer.
@@ -1503,7 +1745,12 @@ func (rw *pooledResponseWriter) Header() http.Header {
 // leveraging a **pooled response writer** and **context pooling** to minimize memory
 // allocations and improve performance.
 //
+// Routes are looked up in the routing tree of the request method, so the cost of
+// matching depends on the depth of the path and not on the number of registered routes.
+//
 // If the request method is `OPTIONS`, it is handled separately via `handleOptions`.
+// If the path matches routes registered for other methods, the function responds
+// with `405 Method Not Allowed` and an `Allow` header listing those methods.
 // If no matching route is found, the function responds with `404 Not Found`.
 //
 // Example Usage:
@@ -1524,49 +1771,46 @@ func (q *Quick) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	ctx := newCtx(rw, req, q) // creates a new, clean instance of the context
 	defer releaseCtx(ctx)     // Returns it to the pool
 
-	for i := range q.routes {
-		var requestURI = path.Clean(req.URL.Path)
-		var patternUri = q.routes[i].Pattern
-
-		if q.routes[i].Method != req.Method {
-			continue
-		}
-
-		if len(patternUri) == 0 {
-			patternUri = q.routes[i].Path
-		}
+	// Parameters are collected into a pooled list, without allocations.
+	ps := acquireParams()
+	defer releaseParams(ps)
 
-		paramsMap, isValid := createParamsAndValid(requestURI, path.Clean(patternUri))
-
-		if !isValid {
-			continue // This route doesn't match, continue checking.
-		}
-
-		var c = ctxServeHttp{
-			Path:      requestURI,
-			ParamsMap: paramsMap,
-			Method:    q.routes[i].Method,
+	var requestURI = path.Clean(req.URL.Path)
+	route := q.router.find(req.Method, requestURI, ps)
+	if route == nil {
+		// The path exists for other methods: send a 405 with the Allow header.
+		if allow := q.router.allowed(requestURI); len(allow) > 0 {
+			q.methodNotAllowed(ctx, allow)
+			return
 		}
-		req = req.WithContext(context.WithValue(req.Context(), myContextKey, c))
-
-		// Pass the rw (pooledResponseWriter) to the handler
-		q.routes[i].handler(rw, req)
+		// If no route matches, send a 404 response.
+		q.notFound(ctx)
 		return
 	}
 
-	// If no route matches, send a 404 response.
-	NotFound(rw, req)
+	// The params are copied out of the pooled list, which is reused once
+	// ServeHTTP returns, because a handler may outlive the request (timeout).
+	var c = ctxServeHttp{
+		Path:   requestURI,
+		Method: route.Method,
+		params: slices.Clone(ps.list),
+		app:    q,
+	}
+	req = req.WithContext(context.WithValue(req.Context(), myContextKey, c))
+
+	// Pass the rw (pooledResponseWriter) to the handler
+	route.handler(rw, req)
 }
 
 // createParamsAndValid extracts dynamic parameters from a request URI and validates the pattern.
 //
-// This function compares the request URI with the registered pattern and extracts
+// This function compares the request URI with a single pattern and extracts
 // route parameters such as `:id` or `{id:[0-9]+}` dynamically.
 //
 // Example Usage:
-// This function is internally used by `ServeHTTP()` to verify if a request matches a
-// registered route pattern. If it does, it extracts the dynamic parameters and returns
-// them as a map.
+// This function matches one pattern at a time and compiles regex segments on each call.
+// Request routing is done by the routing tree (see quick_router.go); this helper
+// is kept for one-off checks of a path against a pattern.
 func createParamsAndValid(reqURI, patternURI string) (map[string]string, bool) {
 	params := make(map[string]string)
 	var builder strings.Builder
@@ -1717,6 +1961,7 @@ func (q *Quick) HandlersCount() uint32 {
 //   - The function automatically trims trailing slashes from `route`.
 //   - If an invalid parameter is provided, the function panics.
 //   - When using an embedded filesystem, files are served directly from memory.
+//   - Files carry an ETag, and conditional requests are answered with 304 or 412.
 func (q *Quick) Static(route string, dirOrFS any) {
 	route = strings.TrimSuffix(route, "/")
 
@@ -1725,11 +1970,11 @@ func (q *Quick) Static(route string, dirOrFS any) {
 	// check of dirOrFS is a embed.FS
 	switch v := dirOrFS.(type) {
 	case string:
-		fileServer = http.FileServer(http.Dir(v))
+		fileServer = staticValidators(http.Dir(v), http.FileServer(http.Dir(v)))
 	case embed.FS:
 		q.embedFS = v
 		q.hasEmbed = true
-		fileServer = http.FileServer(http.FS(v))
+		fileServer = staticValidators(http.FS(v), http.FileServer(http.FS(v)))
 	default:
 		panic("Static: invalid parameter, must be string or embed.FS")
 	}
@@ -2154,6 +2399,17 @@ func (q *Quick) startServerWithGracefulShutdown(listener net.Listener, certFile,
 	}
 }
 
+// MethodNotAllowed sends a 405 Method Not Allowed response.
+// The Allow header is filled with the methods registered for the requested path.
+//
+// Example Usage:
+//
+//	quick.MethodNotAllowed(w, r, []string{quick.MethodGet, quick.MethodOptions})
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request, allow []string) {
+	w.Header().Set("Allow", strings.Join(allow, ", "))
+	http.Error(w, "405 method not allowed", http.StatusMethodNotAllowed)
+}
+
 // NotFound sends a 404 Not Found response with optional custom body.
 // It wraps http.NotFound and provides a Quick-style naming.
 func NotFound(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,523 @@
+// Package quick provides a high-performance HTTP framework for building web applications in Go.
+//
+// This file implements binding of path parameters, query strings, headers,
+// cookies and form values into structs, driven by struct tags:
+//
+//	type ListUsers struct {
+//	    Org     string        `param:"org"`
+//	    Page    int           `query:"page"`
+//	    Tags    []string      `query:"tag"`           // ?tag=a&tag=b
+//	    Since   *time.Time    `query:"since"`         // optional, RFC 3339
+//	    Day     time.Time     `query:"day" layout:"2006-01-02"`
+//	    Timeout time.Duration `header:"X-Timeout"`    // "1.5s"
+//	    Token   string        `cookie:"token"`
+//	    Name    string        `form:"name" validate:"required"`
+//	}
+//
+// Supported field types: strings, ints, uints, floats, bools, time.Time,
+// time.Duration, slices of those (one element per repeated key), pointers
+// (allocated only when the key is present) and any type implementing
+// encoding.TextUnmarshaler. Form fields of type *UploadedFile or
+// []*UploadedFile receive the files of a multipart body.
+//
+// Values that cannot be converted produce a *BindError, which the default error
+// handler renders as a 400 Bad Request problem. After binding, the struct is
+// validated with its `validate` tags (see Validate).
+package quick
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"mime"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Struct tags read by the Bind* methods.
+const (
+	bindTagParam  = "param"
+	bindTagQuery  = "query"
+	bindTagHeader = "header"
+	bindTagCookie = "cookie"
+	bindTagForm   = "form"
+)
+
+// defaultMultipartMemory is the memory used to parse multipart forms
+// when no limit was set with FormFileLimit.
+const defaultMultipartMemory = 32 << 20 // 32MB
+
+// BindError reports a request value that could not be converted to the type
+// of its struct field.
+//
+// Fields:
+//   - Source: The tag that selected the value ("param", "query", "header", "cookie" or "form").
+//   - Key: The name of the parameter, header, cookie or form field.
+//   - Value: The raw value received.
+//   - Err: The conversion error.
+type BindError struct {
+	Source string
+	Key    string
+	Value  string
+	Err    error
+}
+
+// Error implements the error interface.
+func (e *BindError) Error() string {
+	return fmt.Sprintf("invalid %s %q: %v", bindSourceName(e.Source), e.Key, e.Err)
+}
+
+// Unwrap returns the conversion error.
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+// Problem converts the error into a 400 Bad Request problem.
+func (e *BindError) Problem() *Problem {
+	return ProblemBadRequest(e.Error()).Wrap(e)
+}
+
+// bindSourceName returns the human-readable name of a tag used in error messages.
+func bindSourceName(source string) string {
+	switch source {
+	case bindTagParam:
+		return "path parameter"
+	case bindTagQuery:
+		return "query parameter"
+	case bindTagForm:
+		return "form field"
+	}
+	return source
+}
+
+// BindParams binds route parameters into the `param` tagged fields of v.
+//
+// Parameters:
+//   - v: A pointer to the destination struct.
+//
+// Returns:
+//   - error: A *BindError (400) for invalid values, or ValidationErrors (422).
+//
+// Example Usage:
+//
+//	// GET /users/:id
+//	var in struct {
+//	    ID int `param:"id"`
+//	}
+//	if err := c.BindParams(&in); err != nil {
+//	    return err
+//	}
+func (c *Ctx) BindParams(v interface{}) error {
+	return c.bindTags(v, bindTagParam)
+}
+
+// BindQuery binds the query string into the `query` tagged fields of v.
+//
+// Repeated keys (?tag=a&tag=b) fill slice fields.
+//
+// Parameters:
+//   - v: A pointer to the destination struct.
+//
+// Returns:
+//   - error: A *BindError (400) for invalid values, or ValidationErrors (422).
+//
+// Example Usage:
+//
+//	var in struct {
+//	    Page int      `query:"page"`
+//	    Tags []string `query:"tag"`
+//	}
+//	if err := c.BindQuery(&in); err != nil {
+//	    return err
+//	}
+func (c *Ctx) BindQuery(v interface{}) error {
+	return c.bindTags(v, bindTagQuery)
+}
+
+// BindHeader binds request headers into the `header` tagged fields of v.
+//
+// Header names are matched case-insensitively.
+//
+// Parameters:
+//   - v: A pointer to the destination struct.
+//
+// Returns:
+//   - error: A *BindError (400) for invalid values, or ValidationErrors (422).
+//
+// Example Usage:
+//
+//	var in struct {
+//	    RequestID string `header:"X-Request-ID"`
+//	}
+//	if err := c.BindHeader(&in); err != nil {
+//	    return err
+//	}
+func (c *Ctx) BindHeader(v interface{}) error {
+	return c.bindTags(v, bindTagHeader)
+}
+
+// BindCookie binds request cookies into the `cookie` tagged fields of v.
+//
+// Parameters:
+//   - v: A pointer to the destination struct.
+//
+// Returns:
+//   - error: A *BindError (400) for invalid values, or ValidationErrors (422).
+func (c *Ctx) BindCookie(v interface{}) error {
+	return c.bindTags(v, bindTagCookie)
+}
+
+// BindForm binds the form body (application/x-www-form-urlencoded or
+// multipart/form-data) into the `form` tagged fields of v.
+//
+// Parameters:
+//   - v: A pointer to the destination struct.
+//
+// Returns:
+//   - error: A 400 *Problem if the form cannot be parsed, a *BindError (400)
+//     for invalid values, or ValidationErrors (422).
+//
+// Example Usage:
+//
+//	var in struct {
+//	    Name string `form:"name" validate:"required"`
+//	}
+//	if err := c.BindForm(&in); err != nil {
+//	    return err
+//	}
+func (c *Ctx) BindForm(v interface{}) error {
+	return c.bindTags(v, bindTagForm)
+}
+
+// BindAll binds every tagged field of v from its source in a single call:
+// `param`, `query`, `header`, `cookie` and `form`.
+//
+// A field may carry several tags. Sources are applied in the order form,
+// cookie, header, query, param, so path parameters take precedence.
+//
+// Parameters:
+//   - v: A pointer to the destination struct.
+//
+// Returns:
+//   - error: A *BindError (400) for invalid values, or ValidationErrors (422).
+//
+// Example Usage:
+//
+//	var in struct {
+//	    Org   string `param:"org"`
+//	    Page  int    `query:"page"`
+//	    Token string `header:"Authorization"`
+//	}
+//	if err := c.BindAll(&in); err != nil {
+//	    return err
+//	}
+func (c *Ctx) BindAll(v interface{}) error {
+	return c.bindTags(v, bindTagForm, bindTagCookie, bindTagHeader, bindTagQuery, bindTagParam)
+}
+
+// bindTags binds v from the given sources, in order, and validates the result.
+func (c *Ctx) bindTags(v interface{}, sources ...string) error {
+	if err := c.bindSources(v, sources...); err != nil {
+		return err
+	}
+	return Validate(v)
+}
+
+// bindSources binds v from the given sources, in order, without validating it.
+func (c *Ctx) bindSources(v interface{}, sources ...string) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("quick: bind destination must be a non-nil pointer to a struct")
+	}
+	rv = rv.Elem()
+	plan := bindPlanFor(rv.Type())
+
+	for _, source := range sources {
+		fields := plan.fields[source]
+		if len(fields) == 0 {
+			continue
+		}
+		lookup, err := c.bindLookup(source)
+		if err != nil {
+			return err
+		}
+		for _, f := range fields {
+			if f.file {
+				if err := c.bindFiles(rv.FieldByIndex(f.index), f.key); err != nil {
+					return &BindError{Source: source, Key: f.key, Err: err}
+				}
+				continue
+			}
+			values := lookup(f.key)
+			if len(values) == 0 {
+				continue
+			}
+			if err := setBindValue(rv.FieldByIndex(f.index), values, f.layout); err != nil {
+				if errors.Is(err, errUnsupportedBindType) {
+					return fmt.Errorf("quick: field %s: %w", f.name, err)
+				}
+				return &BindError{Source: source, Key: f.key, Value: values[0], Err: err}
+			}
+		}
+	}
+	return nil
+}
+
+// bindFiles fills a *UploadedFile or []*UploadedFile field with the multipart
+// files sent under key. The field is left unchanged when no file was sent.
+func (c *Ctx) bindFiles(fv reflect.Value, key string) error {
+	if c.Request.MultipartForm == nil || len(c.Request.MultipartForm.File[key]) == 0 {
+		return nil
+	}
+	headers := c.Request.MultipartForm.File[key]
+
+	if fv.Type() == uploadedFileType {
+		file, err := newUploadedFile(headers[0])
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(file))
+		return nil
+	}
+
+	files := make([]*UploadedFile, 0, len(headers))
+	for _, fh := range headers {
+		file, err := newUploadedFile(fh)
+		if err != nil {
+			return err
+		}
+		files = append(files, file)
+	}
+	fv.Set(reflect.ValueOf(files))
+	return nil
+}
+
+// bindLookup returns the function that reads the raw values of a source.
+func (c *Ctx) bindLookup(source string) (func(key string) []string, error) {
+	switch source {
+	case bindTagParam:
+		return func(key string) []string {
+			if val, ok := c.Params[key]; ok {
+				return []string{val}
+			}
+			return nil
+		}, nil
+
+	case bindTagQuery:
+		query := c.queryArgs()
+		return func(key string) []string { return query[key] }, nil
+
+	case bindTagHeader:
+		return c.Request.Header.Values, nil
+
+	case bindTagCookie:
+		cookies := c.Request.Cookies()
+		return func(key string) []string {
+			var values []string
+			for _, ck := range cookies {
+				if ck.Name == key {
+					values = append(values, ck.Value)
+				}
+			}
+			return values
+		}, nil
+
+	case bindTagForm:
+		if err := c.parseFormBody(); err != nil {
+			return nil, bindError(err)
+		}
+		form := c.Request.PostForm
+		return func(key string) []string { return form[key] }, nil
+	}
+	return nil, fmt.Errorf("quick: unknown bind source %q", source)
+}
+
+// parseFormBody parses the urlencoded or multipart request body into Request.PostForm.
+func (c *Ctx) parseFormBody() error {
+	mediaType, _, _ := mime.ParseMediaType(c.Request.Header.Get("Content-Type"))
+	if mediaType == ContentTypeMultipart {
+		size := c.uploadFileSize
+		if size == 0 {
+			size = defaultMultipartMemory
+		}
+		return c.Request.ParseMultipartForm(size)
+	}
+	return c.Request.ParseForm()
+}
+
+// bindField is a struct field bound from a single source.
+//
+// file marks *UploadedFile and []*UploadedFile fields, filled from multipart files.
+type bindField struct {
+	index  []int
+	name   string
+	key    string
+	layout string
+	file   bool
+}
+
+// bindPlan lists the bound fields of a struct type, by source tag.
+type bindPlan struct {
+	fields map[string][]bindField
+}
+
+// bindCache stores one plan per struct type, so tags are parsed only once.
+var bindCache sync.Map // map[reflect.Type]*bindPlan
+
+// bindPlanFor returns the plan of t, building it on first use.
+func bindPlanFor(t reflect.Type) *bindPlan {
+	if plan, ok := bindCache.Load(t); ok {
+		return plan.(*bindPlan)
+	}
+	plan := &bindPlan{fields: make(map[string][]bindField)}
+	collectBindFields(t, nil, plan)
+	actual, _ := bindCache.LoadOrStore(t, plan)
+	return actual.(*bindPlan)
+}
+
+// collectBindFields walks t, including embedded structs, and records tagged fields.
+func collectBindFields(t reflect.Type, index []int, plan *bindPlan) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		idx := append(append([]int(nil), index...), i)
+
+		tagged := false
+		for _, source := range []string{bindTagParam, bindTagQuery, bindTagHeader, bindTagCookie, bindTagForm} {
+			key, _, _ := strings.Cut(sf.Tag.Get(source), ",")
+			if key == "" || key == "-" || !sf.IsExported() {
+				continue
+			}
+			tagged = true
+			plan.fields[source] = append(plan.fields[source], bindField{
+				index:  idx,
+				name:   sf.Name,
+				key:    key,
+				layout: sf.Tag.Get("layout"),
+				file:   source == bindTagForm && (sf.Type == uploadedFileType || sf.Type == uploadedFilesType),
+			})
+		}
+
+		if !tagged && sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			collectBindFields(sf.Type, idx, plan)
+		}
+	}
+}
+
+// errUnsupportedBindType is returned for field types that cannot be bound.
+var errUnsupportedBindType = errors.New("unsupported field type")
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	uploadedFileType    = reflect.TypeOf((*UploadedFile)(nil))
+	uploadedFilesType   = reflect.TypeOf([]*UploadedFile(nil))
+)
+
+// setBindValue converts values into fv.
+//
+// Slices receive every value; other types receive the first one.
+// Empty values leave non-string fields unchanged.
+func setBindValue(fv reflect.Value, values []string, layout string) error {
+	if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 && !fv.Addr().Type().Implements(textUnmarshalerType) {
+		slice := reflect.MakeSlice(fv.Type(), 0, len(values))
+		for _, val := range values {
+			elem := reflect.New(fv.Type().Elem()).Elem()
+			if err := setBindString(elem, val, layout); err != nil {
+				return err
+			}
+			slice = reflect.Append(slice, elem)
+		}
+		fv.Set(slice)
+		return nil
+	}
+	return setBindString(fv, values[0], layout)
+}
+
+// setBindString converts a single raw value into fv.
+func setBindString(fv reflect.Value, val string, layout string) error {
+	if fv.Kind() == reflect.Pointer {
+		elem := reflect.New(fv.Type().Elem())
+		if err := setBindString(elem.Elem(), val, layout); err != nil {
+			return err
+		}
+		fv.Set(elem)
+		return nil
+	}
+
+	if val == "" && fv.Kind() != reflect.String {
+		return nil
+	}
+
+	switch fv.Type() {
+	case timeType:
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		t, err := time.Parse(layout, val)
+		if err != nil {
+			return fmt.Errorf("%q is not a valid time (layout %s)", val, layout)
+		}
+		fv.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			return fmt.Errorf("%q is not a valid duration", val)
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+
+	if fv.CanAddr() && fv.Addr().Type().Implements(textUnmarshalerType) {
+		return fv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(val))
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(val)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return fmt.Errorf("%q is not a valid boolean", val)
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(val, 10, fv.Type().Bits())
+		if err != nil {
+			return numError(val, "integer", err)
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(val, 10, fv.Type().Bits())
+		if err != nil {
+			return numError(val, "unsigned integer", err)
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(val, fv.Type().Bits())
+		if err != nil {
+			return numError(val, "number", err)
+		}
+		fv.SetFloat(n)
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("%w %s", errUnsupportedBindType, fv.Type())
+		}
+		fv.SetBytes([]byte(val))
+	default:
+		return fmt.Errorf("%w %s", errUnsupportedBindType, fv.Type())
+	}
+	return nil
+}
+
+// numError describes a failed numeric conversion without the strconv prefix.
+func numError(val, kind string, err error) error {
+	if errors.Is(err, strconv.ErrRange) {
+		return fmt.Errorf("%q is out of range", val)
+	}
+	return fmt.Errorf("%q is not a valid %s", val, kind)
+}
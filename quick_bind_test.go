@@ -0,0 +1,218 @@
+// Package quick provides a high-performance, minimalistic web framework for Go.
+//
+// This file contains **unit tests** for binding params, query, headers, cookies and forms into structs.
+//
+// 📌 To run all unit tests, use:
+//
+//	$ go test -v ./...
+//	$ go test -v -run ^TestBind
+package quick
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// bindPaging is embedded to verify that embedded structs are walked.
+type bindPaging struct {
+	Page  int `query:"page"`
+	Limit int `query:"limit"`
+}
+
+type bindRequest struct {
+	bindPaging
+	Org     string        `param:"org"`
+	ID      uint64        `param:"id" query:"id"`
+	Tags    []string      `query:"tag"`
+	Scores  []float64     `query:"score"`
+	Active  bool          `query:"active"`
+	Since   *time.Time    `query:"since"`
+	Day     time.Time     `query:"day" layout:"2006-01-02"`
+	Missing *int          `query:"missing"`
+	Timeout time.Duration `header:"X-Timeout"`
+	IP      net.IP        `header:"X-Client-IP"`
+	Token   string        `cookie:"token"`
+	Name    string        `form:"name"`
+}
+
+// TestBindAll verifies conversion of every supported type and source precedence.
+//
+// Run with:
+//
+//	go test -v -run ^TestBindAll
+func TestBindAll(t *testing.T) {
+	var got bindRequest
+	q := New()
+	q.Post("/orgs/:org/items/:id", func(c *Ctx) error {
+		got = bindRequest{bindPaging: bindPaging{Limit: 20}}
+		return c.BindAll(&got)
+	})
+
+	query := "page=2&id=99&tag=a&tag=b&score=1.5&score=2&active=true&since=2024-05-01T10:00:00Z&day=2024-05-02"
+	form := url.Values{"name": {"Jeff"}}
+	req := httptest.NewRequest(MethodPost, "/orgs/quick/items/7?"+query, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("x-timeout", "1.5s")
+	req.Header.Set("X-Client-IP", "10.0.0.1")
+	req.AddCookie(&http.Cookie{Name: "token", Value: "abc"})
+	rec := httptest.NewRecorder()
+	q.ServeHTTP(rec, req)
+
+	if rec.Code != StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	since := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	want := bindRequest{
+		bindPaging: bindPaging{Page: 2, Limit: 20},
+		Org:        "quick",
+		ID:         7, // param takes precedence over query
+		Tags:       []string{"a", "b"},
+		Scores:     []float64{1.5, 2},
+		Active:     true,
+		Since:      &since,
+		Day:        time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC),
+		Timeout:    1500 * time.Millisecond,
+		IP:         net.ParseIP("10.0.0.1"),
+		Token:      "abc",
+		Name:       "Jeff",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected binding\nexpected %+v\ngot      %+v", want, got)
+	}
+}
+
+// TestBindErrors verifies that invalid values produce a *BindError answered with 400.
+//
+// Run with:
+//
+//	go test -v -run ^TestBindErrors
+func TestBindErrors(t *testing.T) {
+	type in struct {
+		Page int `query:"page" validate:"min=1"`
+	}
+
+	var bindErr error
+	q := New()
+	q.Get("/items", func(c *Ctx) error {
+		var v in
+		bindErr = c.BindQuery(&v)
+		return bindErr
+	})
+
+	tests := []struct {
+		query string
+		code  int
+		msg   string
+	}{
+		{"page=abc", StatusBadRequest, `invalid query parameter "page": "abc" is not a valid integer`},
+		{"page=99999999999999999999", StatusBadRequest, `invalid query parameter "page": "99999999999999999999" is out of range`},
+		{"page=0", StatusUnprocessableEntity, "page must be at least 1"},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		q.ServeHTTP(rec, httptest.NewRequest(MethodGet, "/items?"+tt.query, nil))
+		if rec.Code != tt.code {
+			t.Errorf("%s: expected %d, got %d", tt.query, tt.code, rec.Code)
+		}
+		if bindErr == nil || bindErr.Error() != tt.msg {
+			t.Errorf("%s: expected error %q, got %v", tt.query, tt.msg, bindErr)
+		}
+	}
+
+	var berr *BindError
+	rec := httptest.NewRecorder()
+	q.ServeHTTP(rec, httptest.NewRequest(MethodGet, "/items?page=x", nil))
+	if !errors.As(bindErr, &berr) || berr.Source != "query" || berr.Key != "page" || berr.Value != "x" {
+		t.Errorf("unexpected bind error: %#v", bindErr)
+	}
+
+	c := &Ctx{Request: httptest.NewRequest(MethodGet, "/?ch=1", nil)}
+	var unsupported struct {
+		Ch chan int `query:"ch"`
+	}
+	if err := c.BindQuery(&unsupported); err == nil || errors.As(err, &berr) && berr.Key == "ch" {
+		t.Errorf("expected a plain error for unsupported field type, got %v", err)
+	}
+	if err := c.BindQuery(unsupported); err == nil {
+		t.Error("expected an error for non-pointer destination")
+	}
+}
+
+// TestBindFormBodies verifies that Bind decodes urlencoded and multipart bodies,
+// including uploaded files.
+//
+// Run with:
+//
+//	go test -v -run ^TestBindFormBodies
+func TestBindFormBodies(t *testing.T) {
+	type profile struct {
+		Name   string          `form:"name" validate:"required"`
+		Age    int             `form:"age"`
+		Langs  []string        `form:"lang"`
+		Avatar *UploadedFile   `form:"avatar"`
+		Docs   []*UploadedFile `form:"docs"`
+	}
+
+	var got profile
+	q := New()
+	q.Post("/profile", func(c *Ctx) error {
+		got = profile{}
+		return c.Bind(&got)
+	})
+
+	// urlencoded
+	form := url.Values{"name": {"Jeff"}, "age": {"40"}, "lang": {"go", "c"}}
+	req := httptest.NewRequest(MethodPost, "/profile", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", ContentTypeForm)
+	rec := httptest.NewRecorder()
+	q.ServeHTTP(rec, req)
+	if rec.Code != StatusOK || got.Name != "Jeff" || got.Age != 40 || !reflect.DeepEqual(got.Langs, []string{"go", "c"}) {
+		t.Fatalf("urlencoded: unexpected result %d %+v", rec.Code, got)
+	}
+
+	// multipart with files
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("name", "Ana")
+	fw, _ := mw.CreateFormFile("avatar", "me.txt")
+	fw.Write([]byte("avatar"))
+	for _, name := range []string{"a.txt", "b.txt"} {
+		fw, _ := mw.CreateFormFile("docs", name)
+		fw.Write([]byte(name))
+	}
+	mw.Close()
+
+	req = httptest.NewRequest(MethodPost, "/profile", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rec = httptest.NewRecorder()
+	q.ServeHTTP(rec, req)
+	if rec.Code != StatusOK {
+		t.Fatalf("multipart: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if got.Name != "Ana" || got.Avatar == nil || string(got.Avatar.Info.Bytes) != "avatar" || got.Avatar.Info.Filename != "me.txt" {
+		t.Errorf("multipart: unexpected avatar binding %+v", got)
+	}
+	if len(got.Docs) != 2 || got.Docs[1].Info.Filename != "b.txt" {
+		t.Errorf("multipart: expected 2 docs, got %+v", got.Docs)
+	}
+
+	// validation still applies to form bodies
+	req = httptest.NewRequest(MethodPost, "/profile", strings.NewReader("age=1"))
+	req.Header.Set("Content-Type", ContentTypeForm)
+	rec = httptest.NewRecorder()
+	q.ServeHTTP(rec, req)
+	if rec.Code != StatusUnprocessableEntity {
+		t.Errorf("expected 422 for missing name, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,216 @@
+// Package quick provides a high-performance HTTP framework for building web applications in Go.
+//
+// This file implements the codec registry used to decode request bodies and
+// encode responses.
+//
+// Codecs are registered by media type with `Quick.RegisterCodec`. JSON (application/json)
+// and XML (application/xml, text/xml) are registered by default and can be
+// replaced, for example to plug a faster JSON library. Any other format
+// (MessagePack, CBOR, YAML, protobuf...) is added the same way:
+//
+//	q := quick.New()
+//	q.RegisterCodec("application/msgpack", msgpackCodec{})
+//
+// `Ctx.Bind` and `Ctx.BodyParser` choose the decoder from the Content-Type
+// header, and `Ctx.Render` chooses the encoder from the Accept header.
+package quick
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"mime"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Codec marshals and unmarshals values for one media type.
+//
+// Implementations must be safe for concurrent use.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONCodec is the default codec for application/json, based on encoding/json.
+type JSONCodec struct{}
+
+// Marshal encodes v as JSON.
+func (JSONCodec) Marshal(v any) ([]byte, error) { return json.Marshal(v) }
+
+// Unmarshal decodes JSON data into v.
+func (JSONCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+// XMLCodec is the default codec for application/xml and text/xml, based on encoding/xml.
+type XMLCodec struct{}
+
+// Marshal encodes v as XML.
+func (XMLCodec) Marshal(v any) ([]byte, error) { return xml.Marshal(v) }
+
+// Unmarshal decodes XML data into v.
+func (XMLCodec) Unmarshal(data []byte, v any) error { return xml.Unmarshal(data, v) }
+
+// codecRegistry holds the codecs of an application.
+//
+// Fields:
+//   - codecs: Codecs indexed by lowercased media type.
+//   - types: Registered media types in preference order, used by Render.
+type codecRegistry struct {
+	codecs map[string]Codec
+	types  []string
+}
+
+// defaultCodecs is used by contexts that are not attached to an application.
+var defaultCodecs = newCodecRegistry()
+
+// builtinTypes are the media types registered by default, in preference order.
+var builtinTypes = []string{ContentTypeAppJSON, ContentTypeAppXML, ContentTypeTextXML}
+
+// newCodecRegistry returns a registry with the default codecs.
+//
+// Returns:
+//   - *codecRegistry: The registry used by the application.
+func newCodecRegistry() *codecRegistry {
+	r := &codecRegistry{codecs: map[string]Codec{
+		ContentTypeAppJSON: JSONCodec{},
+		ContentTypeAppXML:  XMLCodec{},
+		ContentTypeTextXML: XMLCodec{},
+	}}
+	r.sortTypes()
+	return r
+}
+
+// register adds or replaces the codec of mediaType; a nil codec removes it.
+func (r *codecRegistry) register(mediaType string, codec Codec) {
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	if codec == nil {
+		delete(r.codecs, mediaType)
+	} else {
+		r.codecs[mediaType] = codec
+	}
+	r.sortTypes()
+}
+
+// sortTypes lists the registered media types for Render: the default media
+// types keep their order (JSON first) and custom ones follow alphabetically.
+func (r *codecRegistry) sortTypes() {
+	extra := make([]string, 0, len(r.codecs))
+	for mediaType := range r.codecs {
+		if !slices.Contains(builtinTypes, mediaType) {
+			extra = append(extra, mediaType)
+		}
+	}
+	sort.Strings(extra)
+
+	r.types = r.types[:0]
+	for _, mediaType := range append(slices.Clone(builtinTypes), extra...) {
+		if _, ok := r.codecs[mediaType]; ok {
+			r.types = append(r.types, mediaType)
+		}
+	}
+}
+
+// RegisterCodec registers the codec of a media type for Bind, BodyParser,
+// Render, JSON and XML. A codec for application/json or application/xml
+// replaces the default one, and a nil codec removes the media type.
+//
+// Codecs are not safe to register while requests are served, so register
+// them before Listen.
+//
+// Example Usage:
+//
+//	q := quick.New()
+//	q.RegisterCodec("application/msgpack", msgpackCodec{})
+func (q *Quick) RegisterCodec(mediaType string, codec Codec) {
+	q.codecs.register(mediaType, codec)
+}
+
+// lookup returns the codec for a Content-Type header value.
+//
+// Parameters are ignored, and structured syntax suffixes fall back to their
+// base format (e.g. "application/vnd.api+json" uses the application/json codec).
+//
+// Parameters:
+//   - contentType: The Content-Type header value.
+//
+// Returns:
+//   - Codec: The codec, or nil if the media type is not registered.
+//   - string: The media type without parameters.
+func (r *codecRegistry) lookup(contentType string) (Codec, string) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, _, _ = strings.Cut(contentType, ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	}
+	if codec, ok := r.codecs[mediaType]; ok {
+		return codec, mediaType
+	}
+	if i := strings.LastIndexByte(mediaType, '+'); i >= 0 {
+		switch mediaType[i+1:] {
+		case "json":
+			return r.codecs[ContentTypeAppJSON], mediaType
+		case "xml":
+			return r.codecs[ContentTypeAppXML], mediaType
+		}
+	}
+	return nil, mediaType
+}
+
+// codecs returns the codec registry of the application, or the default one.
+func (c *Ctx) codecs() *codecRegistry {
+	if c.App != nil && c.App.codecs != nil {
+		return c.App.codecs
+	}
+	return defaultCodecs
+}
+
+// customCodec returns the codec registered for mediaType when it replaces the
+// built-in encoder, or nil when the built-in pooled encoder can be used.
+func (c *Ctx) customCodec(mediaType string) Codec {
+	switch codec := c.codecs().codecs[mediaType].(type) {
+	case nil, JSONCodec, XMLCodec:
+		return nil
+	default:
+		return codec
+	}
+}
+
+// Render encodes v with the codec that best matches the Accept header and
+// writes it as the response.
+//
+// The registered media types are offered in order (JSON, XML, then custom ones),
+// so JSON is used when the client accepts anything. The Content-Type header is
+// set to the chosen media type.
+//
+// Parameters:
+//   - v: The value to encode.
+//
+// Returns:
+//   - error: A 406 *Problem if no registered codec is acceptable, or the encoding error.
+//
+// Example Usage:
+//
+//	q.Get("/users/:id", func(c *quick.Ctx) error {
+//	    return c.Render(user) // JSON, XML or any registered format
+//	})
+func (c *Ctx) Render(v any) error {
+	reg := c.codecs()
+	mediaType := negotiate(c.Request.Header.Get("Accept"), reg.types, mediaRangeMatch)
+	if mediaType == "" {
+		return ProblemNotAcceptable("supported media types: " + strings.Join(reg.types, ", "))
+	}
+
+	c.Set("Content-Type", mediaType)
+	switch reg.codecs[mediaType].(type) {
+	case JSONCodec:
+		return c.JSON(v)
+	case XMLCodec:
+		return c.XML(v)
+	}
+
+	b, err := reg.codecs[mediaType].Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.writeResponse(b)
+}
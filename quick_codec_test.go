@@ -0,0 +1,138 @@
+// Package quick provides a high-performance, minimalistic web framework for Go.
+//
+// This file contains **unit tests** for the codec registry, Bind decoding and Ctx.Render.
+//
+// 📌 To run all unit tests, use:
+//
+//	$ go test -v ./...
+//	$ go test -v -run ^TestCodec
+package quick
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// kvCodec is a test codec encoding map[string]string as "key=value" lines.
+type kvCodec struct{}
+
+func (kvCodec) Marshal(v any) ([]byte, error) {
+	m, ok := v.(map[string]string)
+	if !ok {
+		return nil, errors.New("kv: unsupported value")
+	}
+	var sb strings.Builder
+	for _, k := range []string{"id", "name"} {
+		fmt.Fprintf(&sb, "%s=%s\n", k, m[k])
+	}
+	return []byte(sb.String()), nil
+}
+
+func (kvCodec) Unmarshal(data []byte, v any) error {
+	m, ok := v.(*map[string]string)
+	if !ok {
+		return errors.New("kv: unsupported value")
+	}
+	*m = make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		k, val, ok := strings.Cut(line, "=")
+		if !ok {
+			return errors.New("kv: invalid line " + line)
+		}
+		(*m)[k] = val
+	}
+	return nil
+}
+
+// upperJSONCodec replaces the default JSON codec to verify that Ctx.JSON uses it.
+type upperJSONCodec struct{ JSONCodec }
+
+func (c upperJSONCodec) Marshal(v any) ([]byte, error) {
+	b, err := json.Marshal(v)
+	return []byte(strings.ToUpper(string(b))), err
+}
+
+// TestCodecBindAndRender verifies decoding by Content-Type and encoding by Accept.
+//
+// Run with:
+//
+//	go test -v -run ^TestCodecBindAndRender
+func TestCodecBindAndRender(t *testing.T) {
+	q := New(Config{MaxBodySize: 1 << 20})
+	q.RegisterCodec("text/x-kv", kvCodec{})
+	q.Post("/echo", func(c *Ctx) error {
+		var m map[string]string
+		if err := c.Bind(&m); err != nil {
+			return err
+		}
+		return c.Render(m)
+	})
+
+	tests := []struct {
+		contentType string
+		body        string
+		accept      string
+		code        int
+		resType     string
+		resBody     string
+	}{
+		{"text/x-kv", "id=1\nname=quick", "text/x-kv", StatusOK, "text/x-kv", "id=1\nname=quick\n"},
+		{"text/x-kv; charset=utf-8", "id=2\nname=go", "", StatusOK, ContentTypeAppJSON, `{"id":"2","name":"go"}`},
+		{ContentTypeAppJSON, `{"id":"3","name":"x"}`, "application/xml;q=0.5, text/x-kv", StatusOK, "text/x-kv", "id=3\nname=x\n"},
+		{"application/vnd.api+json", `{"id":"4"}`, "application/json", StatusOK, ContentTypeAppJSON, `{"id":"4"}`},
+		{"text/x-kv", "id=5", "image/png", StatusNotAcceptable, ContentTypeProblemJSON, ""},
+		{"application/yaml", "id: 6", "", StatusUnsupportedMediaType, ContentTypeProblemJSON, ""},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(MethodPost, "/echo", strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", tt.contentType)
+		if tt.accept != "" {
+			req.Header.Set("Accept", tt.accept)
+		}
+		rec := httptest.NewRecorder()
+		q.ServeHTTP(rec, req)
+
+		if rec.Code != tt.code {
+			t.Errorf("%s -> %s: expected %d, got %d (%s)", tt.contentType, tt.accept, tt.code, rec.Code, rec.Body.String())
+			continue
+		}
+		if got := rec.Header().Get("Content-Type"); got != tt.resType {
+			t.Errorf("%s -> %s: expected Content-Type %q, got %q", tt.contentType, tt.accept, tt.resType, got)
+		}
+		if tt.resBody != "" && rec.Body.String() != tt.resBody {
+			t.Errorf("%s -> %s: expected body %q, got %q", tt.contentType, tt.accept, tt.resBody, rec.Body.String())
+		}
+	}
+}
+
+// TestCodecReplaceJSON verifies that a codec registered for application/json
+// is used by Ctx.JSON and Ctx.BodyParser.
+//
+// Run with:
+//
+//	go test -v -run ^TestCodecReplaceJSON
+func TestCodecReplaceJSON(t *testing.T) {
+	q := New(Config{MaxBodySize: 1 << 20})
+	q.RegisterCodec(ContentTypeAppJSON, upperJSONCodec{})
+	q.Post("/", func(c *Ctx) error {
+		var m map[string]string
+		if err := c.BodyParser(&m); err != nil {
+			return err
+		}
+		return c.JSON(m)
+	})
+
+	req := httptest.NewRequest(MethodPost, "/", strings.NewReader(`{"name":"quick"}`))
+	req.Header.Set("Content-Type", ContentTypeAppJSON)
+	rec := httptest.NewRecorder()
+	q.ServeHTTP(rec, req)
+
+	if rec.Body.String() != `{"NAME":"QUICK"}` {
+		t.Errorf("expected custom JSON codec output, got %q", rec.Body.String())
+	}
+}
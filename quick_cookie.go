@@ -0,0 +1,383 @@
+// Package quick provides a high-performance HTTP framework for building web applications in Go.
+//
+// This file implements cookie helpers on Ctx, including signed (HMAC-SHA256)
+// and encrypted (AES-256-GCM) cookies backed by a KeyRing configured in
+// `Config.CookieKeys`.
+//
+// The first key of the ring signs and encrypts new cookies; every key is
+// tried when verifying or decrypting, so keys can be rotated without
+// invalidating cookies issued with the previous key:
+//
+//	keys := quick.NewKeyRing([]byte(os.Getenv("COOKIE_KEY")), []byte(os.Getenv("COOKIE_KEY_OLD")))
+//	q := quick.New(quick.Config{CookieKeys: keys})
+//
+//	q.Get("/login", func(c *quick.Ctx) error {
+//	    return c.SetSignedCookie(&http.Cookie{Name: "uid", Value: "42", HttpOnly: true})
+//	})
+package quick
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Errors returned by the signed and encrypted cookie helpers.
+var (
+	// ErrInvalidCookie is returned when a cookie signature or ciphertext does not verify.
+	ErrInvalidCookie = errors.New("quick: invalid or tampered cookie")
+	// ErrNoCookieKeys is returned when Config.CookieKeys has no key.
+	ErrNoCookieKeys = errors.New("quick: no cookie keys configured")
+)
+
+// cookieKey holds the subkeys derived from one secret of the ring.
+type cookieKey struct {
+	sign []byte
+	aead cipher.AEAD
+}
+
+// KeyRing holds the secrets used to sign and encrypt values.
+//
+// The first key is the current one; the others are only used to verify and
+// decrypt values issued before a rotation. Secrets can have any length:
+// independent signing and encryption keys are derived from each one with HMAC-SHA256.
+//
+// A KeyRing is safe for concurrent use.
+type KeyRing struct {
+	mu   sync.RWMutex
+	keys []cookieKey
+}
+
+// NewKeyRing creates a key ring from secrets, the first being the current key.
+//
+// Parameters:
+//   - secrets: The secrets, newest first. Use at least 32 random bytes each.
+//
+// Returns:
+//   - *KeyRing: The key ring.
+//
+// Example Usage:
+//
+//	keys := quick.NewKeyRing([]byte("current-secret..."), []byte("previous-secret..."))
+func NewKeyRing(secrets ...[]byte) *KeyRing {
+	kr := &KeyRing{}
+	for _, secret := range secrets {
+		kr.keys = append(kr.keys, deriveCookieKey(secret))
+	}
+	return kr
+}
+
+// Rotate makes secret the current key. Previous keys keep verifying and
+// decrypting existing values.
+//
+// Parameters:
+//   - secret: The new secret.
+func (kr *KeyRing) Rotate(secret []byte) {
+	key := deriveCookieKey(secret)
+	kr.mu.Lock()
+	kr.keys = append([]cookieKey{key}, kr.keys...)
+	kr.mu.Unlock()
+}
+
+// Retain keeps only the n newest keys, dropping older ones.
+//
+// Parameters:
+//   - n: The number of keys to keep.
+func (kr *KeyRing) Retain(n int) {
+	kr.mu.Lock()
+	if n >= 0 && n < len(kr.keys) {
+		kr.keys = kr.keys[:n]
+	}
+	kr.mu.Unlock()
+}
+
+// snapshot returns the current keys.
+func (kr *KeyRing) snapshot() []cookieKey {
+	if kr == nil {
+		return nil
+	}
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+	return kr.keys
+}
+
+// deriveCookieKey derives the signing and encryption subkeys of secret.
+func deriveCookieKey(secret []byte) cookieKey {
+	derive := func(label string) []byte {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(label))
+		return mac.Sum(nil)
+	}
+	// AES-256 with a 32-byte key cannot fail.
+	block, _ := aes.NewCipher(derive("quick cookie encryption"))
+	aead, _ := cipher.NewGCM(block)
+	return cookieKey{sign: derive("quick cookie signature"), aead: aead}
+}
+
+// Sign returns value followed by an HMAC-SHA256 signature bound to name.
+//
+// Parameters:
+//   - name: The name the value is bound to (e.g. the cookie name).
+//   - value: The value to sign.
+//
+// Returns:
+//   - string: "base64(value).base64(signature)".
+//   - error: ErrNoCookieKeys if the ring is empty.
+func (kr *KeyRing) Sign(name, value string) (string, error) {
+	keys := kr.snapshot()
+	if len(keys) == 0 {
+		return "", ErrNoCookieKeys
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(value)) + "." +
+		base64.RawURLEncoding.EncodeToString(cookieMAC(keys[0].sign, name, value)), nil
+}
+
+// Verify checks a value produced by Sign with any key of the ring.
+//
+// Parameters:
+//   - name: The name the value was bound to.
+//   - signed: The signed value.
+//
+// Returns:
+//   - string: The original value.
+//   - error: ErrInvalidCookie if the signature does not match, ErrNoCookieKeys if the ring is empty.
+func (kr *KeyRing) Verify(name, signed string) (string, error) {
+	keys := kr.snapshot()
+	if len(keys) == 0 {
+		return "", ErrNoCookieKeys
+	}
+	encValue, encMAC, ok := strings.Cut(signed, ".")
+	if !ok {
+		return "", ErrInvalidCookie
+	}
+	value, err := base64.RawURLEncoding.DecodeString(encValue)
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(encMAC)
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+	for _, key := range keys {
+		if hmac.Equal(mac, cookieMAC(key.sign, name, string(value))) {
+			return string(value), nil
+		}
+	}
+	return "", ErrInvalidCookie
+}
+
+// Encrypt encrypts and authenticates value with AES-256-GCM, bound to name.
+//
+// Parameters:
+//   - name: The name the value is bound to (e.g. the cookie name).
+//   - value: The value to encrypt.
+//
+// Returns:
+//   - string: base64(nonce || ciphertext).
+//   - error: ErrNoCookieKeys if the ring is empty, or an error from the random source.
+func (kr *KeyRing) Encrypt(name, value string) (string, error) {
+	keys := kr.snapshot()
+	if len(keys) == 0 {
+		return "", ErrNoCookieKeys
+	}
+	aead := keys[0].aead
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(value)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(name))
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a value produced by Encrypt with any key of the ring.
+//
+// Parameters:
+//   - name: The name the value was bound to.
+//   - encrypted: The encrypted value.
+//
+// Returns:
+//   - string: The original value.
+//   - error: ErrInvalidCookie if no key can decrypt it, ErrNoCookieKeys if the ring is empty.
+func (kr *KeyRing) Decrypt(name, encrypted string) (string, error) {
+	keys := kr.snapshot()
+	if len(keys) == 0 {
+		return "", ErrNoCookieKeys
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+	for _, key := range keys {
+		n := key.aead.NonceSize()
+		if len(sealed) < n {
+			break
+		}
+		if plain, err := key.aead.Open(nil, sealed[:n], sealed[n:], []byte(name)); err == nil {
+			return string(plain), nil
+		}
+	}
+	return "", ErrInvalidCookie
+}
+
+// cookieMAC computes the HMAC-SHA256 of "name=value".
+func cookieMAC(key []byte, name, value string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(name))
+	mac.Write([]byte{'='})
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+// Cookie returns the value of the request cookie with the given name.
+//
+// Parameters:
+//   - name: The cookie name.
+//
+// Returns:
+//   - string: The cookie value, or "" if the cookie is not present.
+//
+// Example Usage:
+//
+//	theme := c.Cookie("theme")
+func (c *Ctx) Cookie(name string) string {
+	ck, err := c.Request.Cookie(name)
+	if err != nil {
+		return ""
+	}
+	return ck.Value
+}
+
+// SetCookie adds a Set-Cookie header to the response.
+//
+// The Path defaults to "/" when empty.
+//
+// Parameters:
+//   - cookie: The cookie to set.
+//
+// Example Usage:
+//
+//	c.SetCookie(&http.Cookie{Name: "theme", Value: "dark", MaxAge: 3600})
+func (c *Ctx) SetCookie(cookie *http.Cookie) {
+	if cookie.Path == "" {
+		cookie.Path = "/"
+	}
+	http.SetCookie(c.Response, cookie)
+}
+
+// ClearCookie expires the cookie with the given name on the client.
+//
+// Parameters:
+//   - name: The cookie name.
+//   - path: The cookie path, "/" when omitted. It must match the path used to set it.
+//
+// Example Usage:
+//
+//	c.ClearCookie("session")
+func (c *Ctx) ClearCookie(name string, path ...string) {
+	cookie := &http.Cookie{Name: name, Value: "", MaxAge: -1, Expires: time.Unix(0, 0)}
+	if len(path) > 0 {
+		cookie.Path = path[0]
+	}
+	c.SetCookie(cookie)
+}
+
+// cookieKeys returns the key ring configured in the application.
+func (c *Ctx) cookieKeys() *KeyRing {
+	if c.App == nil {
+		return nil
+	}
+	return c.App.config.CookieKeys
+}
+
+// SignedCookie returns the value of a cookie set with SetSignedCookie,
+// after verifying its HMAC signature.
+//
+// Parameters:
+//   - name: The cookie name.
+//
+// Returns:
+//   - string: The verified value.
+//   - error: http.ErrNoCookie if absent, ErrInvalidCookie if tampered, ErrNoCookieKeys if not configured.
+//
+// Example Usage:
+//
+//	uid, err := c.SignedCookie("uid")
+//	if err != nil {
+//	    return quick.ProblemUnauthorized()
+//	}
+func (c *Ctx) SignedCookie(name string) (string, error) {
+	ck, err := c.Request.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	return c.cookieKeys().Verify(name, ck.Value)
+}
+
+// SetSignedCookie sets a cookie whose value is signed with the current key of Config.CookieKeys.
+//
+// The value stays readable by the client but cannot be modified.
+//
+// Parameters:
+//   - cookie: The cookie to set; its Value is replaced by the signed value.
+//
+// Returns:
+//   - error: ErrNoCookieKeys if no key is configured.
+func (c *Ctx) SetSignedCookie(cookie *http.Cookie) error {
+	signed, err := c.cookieKeys().Sign(cookie.Name, cookie.Value)
+	if err != nil {
+		return err
+	}
+	ck := *cookie
+	ck.Value = signed
+	c.SetCookie(&ck)
+	return nil
+}
+
+// EncryptedCookie returns the value of a cookie set with SetEncryptedCookie.
+//
+// Parameters:
+//   - name: The cookie name.
+//
+// Returns:
+//   - string: The decrypted value.
+//   - error: http.ErrNoCookie if absent, ErrInvalidCookie if tampered, ErrNoCookieKeys if not configured.
+func (c *Ctx) EncryptedCookie(name string) (string, error) {
+	ck, err := c.Request.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	return c.cookieKeys().Decrypt(name, ck.Value)
+}
+
+// SetEncryptedCookie sets a cookie whose value is encrypted with AES-256-GCM
+// using the current key of Config.CookieKeys.
+//
+// The value can be neither read nor modified by the client.
+//
+// Parameters:
+//   - cookie: The cookie to set; its Value is replaced by the encrypted value.
+//
+// Returns:
+//   - error: ErrNoCookieKeys if no key is configured.
+//
+// Example Usage:
+//
+//	err := c.SetEncryptedCookie(&http.Cookie{Name: "token", Value: token, HttpOnly: true, Secure: true})
+func (c *Ctx) SetEncryptedCookie(cookie *http.Cookie) error {
+	encrypted, err := c.cookieKeys().Encrypt(cookie.Name, cookie.Value)
+	if err != nil {
+		return err
+	}
+	ck := *cookie
+	ck.Value = encrypted
+	c.SetCookie(&ck)
+	return nil
+}
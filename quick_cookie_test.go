@@ -0,0 +1,142 @@
+// Package quick provides a high-performance, minimalistic web framework for Go.
+//
+// This file contains **unit tests** for cookie helpers, signed and encrypted cookies.
+//
+// 📌 To run all unit tests, use:
+//
+//	$ go test -v ./...
+//	$ go test -v -run ^TestCookie
+package quick
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// cookieRoundTrip serves a request carrying the cookies set by a previous response.
+func cookieRoundTrip(q *Quick, path string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(MethodGet, path, nil)
+	for _, ck := range cookies {
+		req.AddCookie(ck)
+	}
+	rec := httptest.NewRecorder()
+	q.ServeHTTP(rec, req)
+	return rec
+}
+
+// TestCookieBasics verifies Cookie, SetCookie and ClearCookie.
+//
+// Run with:
+//
+//	go test -v -run ^TestCookieBasics
+func TestCookieBasics(t *testing.T) {
+	q := New()
+	q.Get("/set", func(c *Ctx) error {
+		c.SetCookie(&http.Cookie{Name: "theme", Value: "dark"})
+		return c.String("ok")
+	})
+	q.Get("/get", func(c *Ctx) error {
+		return c.String(c.Cookie("theme") + "|" + c.Cookie("missing"))
+	})
+	q.Get("/clear", func(c *Ctx) error {
+		c.ClearCookie("theme")
+		return c.String("ok")
+	})
+
+	cookies := cookieRoundTrip(q, "/set", nil).Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value != "dark" || cookies[0].Path != "/" {
+		t.Fatalf("unexpected cookies: %v", cookies)
+	}
+	if body := cookieRoundTrip(q, "/get", cookies).Body.String(); body != "dark|" {
+		t.Errorf("expected %q, got %q", "dark|", body)
+	}
+	cleared := cookieRoundTrip(q, "/clear", nil).Result().Cookies()
+	if len(cleared) != 1 || cleared[0].MaxAge >= 0 {
+		t.Errorf("expected an expired cookie, got %v", cleared)
+	}
+}
+
+// TestCookieSignedAndEncrypted verifies round trips, tampering detection and key rotation.
+//
+// Run with:
+//
+//	go test -v -run ^TestCookieSignedAndEncrypted
+func TestCookieSignedAndEncrypted(t *testing.T) {
+	keys := NewKeyRing([]byte("old-secret-old-secret-old-secret"))
+	q := New(Config{CookieKeys: keys})
+	q.Get("/set", func(c *Ctx) error {
+		if err := c.SetSignedCookie(&http.Cookie{Name: "uid", Value: "42"}); err != nil {
+			return err
+		}
+		return c.SetEncryptedCookie(&http.Cookie{Name: "token", Value: "s3cr3t"})
+	})
+	q.Get("/get", func(c *Ctx) error {
+		uid, err := c.SignedCookie("uid")
+		if err != nil {
+			return c.Status(StatusUnauthorized).String("uid: " + err.Error())
+		}
+		token, err := c.EncryptedCookie("token")
+		if err != nil {
+			return c.Status(StatusUnauthorized).String("token: " + err.Error())
+		}
+		return c.String(uid + "|" + token)
+	})
+
+	cookies := cookieRoundTrip(q, "/set", nil).Result().Cookies()
+	if len(cookies) != 2 {
+		t.Fatalf("expected 2 cookies, got %v", cookies)
+	}
+	if strings.Contains(cookies[1].Value, "s3cr3t") {
+		t.Errorf("encrypted cookie leaks its value: %q", cookies[1].Value)
+	}
+	if body := cookieRoundTrip(q, "/get", cookies).Body.String(); body != "42|s3cr3t" {
+		t.Fatalf("expected %q, got %q", "42|s3cr3t", body)
+	}
+
+	// cookies issued with the previous key are still accepted after a rotation
+	keys.Rotate([]byte("new-secret-new-secret-new-secret"))
+	if body := cookieRoundTrip(q, "/get", cookies).Body.String(); body != "42|s3cr3t" {
+		t.Errorf("after rotation: expected %q, got %q", "42|s3cr3t", body)
+	}
+
+	// tampered values are rejected
+	forged, _ := NewKeyRing([]byte("attacker")).Sign("uid", "1")
+	tampered := []*http.Cookie{{Name: "uid", Value: forged}, cookies[1]}
+	if rec := cookieRoundTrip(q, "/get", tampered); rec.Code != StatusUnauthorized {
+		t.Errorf("expected 401 for forged signature, got %d %q", rec.Code, rec.Body.String())
+	}
+	swapped := []*http.Cookie{cookies[0], {Name: "token", Value: cookies[0].Value}}
+	if rec := cookieRoundTrip(q, "/get", swapped); rec.Code != StatusUnauthorized {
+		t.Errorf("expected 401 for invalid ciphertext, got %d", rec.Code)
+	}
+
+	// once the old key is retired, old cookies are rejected
+	keys.Retain(1)
+	if rec := cookieRoundTrip(q, "/get", cookies); rec.Code != StatusUnauthorized {
+		t.Errorf("expected 401 after retiring the old key, got %d", rec.Code)
+	}
+}
+
+// TestCookieKeyRingBinding verifies that signed and encrypted values are bound to their name.
+//
+// Run with:
+//
+//	go test -v -run ^TestCookieKeyRingBinding
+func TestCookieKeyRingBinding(t *testing.T) {
+	kr := NewKeyRing([]byte("secret"))
+
+	signed, _ := kr.Sign("role", "admin")
+	if _, err := kr.Verify("other", signed); !errors.Is(err, ErrInvalidCookie) {
+		t.Errorf("expected ErrInvalidCookie for another name, got %v", err)
+	}
+	enc, _ := kr.Encrypt("role", "admin")
+	if _, err := kr.Decrypt("other", enc); !errors.Is(err, ErrInvalidCookie) {
+		t.Errorf("expected ErrInvalidCookie for another name, got %v", err)
+	}
+	if _, err := NewKeyRing().Sign("role", "admin"); !errors.Is(err, ErrNoCookieKeys) {
+		t.Errorf("expected ErrNoCookieKeys, got %v", err)
+	}
+}
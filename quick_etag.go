@@ -0,0 +1,287 @@
+// Package quick provides a high-performance HTTP framework for building web applications in Go.
+//
+// This file implements validators and conditional requests (RFC 9110,
+// Sections 8.8 and 13): generation and comparison of entity tags, and the
+// evaluation of If-Match, If-None-Match, If-Modified-Since and
+// If-Unmodified-Since. The etag and cache middlewares and Static share it.
+package quick
+
+import (
+	"encoding/hex"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GenerateETag returns an entity tag for body.
+//
+// The tag is derived from the length and a 64-bit FNV-1a hash of body. Weak
+// tags, prefixed with W/, tell caches that equivalent responses share the
+// tag even if their bytes differ, e.g. after compression.
+//
+// Parameters:
+//   - body: The response body.
+//   - weak: Whether to generate a weak tag.
+//
+// Returns:
+//   - string: The quoted entity tag, e.g. "1f-9a3c53e1f0b2c4d7".
+func GenerateETag(body []byte, weak bool) string {
+	h := fnv.New64a()
+	h.Write(body)
+	var sum [8]byte
+	tag := `"` + strconv.FormatInt(int64(len(body)), 16) + "-" + hex.EncodeToString(h.Sum(sum[:0])) + `"`
+	if weak {
+		return "W/" + tag
+	}
+	return tag
+}
+
+// fileETag returns a weak entity tag from the size and modification time
+// of a file, as Static uses for files on disk.
+func fileETag(size int64, modTime time.Time) string {
+	return `W/"` + strconv.FormatInt(modTime.UnixNano(), 16) + "-" + strconv.FormatInt(size, 16) + `"`
+}
+
+// staticValidators sets the ETag and Last-Modified of the files served by
+// Static and answers conditional requests with CheckPreconditions.
+//
+// Files on disk get a weak tag from their size and modification time; files
+// without a modification time, such as those of an embed.FS, get a strong
+// tag from their content, computed once.
+func staticValidators(fsys http.FileSystem, next http.Handler) http.Handler {
+	var tags sync.Map // path -> entity tag of files without a modification time
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != MethodGet && r.Method != MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		name := path.Clean("/" + r.URL.Path)
+		if strings.HasSuffix(r.URL.Path, "/") {
+			name = path.Join(name, "index.html")
+		}
+		f, err := fsys.Open(name)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil || info.IsDir() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		c := &Ctx{Response: w, Request: r}
+		if info.ModTime().IsZero() {
+			etag, ok := tags.Load(name)
+			if !ok {
+				data, err := io.ReadAll(f)
+				if err != nil {
+					next.ServeHTTP(w, r)
+					return
+				}
+				etag, _ = tags.LoadOrStore(name, GenerateETag(data, false))
+			}
+			c.SetETag(etag.(string))
+		} else {
+			c.SetETag(fileETag(info.Size(), info.ModTime()))
+			c.SetLastModified(info.ModTime())
+		}
+		if c.CheckPreconditions() {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// SetETag sets the ETag response header.
+//
+// tag is quoted when needed, so a version number or a hash can be passed as
+// is; tags that are already quoted, weak or not, are kept.
+//
+// Parameters:
+//   - tag: The entity tag.
+//   - weak: Optional; true marks an unquoted tag as weak.
+//
+// Example Usage:
+//
+//	c.SetETag(doc.Version)
+//	if c.CheckPreconditions() {
+//	    return nil
+//	}
+func (c *Ctx) SetETag(tag string, weak ...bool) {
+	if !strings.HasPrefix(tag, `"`) && !strings.HasPrefix(tag, `W/"`) {
+		tag = `"` + tag + `"`
+		if len(weak) > 0 && weak[0] {
+			tag = "W/" + tag
+		}
+	}
+	c.Response.Header().Set("ETag", tag)
+}
+
+// SetLastModified sets the Last-Modified response header.
+//
+// Parameters:
+//   - t: The time the resource was last modified.
+func (c *Ctx) SetLastModified(t time.Time) {
+	if !t.IsZero() {
+		c.Response.Header().Set("Last-Modified", t.UTC().Format(http.TimeFormat))
+	}
+}
+
+// CheckPreconditions evaluates the conditional headers of the request
+// against the ETag and Last-Modified headers already set on the response.
+//
+// When a precondition fails it sends the response, 304 Not Modified or
+// 412 Precondition Failed rendered by the error handler of the app, and
+// returns true: the handler must then stop.
+// Call it before changing state, so If-Match protects updates from lost
+// writes.
+//
+// Returns:
+//   - bool: true if the response was sent.
+//
+// Example Usage:
+//
+//	q.Put("/docs/:id", func(c *quick.Ctx) error {
+//	    doc := load(c.Param("id"))
+//	    c.SetETag(doc.Version)
+//	    if c.CheckPreconditions() {
+//	        return nil // 412: the client edited a stale version
+//	    }
+//	    return save(c, doc)
+//	})
+func (c *Ctx) CheckPreconditions() bool {
+	switch CheckPreconditions(c.Request, c.Response.Header()) {
+	case StatusNotModified:
+		h := c.Response.Header()
+		h.Del("Content-Type")
+		h.Del("Content-Length")
+		h.Del("Content-Encoding")
+		if h.Get("ETag") != "" {
+			h.Del("Last-Modified")
+		}
+		c.Response.WriteHeader(StatusNotModified)
+		c.resStatus = StatusNotModified
+		return true
+	case StatusPreconditionFailed:
+		HandleError(c, NewError(StatusPreconditionFailed))
+		return true
+	}
+	return false
+}
+
+// CheckPreconditions evaluates If-Match, If-Unmodified-Since, If-None-Match
+// and If-Modified-Since, in the order of RFC 9110, Section 13.2.2, against
+// the ETag and Last-Modified of header.
+//
+// Parameters:
+//   - r: The request.
+//   - header: The response headers holding the validators.
+//
+// Returns:
+//   - int: 0 if the request should proceed, StatusNotModified or StatusPreconditionFailed otherwise.
+func CheckPreconditions(r *http.Request, header http.Header) int {
+	etag := header.Get("ETag")
+	lastModified := parseHTTPTime(header.Get("Last-Modified"))
+
+	if im := r.Header.Get("If-Match"); im != "" {
+		if !matchETag(im, etag, false) {
+			return StatusPreconditionFailed
+		}
+	} else if ius := parseHTTPTime(r.Header.Get("If-Unmodified-Since")); !ius.IsZero() && !lastModified.IsZero() {
+		if lastModified.After(ius) {
+			return StatusPreconditionFailed
+		}
+	}
+
+	safe := r.Method == MethodGet || r.Method == MethodHead
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if matchETag(inm, etag, true) {
+			if safe {
+				return StatusNotModified
+			}
+			return StatusPreconditionFailed
+		}
+	} else if ims := parseHTTPTime(r.Header.Get("If-Modified-Since")); safe && !ims.IsZero() && !lastModified.IsZero() {
+		if !lastModified.After(ims) {
+			return StatusNotModified
+		}
+	}
+	return 0
+}
+
+// matchETag reports whether etag matches one of the tags of an If-Match or
+// If-None-Match header. "*" matches any current representation, that is any
+// response with an ETag.
+//
+// If-Match uses the strong comparison and If-None-Match the weak one
+// (RFC 9110, Section 8.8.3.2).
+func matchETag(header, etag string, weak bool) bool {
+	if etag == "" {
+		return false
+	}
+	for {
+		header = strings.TrimLeft(header, " \t,")
+		if header == "" {
+			return false
+		}
+		if header[0] == '*' {
+			return true
+		}
+		tag, rest := scanETag(header)
+		if tag == "" {
+			return false
+		}
+		if weak && weakMatch(tag, etag) || !weak && strongMatch(tag, etag) {
+			return true
+		}
+		header = rest
+	}
+}
+
+// scanETag returns the first entity tag of s and what follows it.
+// Entity tags may contain commas, so the list cannot be split on them.
+func scanETag(s string) (tag, rest string) {
+	start := 0
+	if strings.HasPrefix(s, "W/") {
+		start = 2
+	}
+	if len(s) <= start || s[start] != '"' {
+		return "", ""
+	}
+	end := strings.IndexByte(s[start+1:], '"')
+	if end < 0 {
+		return "", ""
+	}
+	end += start + 2
+	return s[:end], s[end:]
+}
+
+// strongMatch reports whether both tags are strong and identical.
+func strongMatch(a, b string) bool {
+	return a == b && !strings.HasPrefix(a, "W/")
+}
+
+// weakMatch reports whether the tags are identical, ignoring W/.
+func weakMatch(a, b string) bool {
+	return strings.TrimPrefix(a, "W/") == strings.TrimPrefix(b, "W/")
+}
+
+// parseHTTPTime parses an HTTP date, returning the zero time if it is invalid.
+func parseHTTPTime(s string) time.Time {
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := http.ParseTime(s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
@@ -0,0 +1,218 @@
+// Package quick provides a high-performance, minimalistic web framework for Go.
+//
+// This file contains **unit tests** for entity tags and conditional requests.
+//
+// 📌 To run all unit tests, use:
+//
+//	$ go test -v ./...
+//	$ go test -v -run ^TestETag
+package quick
+
+import (
+	"embed"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestETagGenerate verifies GenerateETag and Ctx.SetETag.
+//
+// Run with:
+//
+//	go test -v -run ^TestETagGenerate
+func TestETagGenerate(t *testing.T) {
+	a := GenerateETag([]byte("hello"), false)
+	if a != GenerateETag([]byte("hello"), false) || a == GenerateETag([]byte("hellp"), false) {
+		t.Errorf("tags should depend only on the body: %s", a)
+	}
+	if !strings.HasPrefix(a, `"5-`) || !strings.HasSuffix(a, `"`) {
+		t.Errorf("unexpected strong tag %s", a)
+	}
+	if w := GenerateETag([]byte("hello"), true); w != "W/"+a {
+		t.Errorf("unexpected weak tag %s", w)
+	}
+
+	tests := []struct {
+		tag  string
+		weak []bool
+		want string
+	}{
+		{"v1", nil, `"v1"`},
+		{"v1", []bool{true}, `W/"v1"`},
+		{`"v1"`, []bool{true}, `"v1"`},
+		{`W/"v1"`, nil, `W/"v1"`},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		c := &Ctx{Response: rec}
+		c.SetETag(tt.tag, tt.weak...)
+		if got := rec.Header().Get("ETag"); got != tt.want {
+			t.Errorf("SetETag(%q, %v) = %s, want %s", tt.tag, tt.weak, got, tt.want)
+		}
+	}
+}
+
+// TestETagCheckPreconditions verifies the evaluation order and comparisons
+// of RFC 9110, Section 13.2.2.
+//
+// Run with:
+//
+//	go test -v -run ^TestETagCheckPreconditions
+func TestETagCheckPreconditions(t *testing.T) {
+	modified := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	before := modified.Add(-time.Hour).Format(http.TimeFormat)
+	after := modified.Add(time.Hour).Format(http.TimeFormat)
+	at := modified.Format(http.TimeFormat)
+
+	tests := []struct {
+		name    string
+		method  string
+		etag    string
+		headers map[string]string
+		want    int
+	}{
+		{"no_conditions", MethodGet, `"a"`, nil, 0},
+		{"if_none_match_hit", MethodGet, `"a"`, map[string]string{"If-None-Match": `"x", "a"`}, StatusNotModified},
+		{"if_none_match_weak", MethodGet, `W/"a"`, map[string]string{"If-None-Match": `"a"`}, StatusNotModified},
+		{"if_none_match_miss", MethodGet, `"a"`, map[string]string{"If-None-Match": `"b"`}, 0},
+		{"if_none_match_star", MethodHead, `"a"`, map[string]string{"If-None-Match": "*"}, StatusNotModified},
+		{"if_none_match_unsafe", MethodPut, `"a"`, map[string]string{"If-None-Match": "*"}, StatusPreconditionFailed},
+		{"if_none_match_comma_in_tag", MethodGet, `"a,b"`, map[string]string{"If-None-Match": `"a,b"`}, StatusNotModified},
+		{"if_match_hit", MethodPut, `"a"`, map[string]string{"If-Match": `"a"`}, 0},
+		{"if_match_miss", MethodPut, `"a"`, map[string]string{"If-Match": `"b"`}, StatusPreconditionFailed},
+		{"if_match_weak_fails", MethodPut, `W/"a"`, map[string]string{"If-Match": `W/"a"`}, StatusPreconditionFailed},
+		{"if_match_no_etag", MethodPut, "", map[string]string{"If-Match": "*"}, StatusPreconditionFailed},
+		{"if_modified_since_not_modified", MethodGet, "", map[string]string{"If-Modified-Since": at}, StatusNotModified},
+		{"if_modified_since_modified", MethodGet, "", map[string]string{"If-Modified-Since": before}, 0},
+		{"if_modified_since_ignored_with_inm", MethodGet, `"a"`, map[string]string{"If-None-Match": `"b"`, "If-Modified-Since": after}, 0},
+		{"if_modified_since_ignored_for_post", MethodPost, "", map[string]string{"If-Modified-Since": after}, 0},
+		{"if_unmodified_since_ok", MethodDelete, "", map[string]string{"If-Unmodified-Since": after}, 0},
+		{"if_unmodified_since_failed", MethodDelete, "", map[string]string{"If-Unmodified-Since": before}, StatusPreconditionFailed},
+		{"if_unmodified_since_ignored_with_im", MethodDelete, `"a"`, map[string]string{"If-Match": `"a"`, "If-Unmodified-Since": before}, 0},
+		{"invalid_date", MethodGet, "", map[string]string{"If-Modified-Since": "yesterday"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			h := http.Header{}
+			if tt.etag != "" {
+				h.Set("ETag", tt.etag)
+			}
+			h.Set("Last-Modified", at)
+			if got := CheckPreconditions(req, h); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestETagCtxCheckPreconditions verifies the responses sent by Ctx.CheckPreconditions.
+//
+// Run with:
+//
+//	go test -v -run ^TestETagCtxCheckPreconditions
+func TestETagCtxCheckPreconditions(t *testing.T) {
+	saved := 0
+	q := New()
+	q.Get("/doc", func(c *Ctx) error {
+		c.SetETag("v2")
+		if c.CheckPreconditions() {
+			return nil
+		}
+		return c.Status(StatusOK).String("document")
+	})
+	q.Put("/doc", func(c *Ctx) error {
+		c.SetETag("v2")
+		if c.CheckPreconditions() {
+			return nil
+		}
+		saved++
+		return c.Status(StatusNoContent).Send(nil)
+	})
+
+	tests := []struct {
+		method string
+		header string
+		value  string
+		code   int
+		body   string
+	}{
+		{MethodGet, "If-None-Match", `"v2"`, StatusNotModified, ""},
+		{MethodGet, "If-None-Match", `"v1"`, StatusOK, "document"},
+		{MethodPut, "If-Match", `"v1"`, StatusPreconditionFailed, `"code":412`},
+		{MethodPut, "If-Match", `"v2"`, StatusNoContent, ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/doc", nil)
+		req.Header.Set(tt.header, tt.value)
+		rec := httptest.NewRecorder()
+		q.ServeHTTP(rec, req)
+		if rec.Code != tt.code || !strings.Contains(rec.Body.String(), tt.body) {
+			t.Errorf("%s %s: %s: got %d %q", tt.method, tt.header, tt.value, rec.Code, rec.Body.String())
+		}
+		if rec.Header().Get("ETag") != `"v2"` {
+			t.Errorf("%s: expected the ETag header, got %q", tt.method, rec.Header().Get("ETag"))
+		}
+	}
+	if saved != 1 {
+		t.Errorf("expected one save, got %d", saved)
+	}
+}
+
+//go:embed quick_etag.go
+var etagTestFS embed.FS
+
+// TestETagStatic verifies the validators of files served by Static.
+//
+// Run with:
+//
+//	go test -v -run ^TestETagStatic
+func TestETagStatic(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.js"), []byte("console.log(1)"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, source := range map[string]any{"dir": dir, "embed": etagTestFS} {
+		t.Run(name, func(t *testing.T) {
+			file := "/static/app.js"
+			if name == "embed" {
+				file = "/static/quick_etag.go"
+			}
+			q := New()
+			q.Static("/static", source)
+			// Static registers its file server on the mux
+			serve := q.mux.ServeHTTP
+
+			rec := httptest.NewRecorder()
+			serve(rec, httptest.NewRequest(MethodGet, file, nil))
+			etag := rec.Header().Get("ETag")
+			if rec.Code != StatusOK || etag == "" {
+				t.Fatalf("expected 200 with an ETag, got %d %q", rec.Code, etag)
+			}
+
+			req := httptest.NewRequest(MethodGet, file, nil)
+			req.Header.Set("If-None-Match", etag)
+			rec = httptest.NewRecorder()
+			serve(rec, req)
+			if rec.Code != StatusNotModified || rec.Body.Len() != 0 {
+				t.Errorf("expected 304, got %d %q", rec.Code, rec.Body.String())
+			}
+
+			req = httptest.NewRequest(MethodGet, file, nil)
+			req.Header.Set("If-Match", `"stale"`)
+			rec = httptest.NewRecorder()
+			serve(rec, req)
+			if rec.Code != StatusPreconditionFailed {
+				t.Errorf("expected 412, got %d", rec.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+// Package quick provides a high-performance HTTP framework for building web applications in Go.
+//
+// This file implements HTTP content negotiation (RFC 9110, Section 12):
+// parsing of Accept-style headers with quality values and selection of the
+// best offer.
+package quick
+
+import (
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// acceptRange is a single entry of an Accept-style header.
+//
+// Fields:
+//   - value: The media range or token, lowercased (e.g. "text/*", "gzip", "en-us").
+//   - q: The quality value, between 0 and 1.
+//   - params: The number of media type parameters other than q, used as a tie-breaker.
+type acceptRange struct {
+	value  string
+	q      float64
+	params int
+}
+
+// parseAccept parses an Accept, Accept-Encoding, Accept-Language or Accept-Charset header.
+//
+// Entries with an invalid quality value are ignored.
+//
+// Parameters:
+//   - header: The raw header value.
+//
+// Returns:
+//   - []acceptRange: The entries, in header order.
+func parseAccept(header string) []acceptRange {
+	if header == "" {
+		return nil
+	}
+	ranges := make([]acceptRange, 0, strings.Count(header, ",")+1)
+	for _, part := range strings.Split(header, ",") {
+		value, params, _ := strings.Cut(part, ";")
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			continue
+		}
+
+		r := acceptRange{value: value, q: 1}
+		valid := true
+		for _, param := range strings.Split(params, ";") {
+			k, v, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok {
+				continue
+			}
+			if strings.EqualFold(strings.TrimSpace(k), "q") {
+				q, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+				if err != nil || q < 0 || q > 1 {
+					valid = false
+					break
+				}
+				r.q = q
+				continue
+			}
+			r.params++
+		}
+		if valid {
+			ranges = append(ranges, r)
+		}
+	}
+	return ranges
+}
+
+// mediaRangeMatch reports how specifically the media range rng matches the media type offer.
+//
+// Returns:
+//   - int: -1 when it does not match, 0 for "*/*", 1 for "type/*", 2 for an exact match.
+func mediaRangeMatch(rng, offer string) int {
+	if rng == "*/*" || rng == "*" {
+		return 0
+	}
+	rt, rs, _ := strings.Cut(rng, "/")
+	ot, os, _ := strings.Cut(offer, "/")
+	if rt != ot {
+		return -1
+	}
+	if rs == "*" {
+		return 1
+	}
+	if rs == os {
+		return 2
+	}
+	return -1
+}
+
+// tokenMatch reports how specifically a token range matches an offer, for
+// encodings, charsets and languages.
+//
+// A language range also matches any offer it is a prefix of ("en" matches "en-US").
+//
+// Returns:
+//   - int: -1 when it does not match, 0 for "*", 1 for a prefix, 2 for an exact match.
+func tokenMatch(rng, offer string) int {
+	switch {
+	case rng == "*":
+		return 0
+	case rng == offer:
+		return 2
+	case strings.HasPrefix(offer, rng+"-"):
+		return 1
+	}
+	return -1
+}
+
+// negotiate returns the offer preferred by the client.
+//
+// For each offer, the most specific matching range defines its quality.
+// Offers with quality 0 are not acceptable. Ties keep the order of offers,
+// so the server preference decides between equally acceptable offers.
+// An empty header accepts the first offer.
+//
+// Parameters:
+//   - header: The raw Accept-style header.
+//   - offers: The values the server can produce, in order of preference.
+//   - match: mediaRangeMatch for media types, tokenMatch for other headers.
+//
+// Returns:
+//   - string: The selected offer, exactly as given, or "" if none is acceptable.
+func negotiate(header string, offers []string, match func(rng, offer string) int) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	ranges := parseAccept(header)
+	if len(ranges) == 0 {
+		return offers[0]
+	}
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		o := strings.ToLower(offer)
+		q, spec, params := 0.0, -1, -1
+		for _, r := range ranges {
+			s := match(r.value, o)
+			if s < 0 {
+				continue
+			}
+			if s > spec || (s == spec && r.params > params) {
+				q, spec, params = r.q, s, r.params
+			}
+		}
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// Accepts returns the offer that best matches the Accept header of the request.
+//
+// Quality values and wildcards ("*/*", "text/*") are honored; between equally
+// acceptable offers the first one wins. Offers may be media types or file
+// extensions ("json", "html"), which are resolved with mime.TypeByExtension.
+// When the request has no Accept header, the first offer is returned.
+//
+// Parameters:
+//   - offers: The media types the handler can produce, in order of preference.
+//
+// Returns:
+//   - string: The selected offer, exactly as given, or "" if none is acceptable.
+//
+// Example Usage:
+//
+//	switch c.Accepts("application/json", "text/html") {
+//	case "application/json":
+//	    return c.JSON(data)
+//	case "text/html":
+//	    return c.HTML("page", data)
+//	default:
+//	    return quick.ProblemNotAcceptable()
+//	}
+func (c *Ctx) Accepts(offers ...string) string {
+	return negotiate(c.Request.Header.Get("Accept"), offers, func(rng, offer string) int {
+		return mediaRangeMatch(rng, offerMediaType(offer))
+	})
+}
+
+// AcceptsEncodings returns the offer that best matches the Accept-Encoding header.
+//
+// Parameters:
+//   - offers: The content codings the handler can produce (e.g. "br", "gzip").
+//
+// Returns:
+//   - string: The selected offer, or "" if none is acceptable.
+//
+// Example Usage:
+//
+//	enc := c.AcceptsEncodings("br", "gzip", "identity")
+func (c *Ctx) AcceptsEncodings(offers ...string) string {
+	return negotiate(c.Request.Header.Get("Accept-Encoding"), offers, tokenMatch)
+}
+
+// AcceptsLanguages returns the offer that best matches the Accept-Language header.
+//
+// A language range also matches more specific tags ("en" matches "en-US").
+//
+// Parameters:
+//   - offers: The language tags available (e.g. "en-US", "pt-BR").
+//
+// Returns:
+//   - string: The selected offer, or "" if none is acceptable.
+//
+// Example Usage:
+//
+//	lang := c.AcceptsLanguages("en", "pt-BR")
+func (c *Ctx) AcceptsLanguages(offers ...string) string {
+	return negotiate(c.Request.Header.Get("Accept-Language"), offers, tokenMatch)
+}
+
+// AcceptsCharsets returns the offer that best matches the Accept-Charset header.
+//
+// Parameters:
+//   - offers: The charsets available (e.g. "utf-8", "iso-8859-1").
+//
+// Returns:
+//   - string: The selected offer, or "" if none is acceptable.
+func (c *Ctx) AcceptsCharsets(offers ...string) string {
+	return negotiate(c.Request.Header.Get("Accept-Charset"), offers, tokenMatch)
+}
+
+// Format calls the renderer registered for the media type that best matches
+// the Accept header.
+//
+// Keys are media types or extensions (see Accepts). Since map order is random,
+// equally acceptable keys are tried in alphabetical order. The key "default",
+// when present, is used if nothing else is acceptable. The Content-Type header
+// is set to the selected media type and "Vary: Accept" is added.
+//
+// Parameters:
+//   - renderers: The renderer of each media type.
+//
+// Returns:
+//   - error: The renderer error, or a 406 *Problem if no renderer is acceptable.
+//
+// Example Usage:
+//
+//	return c.Format(map[string]func() error{
+//	    "application/json": func() error { return c.JSON(user) },
+//	    "text/html":        func() error { return c.HTML("user", user) },
+//	    "default":          func() error { return c.String(user.Name) },
+//	})
+func (c *Ctx) Format(renderers map[string]func() error) error {
+	offers := make([]string, 0, len(renderers))
+	for key := range renderers {
+		if key != "default" {
+			offers = append(offers, key)
+		}
+	}
+	sort.Strings(offers)
+
+	c.Append("Vary", "Accept")
+	if offer := c.Accepts(offers...); offer != "" {
+		c.Set("Content-Type", offerMediaType(offer))
+		return renderers[offer]()
+	}
+	if render, ok := renderers["default"]; ok {
+		return render()
+	}
+	return ProblemNotAcceptable("supported media types: " + strings.Join(offers, ", "))
+}
+
+// offerMediaType resolves an extension offer ("json", ".html") to its media type.
+// Offers that already contain a "/" are returned unchanged.
+func offerMediaType(offer string) string {
+	if strings.Contains(offer, "/") {
+		return offer
+	}
+	if t := mime.TypeByExtension("." + strings.TrimPrefix(offer, ".")); t != "" {
+		mediaType, _, _ := strings.Cut(t, ";")
+		return mediaType
+	}
+	return offer
+}
@@ -50,6 +50,7 @@ func releaseCtx(ctx *Ctx) {
 	ctx.Response = nil
 	ctx.Request = nil
 	ctx.bodyByte = nil
+	ctx.queryValues = nil
 	ctx.JsonStr = ""
 	ctx.resStatus = 0
 	ctx.MoreRequests = 0
@@ -75,6 +76,12 @@ func (rw *pooledResponseWriter) Flush() {
 	}
 }
 
+// Unwrap returns the underlying ResponseWriter, so http.ResponseController can
+// reach Hijack and other optional interfaces, e.g. for WebSocket proxying.
+func (rw *pooledResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // responseWriterPool is a sync.Pool for pooledResponseWriter instances to reduce allocations.
 var responseWriterPool = sync.Pool{
 	New: func() interface{} {
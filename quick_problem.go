@@ -0,0 +1,391 @@
+// Package quick provides a high-performance HTTP framework for building web applications in Go.
+//
+// This file implements "Problem Details for HTTP APIs" (RFC 7807 / RFC 9457).
+//
+// A *Problem is an error that handlers can return. The default error handler renders it as
+// `application/problem+json`, or as `application/problem+xml` when the client prefers XML.
+//
+// Example:
+//
+//	q.Get("/users/:id", func(c *quick.Ctx) error {
+//	    return quick.NewProblem(quick.StatusNotFound).
+//	        Type("https://example.com/probs/user-not-found").
+//	        Detail("user " + c.Param("id") + " does not exist").
+//	        Instance(c.Path())
+//	})
+package quick
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// problemXMLNamespace is the XML namespace defined by RFC 7807, Appendix A.
+const problemXMLNamespace = "urn:ietf:rfc:7807"
+
+// problemMembers lists the members defined by the RFC, which extensions cannot override.
+var problemMembers = map[string]bool{
+	"type":     true,
+	"title":    true,
+	"status":   true,
+	"detail":   true,
+	"instance": true,
+}
+
+// Problem represents an RFC 9457 problem details object.
+//
+// It implements the error interface, so it can be returned directly from handlers.
+// Use NewProblem or one of the Problem* constructors to create it, and the
+// chainable methods to fill its members.
+//
+// Members:
+//   - type: A URI reference that identifies the problem type (defaults to "about:blank").
+//   - title: A short, human-readable summary (defaults to the status text).
+//   - status: The HTTP status code.
+//   - detail: A human-readable explanation specific to this occurrence.
+//   - instance: A URI reference that identifies this occurrence.
+//   - extensions: Additional members, rendered next to the standard ones.
+type Problem struct {
+	typ        string
+	title      string
+	status     int
+	detail     string
+	instance   string
+	extensions map[string]any
+	cause      error
+}
+
+// NewProblem creates a new Problem for the given HTTP status code.
+//
+// The title defaults to the standard status text of the code.
+//
+// Example usage:
+//
+//	return quick.NewProblem(404).Detail("order 42 not found")
+func NewProblem(status int) *Problem {
+	return &Problem{
+		status: status,
+		title:  StatusText(status),
+	}
+}
+
+// Type sets the problem type URI.
+func (p *Problem) Type(uri string) *Problem {
+	p.typ = uri
+	return p
+}
+
+// Title sets the short, human-readable summary of the problem type.
+func (p *Problem) Title(title string) *Problem {
+	p.title = title
+	return p
+}
+
+// Detail sets the explanation specific to this occurrence of the problem.
+func (p *Problem) Detail(detail string) *Problem {
+	p.detail = detail
+	return p
+}
+
+// Instance sets the URI reference that identifies this occurrence of the problem.
+func (p *Problem) Instance(uri string) *Problem {
+	p.instance = uri
+	return p
+}
+
+// With adds an extension member to the problem.
+//
+// Standard members (type, title, status, detail, instance) cannot be overridden
+// and are ignored.
+//
+// Example usage:
+//
+//	quick.NewProblem(403).Detail("not enough credit").With("balance", 30)
+func (p *Problem) With(key string, value any) *Problem {
+	if key == "" || problemMembers[key] {
+		return p
+	}
+	if p.extensions == nil {
+		p.extensions = make(map[string]any)
+	}
+	p.extensions[key] = value
+	return p
+}
+
+// Wrap records the underlying cause of the problem.
+//
+// The cause is never rendered to the client; it is available through
+// errors.Is / errors.As and Unwrap for logging.
+func (p *Problem) Wrap(err error) *Problem {
+	p.cause = err
+	return p
+}
+
+// Status returns the HTTP status code of the problem.
+func (p *Problem) Status() int {
+	return p.status
+}
+
+// Extension returns the value of an extension member and whether it is set.
+func (p *Problem) Extension(key string) (any, bool) {
+	v, ok := p.extensions[key]
+	return v, ok
+}
+
+// Error implements the error interface.
+//
+// It returns the detail, or the title when no detail is set.
+func (p *Problem) Error() string {
+	if p.detail != "" {
+		return p.detail
+	}
+	if p.title != "" {
+		return p.title
+	}
+	return StatusText(p.status)
+}
+
+// Unwrap returns the cause recorded with Wrap.
+func (p *Problem) Unwrap() error {
+	return p.cause
+}
+
+// extensionKeys returns the extension member names in a stable order.
+func (p *Problem) extensionKeys() []string {
+	keys := make([]string, 0, len(p.extensions))
+	for k := range p.extensions {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// MarshalJSON encodes the problem as an RFC 9457 JSON object.
+//
+// Standard members come first, followed by the extension members in alphabetical order.
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+
+	first := true
+	write := func(key string, value any) error {
+		b, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		buf.WriteString(strconv.Quote(key))
+		buf.WriteByte(':')
+		buf.Write(b)
+		return nil
+	}
+
+	if p.typ != "" {
+		write("type", p.typ)
+	}
+	if p.title != "" {
+		write("title", p.title)
+	}
+	write("status", p.status)
+	if p.detail != "" {
+		write("detail", p.detail)
+	}
+	if p.instance != "" {
+		write("instance", p.instance)
+	}
+	for _, k := range p.extensionKeys() {
+		if err := write(k, p.extensions[k]); err != nil {
+			return nil, fmt.Errorf("problem extension %q: %w", k, err)
+		}
+	}
+
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// MarshalXML encodes the problem following RFC 7807, Appendix A.
+//
+// Extension members are encoded as child elements named after their key,
+// so their values must be encodable by encoding/xml.
+func (p *Problem) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{
+		Name: xml.Name{Local: "problem"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: problemXMLNamespace}},
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	elem := func(name string, value any) error {
+		return e.EncodeElement(value, xml.StartElement{Name: xml.Name{Local: name}})
+	}
+
+	if p.typ != "" {
+		if err := elem("type", p.typ); err != nil {
+			return err
+		}
+	}
+	if p.title != "" {
+		if err := elem("title", p.title); err != nil {
+			return err
+		}
+	}
+	if err := elem("status", p.status); err != nil {
+		return err
+	}
+	if p.detail != "" {
+		if err := elem("detail", p.detail); err != nil {
+			return err
+		}
+	}
+	if p.instance != "" {
+		if err := elem("instance", p.instance); err != nil {
+			return err
+		}
+	}
+	for _, k := range p.extensionKeys() {
+		if err := elem(k, p.extensions[k]); err != nil {
+			return fmt.Errorf("problem extension %q: %w", k, err)
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// Problem converts a quick.Error into a Problem with the same status and message as detail.
+//
+// Example usage:
+//
+//	p := quick.NewError(400, "invalid id").Problem()
+func (e *Error) Problem() *Problem {
+	return NewProblem(e.Code).Detail(e.Message)
+}
+
+// Problem writes p as the response, choosing between JSON and XML from the Accept header.
+//
+// The response uses the problem status code and the content type
+// `application/problem+json` or `application/problem+xml`.
+//
+// Example Usage:
+//
+//	q.Get("/", func(c *quick.Ctx) error {
+//	    return c.Problem(quick.ProblemForbidden("read only account"))
+//	})
+func (c *Ctx) Problem(p *Problem) error {
+	c.Status(p.status)
+
+	if prefersXML(c) {
+		c.Set("Content-Type", ContentTypeProblemXML)
+		return c.XML(p)
+	}
+
+	c.Set("Content-Type", ContentTypeProblemJSON)
+	return c.JSON(p)
+}
+
+// problemOffers are the media types a problem can be rendered as.
+// JSON is listed first, so it wins ties and is used when Accept is empty.
+var problemOffers = []string{
+	ContentTypeProblemJSON, ContentTypeAppJSON,
+	ContentTypeProblemXML, ContentTypeAppXML, ContentTypeTextXML,
+}
+
+// prefersXML reports whether the client ranks an XML media type strictly above JSON.
+func prefersXML(c *Ctx) bool {
+	switch c.Accepts(problemOffers...) {
+	case ContentTypeProblemXML, ContentTypeAppXML, ContentTypeTextXML:
+		return true
+	}
+	return false
+}
+
+// ProblemBadRequest creates a 400 Bad Request problem with an optional detail.
+func ProblemBadRequest(detail ...string) *Problem {
+	return newProblemDetail(StatusBadRequest, detail)
+}
+
+// ProblemUnauthorized creates a 401 Unauthorized problem with an optional detail.
+func ProblemUnauthorized(detail ...string) *Problem {
+	return newProblemDetail(StatusUnauthorized, detail)
+}
+
+// ProblemForbidden creates a 403 Forbidden problem with an optional detail.
+func ProblemForbidden(detail ...string) *Problem {
+	return newProblemDetail(StatusForbidden, detail)
+}
+
+// ProblemNotFound creates a 404 Not Found problem with an optional detail.
+func ProblemNotFound(detail ...string) *Problem {
+	return newProblemDetail(StatusNotFound, detail)
+}
+
+// ProblemMethodNotAllowed creates a 405 Method Not Allowed problem with an optional detail.
+func ProblemMethodNotAllowed(detail ...string) *Problem {
+	return newProblemDetail(StatusMethodNotAllowed, detail)
+}
+
+// ProblemNotAcceptable creates a 406 Not Acceptable problem with an optional detail.
+func ProblemNotAcceptable(detail ...string) *Problem {
+	return newProblemDetail(StatusNotAcceptable, detail)
+}
+
+// ProblemConflict creates a 409 Conflict problem with an optional detail.
+func ProblemConflict(detail ...string) *Problem {
+	return newProblemDetail(StatusConflict, detail)
+}
+
+// ProblemGone creates a 410 Gone problem with an optional detail.
+func ProblemGone(detail ...string) *Problem {
+	return newProblemDetail(StatusGone, detail)
+}
+
+// ProblemRequestEntityTooLarge creates a 413 Request Entity Too Large problem with an optional detail.
+func ProblemRequestEntityTooLarge(detail ...string) *Problem {
+	return newProblemDetail(StatusRequestEntityTooLarge, detail)
+}
+
+// ProblemUnsupportedMediaType creates a 415 Unsupported Media Type problem with an optional detail.
+func ProblemUnsupportedMediaType(detail ...string) *Problem {
+	return newProblemDetail(StatusUnsupportedMediaType, detail)
+}
+
+// ProblemUnprocessableEntity creates a 422 Unprocessable Entity problem with an optional detail.
+func ProblemUnprocessableEntity(detail ...string) *Problem {
+	return newProblemDetail(StatusUnprocessableEntity, detail)
+}
+
+// ProblemTooManyRequests creates a 429 Too Many Requests problem with an optional detail.
+func ProblemTooManyRequests(detail ...string) *Problem {
+	return newProblemDetail(StatusTooManyRequests, detail)
+}
+
+// ProblemInternalServerError creates a 500 Internal Server Error problem with an optional detail.
+func ProblemInternalServerError(detail ...string) *Problem {
+	return newProblemDetail(StatusInternalServerError, detail)
+}
+
+// ProblemServiceUnavailable creates a 503 Service Unavailable problem with an optional detail.
+func ProblemServiceUnavailable(detail ...string) *Problem {
+	return newProblemDetail(StatusServiceUnavailable, detail)
+}
+
+// ProblemGatewayTimeout creates a 504 Gateway Timeout problem with an optional detail.
+func ProblemGatewayTimeout(detail ...string) *Problem {
+	return newProblemDetail(StatusGatewayTimeout, detail)
+}
+
+// newProblemDetail creates a Problem and sets the first detail, if any.
+func newProblemDetail(status int, detail []string) *Problem {
+	p := NewProblem(status)
+	if len(detail) > 0 {
+		p.detail = detail[0]
+	}
+	return p
+}
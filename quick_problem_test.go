@@ -0,0 +1,124 @@
+// Package quick provides a high-performance, minimalistic web framework for Go.
+//
+// This file contains **unit tests** for RFC 9457 problem details.
+//
+// 📌 To run all unit tests, use:
+//
+//	$ go test -v ./...
+//	$ go test -v -run ^TestProblem
+package quick
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestProblemMarshalJSON verifies member order, defaults and extensions of the JSON encoding.
+//
+// Run with:
+//
+//	go test -v -run ^TestProblemMarshalJSON
+func TestProblemMarshalJSON(t *testing.T) {
+	p := NewProblem(StatusForbidden).
+		Type("https://example.com/probs/out-of-credit").
+		Detail("Your current balance is 30, but that costs 50.").
+		Instance("/account/12345/msgs/abc").
+		With("balance", 30).
+		With("accounts", []string{"/account/12345"}).
+		With("status", 999) // standard members cannot be overridden
+
+	b, err := p.MarshalJSON()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `{"type":"https://example.com/probs/out-of-credit","title":"Forbidden","status":403,` +
+		`"detail":"Your current balance is 30, but that costs 50.","instance":"/account/12345/msgs/abc",` +
+		`"accounts":["/account/12345"],"balance":30}`
+	if string(b) != want {
+		t.Errorf("expected %s\ngot      %s", want, b)
+	}
+}
+
+// TestProblemMarshalXML verifies the RFC 7807 Appendix A XML encoding.
+//
+// Run with:
+//
+//	go test -v -run ^TestProblemMarshalXML
+func TestProblemMarshalXML(t *testing.T) {
+	b, err := xml.Marshal(ProblemNotFound("no such user").With("id", 7))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `<problem xmlns="urn:ietf:rfc:7807"><title>Not Found</title><status>404</status>` +
+		`<detail>no such user</detail><id>7</id></problem>`
+	if string(b) != want {
+		t.Errorf("expected %s\ngot      %s", want, b)
+	}
+}
+
+// TestProblemUnwrap verifies that the wrapped cause is reachable through the errors package.
+//
+// Run with:
+//
+//	go test -v -run ^TestProblemUnwrap
+func TestProblemUnwrap(t *testing.T) {
+	cause := errors.New("connection refused")
+	p := ProblemServiceUnavailable().Wrap(cause)
+
+	if !errors.Is(p, cause) {
+		t.Error("expected errors.Is to find the cause")
+	}
+	if p.Error() != "Service Unavailable" {
+		t.Errorf("expected title as error message, got %q", p.Error())
+	}
+	if p.Status() != StatusServiceUnavailable {
+		t.Errorf("expected status 503, got %d", p.Status())
+	}
+}
+
+// TestProblemRendering verifies that the default error handler renders problems
+// as JSON or XML according to the Accept header.
+//
+// Run with:
+//
+//	go test -v -run ^TestProblemRendering
+func TestProblemRendering(t *testing.T) {
+	q := New()
+	q.Get("/orders/:id", func(c *Ctx) error {
+		return NewProblem(StatusNotFound).Detail("order " + c.Param("id") + " not found")
+	})
+
+	tests := []struct {
+		accept      string
+		contentType string
+		body        string
+	}{
+		{"", ContentTypeProblemJSON, `{"title":"Not Found","status":404,"detail":"order 9 not found"}`},
+		{"application/json, application/xml;q=0.5", ContentTypeProblemJSON, `{"title":"Not Found","status":404,"detail":"order 9 not found"}`},
+		{"application/problem+xml", ContentTypeProblemXML, `<problem xmlns="urn:ietf:rfc:7807"><title>Not Found</title><status>404</status><detail>order 9 not found</detail></problem>`},
+		{"application/json;q=0.2, text/xml", ContentTypeProblemXML, `<problem xmlns="urn:ietf:rfc:7807"><title>Not Found</title><status>404</status><detail>order 9 not found</detail></problem>`},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(MethodGet, "/orders/9", nil)
+		if tt.accept != "" {
+			req.Header.Set("Accept", tt.accept)
+		}
+		rec := httptest.NewRecorder()
+		q.ServeHTTP(rec, req)
+
+		if rec.Code != StatusNotFound {
+			t.Errorf("Accept %q: expected 404, got %d", tt.accept, rec.Code)
+		}
+		if got := rec.Header().Get("Content-Type"); got != tt.contentType {
+			t.Errorf("Accept %q: expected Content-Type %q, got %q", tt.accept, tt.contentType, got)
+		}
+		if rec.Body.String() != tt.body {
+			t.Errorf("Accept %q: expected body %s, got %s", tt.accept, tt.body, rec.Body.String())
+		}
+	}
+}
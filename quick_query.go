@@ -0,0 +1,192 @@
+// Package quick provides a high-performance HTTP framework for building web applications in Go.
+//
+// This file implements multi-value and typed accessors for query parameters
+// and route parameters.
+//
+// Typed accessors return the default value when the key is missing or empty,
+// and a *BindError when the value cannot be converted. Returning that error
+// from a handler answers 400 Bad Request through the default error handler:
+//
+//	q.Get("/items", func(c *quick.Ctx) error {
+//	    page, err := c.QueryInt("page", 1)
+//	    if err != nil {
+//	        return err // 400: invalid query parameter "page": "x" is not a valid integer
+//	    }
+//	    return c.JSON(quick.M{"page": page})
+//	})
+package quick
+
+import (
+	"net/url"
+	"reflect"
+	"time"
+)
+
+// queryArgs returns the parsed query string, parsing it once per request.
+func (c *Ctx) queryArgs() url.Values {
+	if c.queryValues == nil {
+		c.queryValues = c.Request.URL.Query()
+	}
+	return c.queryValues
+}
+
+// QueryValues returns every value of a query parameter, in request order.
+//
+// Unlike the Query map, repeated keys are preserved (?tag=a&tag=b).
+//
+// Parameters:
+//   - key: The name of the query parameter.
+//
+// Returns:
+//   - []string: The values, or nil if the parameter is absent.
+//
+// Example Usage:
+//
+//	tags := c.QueryValues("tag") // ["a", "b"]
+func (c *Ctx) QueryValues(key string) []string {
+	return c.queryArgs()[key]
+}
+
+// QueryInt returns a query parameter converted to int.
+//
+// Parameters:
+//   - key: The name of the query parameter.
+//   - def: The value returned when the parameter is missing or empty.
+//
+// Returns:
+//   - int: The converted value, or def.
+//   - error: A *BindError (400) if the value is not a valid integer.
+//
+// Example Usage:
+//
+//	limit, err := c.QueryInt("limit", 20)
+func (c *Ctx) QueryInt(key string, def int) (int, error) {
+	return typedValue(bindTagQuery, key, c.queryArgs().Get(key), def, "")
+}
+
+// QueryBool returns a query parameter converted to bool.
+//
+// Accepted values are those of strconv.ParseBool ("1", "t", "true", "0", "f", "false", ...).
+//
+// Parameters:
+//   - key: The name of the query parameter.
+//   - def: The value returned when the parameter is missing or empty.
+//
+// Returns:
+//   - bool: The converted value, or def.
+//   - error: A *BindError (400) if the value is not a valid boolean.
+func (c *Ctx) QueryBool(key string, def bool) (bool, error) {
+	return typedValue(bindTagQuery, key, c.queryArgs().Get(key), def, "")
+}
+
+// QueryFloat returns a query parameter converted to float64.
+//
+// Parameters:
+//   - key: The name of the query parameter.
+//   - def: The value returned when the parameter is missing or empty.
+//
+// Returns:
+//   - float64: The converted value, or def.
+//   - error: A *BindError (400) if the value is not a valid number.
+func (c *Ctx) QueryFloat(key string, def float64) (float64, error) {
+	return typedValue(bindTagQuery, key, c.queryArgs().Get(key), def, "")
+}
+
+// QueryTime returns a query parameter parsed with the given layout.
+//
+// Parameters:
+//   - key: The name of the query parameter.
+//   - layout: The time layout (e.g. time.RFC3339 or "2006-01-02"); empty means time.RFC3339.
+//
+// Returns:
+//   - time.Time: The parsed time, or the zero time if the parameter is missing or empty.
+//   - error: A *BindError (400) if the value does not match the layout.
+//
+// Example Usage:
+//
+//	day, err := c.QueryTime("day", "2006-01-02")
+func (c *Ctx) QueryTime(key, layout string) (time.Time, error) {
+	return typedValue(bindTagQuery, key, c.queryArgs().Get(key), time.Time{}, layout)
+}
+
+// ParamInt returns a route parameter converted to int.
+//
+// Parameters:
+//   - key: The name of the route parameter (e.g. "id" for /users/:id).
+//   - def: The value returned when the parameter is missing or empty.
+//
+// Returns:
+//   - int: The converted value, or def.
+//   - error: A *BindError (400) if the value is not a valid integer.
+//
+// Example Usage:
+//
+//	id, err := c.ParamInt("id", 0)
+//	if err != nil {
+//	    return err
+//	}
+func (c *Ctx) ParamInt(key string, def int) (int, error) {
+	return typedValue(bindTagParam, key, c.Param(key), def, "")
+}
+
+// ParamBool returns a route parameter converted to bool.
+//
+// Parameters:
+//   - key: The name of the route parameter.
+//   - def: The value returned when the parameter is missing or empty.
+//
+// Returns:
+//   - bool: The converted value, or def.
+//   - error: A *BindError (400) if the value is not a valid boolean.
+func (c *Ctx) ParamBool(key string, def bool) (bool, error) {
+	return typedValue(bindTagParam, key, c.Param(key), def, "")
+}
+
+// ParamFloat returns a route parameter converted to float64.
+//
+// Parameters:
+//   - key: The name of the route parameter.
+//   - def: The value returned when the parameter is missing or empty.
+//
+// Returns:
+//   - float64: The converted value, or def.
+//   - error: A *BindError (400) if the value is not a valid number.
+func (c *Ctx) ParamFloat(key string, def float64) (float64, error) {
+	return typedValue(bindTagParam, key, c.Param(key), def, "")
+}
+
+// ParamTime returns a route parameter parsed with the given layout.
+//
+// Parameters:
+//   - key: The name of the route parameter.
+//   - layout: The time layout; empty means time.RFC3339.
+//
+// Returns:
+//   - time.Time: The parsed time, or the zero time if the parameter is missing or empty.
+//   - error: A *BindError (400) if the value does not match the layout.
+func (c *Ctx) ParamTime(key, layout string) (time.Time, error) {
+	return typedValue(bindTagParam, key, c.Param(key), time.Time{}, layout)
+}
+
+// typedValue converts raw with the same rules as the Bind* methods.
+//
+// Parameters:
+//   - source: The bind tag used in error messages ("query" or "param").
+//   - key: The parameter name.
+//   - raw: The raw value; empty returns def.
+//   - def: The default value, which also selects the target type.
+//   - layout: The time layout, used only for time.Time.
+//
+// Returns:
+//   - T: The converted value, or def.
+//   - error: A *BindError describing the invalid value.
+func typedValue[T any](source, key, raw string, def T, layout string) (T, error) {
+	if raw == "" {
+		return def, nil
+	}
+	var v T
+	if err := setBindString(reflect.ValueOf(&v).Elem(), raw, layout); err != nil {
+		return def, &BindError{Source: source, Key: key, Value: raw, Err: err}
+	}
+	return v, nil
+}
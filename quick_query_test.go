@@ -0,0 +1,105 @@
+// Package quick provides a high-performance, minimalistic web framework for Go.
+//
+// This file contains **unit tests** for multi-value and typed query/param accessors.
+//
+// 📌 To run all unit tests, use:
+//
+//	$ go test -v ./...
+//	$ go test -v -run ^TestQuery
+package quick
+
+import (
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestQueryTypedAccessors verifies conversions, defaults and errors of the typed accessors.
+//
+// Run with:
+//
+//	go test -v -run ^TestQueryTypedAccessors
+func TestQueryTypedAccessors(t *testing.T) {
+	c := &Ctx{
+		Request: httptest.NewRequest(MethodGet, "/?tag=a&tag=b&page=3&debug=true&ratio=0.5&day=2024-05-02&bad=x&empty=", nil),
+		Params:  map[string]string{"id": "42", "flag": "nope"},
+	}
+
+	if got := c.QueryValues("tag"); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("QueryValues: expected [a b], got %v", got)
+	}
+	if got := c.QueryValues("none"); got != nil {
+		t.Errorf("QueryValues: expected nil, got %v", got)
+	}
+
+	if n, err := c.QueryInt("page", 1); err != nil || n != 3 {
+		t.Errorf("QueryInt(page): got %d, %v", n, err)
+	}
+	if n, err := c.QueryInt("missing", 10); err != nil || n != 10 {
+		t.Errorf("QueryInt(missing): got %d, %v", n, err)
+	}
+	if n, err := c.QueryInt("empty", 5); err != nil || n != 5 {
+		t.Errorf("QueryInt(empty): got %d, %v", n, err)
+	}
+	if b, err := c.QueryBool("debug", false); err != nil || !b {
+		t.Errorf("QueryBool(debug): got %v, %v", b, err)
+	}
+	if f, err := c.QueryFloat("ratio", 1); err != nil || f != 0.5 {
+		t.Errorf("QueryFloat(ratio): got %v, %v", f, err)
+	}
+	if d, err := c.QueryTime("day", "2006-01-02"); err != nil || !d.Equal(time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("QueryTime(day): got %v, %v", d, err)
+	}
+	if id, err := c.ParamInt("id", 0); err != nil || id != 42 {
+		t.Errorf("ParamInt(id): got %d, %v", id, err)
+	}
+
+	n, err := c.QueryInt("bad", 7)
+	var berr *BindError
+	if !errors.As(err, &berr) || n != 7 || berr.Source != "query" || berr.Key != "bad" {
+		t.Errorf("QueryInt(bad): expected *BindError and default, got %d, %v", n, err)
+	}
+	if _, err := c.ParamBool("flag", false); err == nil || err.Error() != `invalid path parameter "flag": "nope" is not a valid boolean` {
+		t.Errorf("ParamBool(flag): unexpected error %v", err)
+	}
+}
+
+// TestQueryAccessorsErrorHandler verifies that errors returned by the typed
+// accessors are answered with 400 Bad Request.
+//
+// Run with:
+//
+//	go test -v -run ^TestQueryAccessorsErrorHandler
+func TestQueryAccessorsErrorHandler(t *testing.T) {
+	q := New()
+	q.Get("/users/:id", func(c *Ctx) error {
+		id, err := c.ParamInt("id", 0)
+		if err != nil {
+			return err
+		}
+		limit, err := c.QueryInt("limit", 20)
+		if err != nil {
+			return err
+		}
+		return c.JSON(M{"id": id, "limit": limit})
+	})
+
+	tests := []struct {
+		path string
+		code int
+	}{
+		{"/users/1", StatusOK},
+		{"/users/1?limit=5", StatusOK},
+		{"/users/abc", StatusBadRequest},
+		{"/users/1?limit=many", StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		q.ServeHTTP(rec, httptest.NewRequest(MethodGet, tt.path, nil))
+		if rec.Code != tt.code {
+			t.Errorf("%s: expected %d, got %d (%s)", tt.path, tt.code, rec.Code, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,397 @@
+// Package quick provides a high-performance HTTP framework for building web applications in Go.
+//
+// This file implements the route matcher used by `Quick.ServeHTTP`.
+//
+// Routes are compiled at registration time into one prefix tree per HTTP method.
+// Every edge of the tree is a path segment, so a lookup costs one step per segment
+// of the request path instead of one step per registered route.
+//
+// Supported segment syntax (unchanged from previous versions):
+//   - Static segments:  /users/profile
+//   - Named parameters: /users/:id
+//   - Regex parameters: /users/{id:[0-9]+} (compiled once, at registration)
+//   - Trailing wildcard: /static* or /assets/*
+//
+// Priority when several children could match the same segment:
+// static > regex parameter > named parameter > wildcard.
+// If a more specific branch fails deeper in the tree, the matcher backtracks
+// and tries the next candidate.
+package quick
+
+import (
+	"errors"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Errors returned by the router when a route cannot be inserted in the tree.
+var (
+	errRouteExists      = errors.New("is already registered, ignoring duplicate registration")
+	errRouteInvalidName = errors.New("has a parameter without name, ignoring registration")
+)
+
+// routeParam holds a single path parameter extracted during route matching.
+//
+// Both key and value point to existing strings (the registered pattern and the
+// request path), so collecting parameters does not allocate.
+type routeParam struct {
+	key   string
+	value string
+}
+
+// routeParams is a reusable list of parameters acquired from paramsPool.
+type routeParams struct {
+	list []routeParam
+}
+
+// paramsPool reuses parameter lists between requests.
+var paramsPool = sync.Pool{
+	New: func() interface{} {
+		return &routeParams{list: make([]routeParam, 0, 8)}
+	},
+}
+
+// acquireParams retrieves an empty parameter list from the pool.
+func acquireParams() *routeParams {
+	return paramsPool.Get().(*routeParams)
+}
+
+// releaseParams resets a parameter list and returns it to the pool.
+func releaseParams(ps *routeParams) {
+	ps.list = ps.list[:0]
+	paramsPool.Put(ps)
+}
+
+// wildcardRoute is a trailing `*` entry attached to a node.
+//
+// The prefix holds the partial segment written before the `*`
+// (e.g. "static" for "/static*", "" for "/assets/*").
+type wildcardRoute struct {
+	prefix string
+	route  *Route
+}
+
+// node is a single segment of the routing tree.
+//
+// Fields:
+//   - name: The parameter name for regex and named parameter nodes.
+//   - regex: The precompiled expression for regex parameter nodes.
+//   - route: The route that ends at this node, if any.
+//   - static: Children indexed by their literal segment.
+//   - regexps: Regex parameter children, in registration order.
+//   - params: Named parameter children, in registration order.
+//   - wildcards: Trailing wildcard routes, longest prefix first.
+type node struct {
+	name      string
+	regex     *regexp.Regexp
+	route     *Route
+	static    map[string]*node
+	regexps   []*node
+	params    []*node
+	wildcards []wildcardRoute
+}
+
+// router keeps one routing tree per HTTP method.
+type router struct {
+	trees map[string]*node
+}
+
+// newRouter creates an empty router.
+func newRouter() *router {
+	return &router{trees: make(map[string]*node)}
+}
+
+// add compiles the pattern and inserts the route into the tree of the given method.
+//
+// Parameters:
+//   - method: The HTTP method of the route (e.g. "GET").
+//   - pattern: The full route pattern (e.g. "/v1/users/{id:[0-9]+}").
+//   - route: The route to be returned when the pattern matches.
+//
+// Returns:
+//   - error: errRouteExists if the same pattern is already registered for the method,
+//     or an error describing an invalid segment.
+func (r *router) add(method, pattern string, route *Route) error {
+	root, ok := r.trees[method]
+	if !ok {
+		root = &node{}
+		r.trees[method] = root
+	}
+
+	s := strings.TrimPrefix(path.Clean("/"+pattern), "/")
+
+	// A trailing `*` turns the last (partial) segment into a wildcard prefix.
+	if strings.HasSuffix(s, "*") {
+		base := strings.TrimSuffix(s, "*")
+		prefix := base
+		n := root
+		if i := strings.LastIndexByte(base, '/'); i >= 0 {
+			prefix = base[i+1:]
+			var err error
+			if n, err = n.insert(strings.Split(base[:i], "/")); err != nil {
+				return err
+			}
+		}
+		for _, w := range n.wildcards {
+			if w.prefix == prefix {
+				return errRouteExists
+			}
+		}
+		n.wildcards = append(n.wildcards, wildcardRoute{prefix: prefix, route: route})
+		sort.SliceStable(n.wildcards, func(i, j int) bool {
+			return len(n.wildcards[i].prefix) > len(n.wildcards[j].prefix)
+		})
+		return nil
+	}
+
+	n, err := root.insert(strings.Split(s, "/"))
+	if err != nil {
+		return err
+	}
+	if n.route != nil {
+		return errRouteExists
+	}
+	n.route = route
+	return nil
+}
+
+// insert walks (and creates when needed) the nodes for the given segments.
+//
+// Parameters:
+//   - segments: The pattern split by "/".
+//
+// Returns:
+//   - *node: The node that represents the last segment.
+//   - error: An error if a parameter segment is invalid.
+func (n *node) insert(segments []string) (*node, error) {
+	for _, seg := range segments {
+		child, err := n.child(seg)
+		if err != nil {
+			return nil, err
+		}
+		n = child
+	}
+	return n, nil
+}
+
+// child returns the node that represents seg below n, creating it if needed.
+//
+// Parameters:
+//   - seg: A single pattern segment (":id", "{id:[0-9]+}" or a literal).
+//
+// Returns:
+//   - *node: The existing or newly created child node.
+//   - error: An error if the segment declares an invalid parameter.
+func (n *node) child(seg string) (*node, error) {
+	switch {
+	case strings.HasPrefix(seg, ":"):
+		name := seg[1:]
+		if name == "" {
+			return nil, errRouteInvalidName
+		}
+		for _, c := range n.params {
+			if c.name == name {
+				return c, nil
+			}
+		}
+		c := &node{name: name}
+		n.params = append(n.params, c)
+		return c, nil
+
+	case len(seg) > 1 && seg[0] == '{' && seg[len(seg)-1] == '}':
+		name, expr, hasRegex := strings.Cut(seg[1:len(seg)-1], ":")
+		if name == "" {
+			return nil, errRouteInvalidName
+		}
+		if !hasRegex {
+			// "{id}" behaves like ":id"
+			return n.child(":" + name)
+		}
+		for _, c := range n.regexps {
+			if c.name == name && c.regex.String() == "^(?:"+expr+")$" {
+				return c, nil
+			}
+		}
+		rgx, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return nil, errors.New("has an invalid regex: " + err.Error())
+		}
+		c := &node{name: name, regex: rgx}
+		n.regexps = append(n.regexps, c)
+		return c, nil
+
+	default:
+		if n.static == nil {
+			n.static = make(map[string]*node)
+		}
+		c, ok := n.static[seg]
+		if !ok {
+			c = &node{}
+			n.static[seg] = c
+		}
+		return c, nil
+	}
+}
+
+// find looks up the route registered for method that matches reqPath.
+//
+// Parameters:
+//   - method: The HTTP method of the request.
+//   - reqPath: The cleaned request path (see path.Clean).
+//   - ps: The list receiving the extracted parameters.
+//
+// Returns:
+//   - *Route: The matched route, or nil if no route matches.
+func (r *router) find(method, reqPath string, ps *routeParams) *Route {
+	if r == nil {
+		return nil
+	}
+	root, ok := r.trees[method]
+	if !ok {
+		return nil
+	}
+	return root.match(strings.TrimPrefix(reqPath, "/"), 0, ps)
+}
+
+// allowed returns the methods that have a route matching reqPath.
+//
+// Methods are listed in the order of allMethods, followed by any custom
+// method in alphabetical order. OPTIONS is always included when at least one
+// route matches, since Quick answers OPTIONS requests automatically.
+//
+// Parameters:
+//   - reqPath: The cleaned request path, or "*" to list every registered method.
+//
+// Returns:
+//   - []string: The allowed methods, or nil if no route matches the path.
+func (r *router) allowed(reqPath string) []string {
+	if r == nil || len(r.trees) == 0 {
+		return nil
+	}
+
+	methods := make([]string, 0, len(r.trees)+1)
+	hasOptions := false
+	for method := range r.trees {
+		if reqPath != "*" {
+			ps := acquireParams()
+			route := r.find(method, reqPath, ps)
+			releaseParams(ps)
+			if route == nil {
+				continue
+			}
+		}
+		hasOptions = hasOptions || method == MethodOptions
+		methods = append(methods, method)
+	}
+	if len(methods) == 0 {
+		return nil
+	}
+	if !hasOptions {
+		methods = append(methods, MethodOptions)
+	}
+
+	sort.Slice(methods, func(i, j int) bool {
+		oi, oj := methodOrder(methods[i]), methodOrder(methods[j])
+		if oi != oj {
+			return oi < oj
+		}
+		return methods[i] < methods[j]
+	})
+	return methods
+}
+
+// methodOrder returns the position of method in allMethods,
+// or len(allMethods) for custom methods.
+func methodOrder(method string) int {
+	for i, m := range allMethods {
+		if m == method {
+			return i
+		}
+	}
+	return len(allMethods)
+}
+
+// match tries to match the segment of p starting at start against the children of n.
+//
+// Parameters:
+//   - p: The request path without its leading slash.
+//   - start: The index in p where the current segment begins.
+//   - ps: The list receiving the extracted parameters.
+//
+// Returns:
+//   - *Route: The matched route, or nil if this branch does not match.
+func (n *node) match(p string, start int, ps *routeParams) *Route {
+	end := strings.IndexByte(p[start:], '/')
+	last := end < 0
+	if last {
+		end = len(p)
+	} else {
+		end += start
+	}
+	seg := p[start:end]
+
+	// 1. static
+	if c, ok := n.static[seg]; ok {
+		if r := c.next(p, end, last, ps); r != nil {
+			return r
+		}
+	}
+
+	// 2. regex parameters
+	for _, c := range n.regexps {
+		if !c.regex.MatchString(seg) {
+			continue
+		}
+		mark := len(ps.list)
+		ps.list = append(ps.list, routeParam{key: c.name, value: seg})
+		if r := c.next(p, end, last, ps); r != nil {
+			return r
+		}
+		ps.list = ps.list[:mark]
+	}
+
+	// 3. named parameters
+	for _, c := range n.params {
+		mark := len(ps.list)
+		ps.list = append(ps.list, routeParam{key: c.name, value: seg})
+		if r := c.next(p, end, last, ps); r != nil {
+			return r
+		}
+		ps.list = ps.list[:mark]
+	}
+
+	// 4. wildcards
+	rest := p[start:]
+	for _, w := range n.wildcards {
+		if strings.HasPrefix(rest, w.prefix) {
+			return w.route
+		}
+	}
+	return nil
+}
+
+// next continues matching below n once the segment ending at end was accepted.
+func (n *node) next(p string, end int, last bool, ps *routeParams) *Route {
+	if last {
+		return n.route
+	}
+	return n.match(p, end+1, ps)
+}
+
+// setParams copies the parameters extracted by the router into the Ctx.
+//
+// The pooled Params map is reused, so no map is allocated per request.
+//
+// Parameters:
+//   - params: The parameters collected during route matching.
+func (c *Ctx) setParams(params []routeParam) {
+	if c.Params == nil {
+		c.Params = make(map[string]string, len(params))
+	}
+	for _, p := range params {
+		c.Params[p.key] = p.value
+	}
+}
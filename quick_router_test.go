@@ -0,0 +1,281 @@
+// Package quick provides a high-performance, minimalistic web framework for Go.
+//
+// This file contains **unit tests** for the routing tree used by ServeHTTP.
+//
+// 📌 To run all unit tests, use:
+//
+//	$ go test -v ./...
+//	$ go test -v -run ^TestRouter
+package quick
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestRouterFind verifies pattern matching, parameter extraction and priorities of the routing tree.
+//
+// Run with:
+//
+//	go test -v -run ^TestRouterFind
+func TestRouterFind(t *testing.T) {
+	r := newRouter()
+	patterns := []string{
+		"/",
+		"/users",
+		"/users/me",
+		"/users/:id",
+		"/users/:id/posts",
+		"/users/{id:[0-9]+}/settings",
+		"/api/{version:v[0-9]+}/users/{id:[0-9]+}",
+		"/files/{name}",
+		"/static*",
+		"/assets/*",
+	}
+	routes := make(map[string]*Route)
+	for _, p := range patterns {
+		route := &Route{Method: MethodGet, Path: p}
+		if err := r.add(MethodGet, p, route); err != nil {
+			t.Fatalf("add(%q) returned error: %v", p, err)
+		}
+		routes[p] = route
+	}
+
+	tests := []struct {
+		path    string
+		pattern string
+		params  map[string]string
+	}{
+		{"/", "/", nil},
+		{"/users", "/users", nil},
+		{"/users/me", "/users/me", nil},
+		{"/users/42", "/users/:id", map[string]string{"id": "42"}},
+		{"/users/me/posts", "/users/:id/posts", map[string]string{"id": "me"}},
+		{"/users/42/settings", "/users/{id:[0-9]+}/settings", map[string]string{"id": "42"}},
+		{"/users/abc/settings", "", nil},
+		{"/api/v2/users/7", "/api/{version:v[0-9]+}/users/{id:[0-9]+}", map[string]string{"version": "v2", "id": "7"}},
+		{"/api/x2/users/7", "", nil},
+		{"/files/report.pdf", "/files/{name}", map[string]string{"name": "report.pdf"}},
+		{"/static", "/static*", nil},
+		{"/staticfiles/app.js", "/static*", nil},
+		{"/assets/css/site.css", "/assets/*", nil},
+		{"/assets", "", nil},
+		{"/unknown", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			ps := acquireParams()
+			defer releaseParams(ps)
+
+			got := r.find(MethodGet, tt.path, ps)
+			if tt.pattern == "" {
+				if got != nil {
+					t.Fatalf("expected no match, got %q", got.Path)
+				}
+				return
+			}
+			if got != routes[tt.pattern] {
+				t.Fatalf("expected route %q, got %v", tt.pattern, got)
+			}
+			if len(ps.list) != len(tt.params) {
+				t.Fatalf("expected %d params, got %v", len(tt.params), ps.list)
+			}
+			for _, p := range ps.list {
+				if tt.params[p.key] != p.value {
+					t.Errorf("param %q: expected %q, got %q", p.key, tt.params[p.key], p.value)
+				}
+			}
+		})
+	}
+}
+
+// TestRouterAddErrors verifies that duplicated and invalid patterns are rejected.
+//
+// Run with:
+//
+//	go test -v -run ^TestRouterAddErrors
+func TestRouterAddErrors(t *testing.T) {
+	r := newRouter()
+	if err := r.add(MethodGet, "/users/:id", &Route{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.add(MethodGet, "/users/:id", &Route{}); err != errRouteExists {
+		t.Errorf("expected errRouteExists, got %v", err)
+	}
+	if err := r.add(MethodPost, "/users/:id", &Route{}); err != nil {
+		t.Errorf("same pattern on another method must be accepted, got %v", err)
+	}
+	if err := r.add(MethodGet, "/debug*", &Route{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.add(MethodGet, "/debug*", &Route{}); err != errRouteExists {
+		t.Errorf("expected errRouteExists for wildcard, got %v", err)
+	}
+	for _, p := range []string{"/users/:", "/number/{:[0-9]+}", "/bad/{id:[0-9}"} {
+		if err := r.add(MethodGet, p, &Route{}); err == nil {
+			t.Errorf("expected error for %q", p)
+		}
+	}
+}
+
+// TestRouterFindNoAllocs ensures that matching and parameter extraction do not allocate.
+//
+// Run with:
+//
+//	go test -v -run ^TestRouterFindNoAllocs
+func TestRouterFindNoAllocs(t *testing.T) {
+	r := newRouter()
+	r.add(MethodGet, "/api/{version:v[0-9]+}/users/:id/posts/:post", &Route{})
+
+	ps := acquireParams()
+	defer releaseParams(ps)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		ps.list = ps.list[:0]
+		if r.find(MethodGet, "/api/v1/users/10/posts/20", ps) == nil {
+			t.Fatal("expected a match")
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("expected 0 allocations, got %v", allocs)
+	}
+}
+
+// TestServeHTTPRouteAndSubRoute ensures that a route and its parameterized
+// sub route can both be registered and served.
+//
+// Run with:
+//
+//	go test -v -run ^TestServeHTTPRouteAndSubRoute
+func TestServeHTTPRouteAndSubRoute(t *testing.T) {
+	q := New()
+	q.Get("/users", func(c *Ctx) error {
+		return c.String("list")
+	})
+	q.Get("/users/:id", func(c *Ctx) error {
+		return c.String("user " + c.Param("id"))
+	})
+	q.Post("/users/:id", func(c *Ctx) error {
+		return c.String("post " + c.Param("id"))
+	})
+
+	tests := []struct {
+		method string
+		path   string
+		code   int
+		body   string
+	}{
+		{MethodGet, "/users", StatusOK, "list"},
+		{MethodGet, "/users/7", StatusOK, "user 7"},
+		{MethodPost, "/users/8", StatusOK, "post 8"},
+		{MethodGet, "/users/7/x", StatusNotFound, "404 page not found\n"},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		q.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+		if rec.Code != tt.code || rec.Body.String() != tt.body {
+			t.Errorf("%s %s: expected %d %q, got %d %q", tt.method, tt.path, tt.code, tt.body, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+// BenchmarkServeHTTPManyRoutes measures lookup cost of the last registered route
+// among many registered routes.
+//
+// Run with:
+//
+//	go test -bench=BenchmarkServeHTTPManyRoutes -benchmem
+func BenchmarkServeHTTPManyRoutes(b *testing.B) {
+	q := New()
+	for i := 0; i < 600; i++ {
+		q.Get(fmt.Sprintf("/v1/resource%d/:id", i), func(c *Ctx) error {
+			return nil
+		})
+	}
+	req := httptest.NewRequest(http.MethodGet, "/v1/resource599/10", nil)
+	rec := httptest.NewRecorder()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q.ServeHTTP(rec, req)
+	}
+}
+
+// TestServeHTTPMethodNotAllowed verifies that a path registered only for other
+// methods answers 405 with an accurate Allow header.
+//
+// Run with:
+//
+//	go test -v -run ^TestServeHTTPMethodNotAllowed
+func TestServeHTTPMethodNotAllowed(t *testing.T) {
+	q := New()
+	handler := func(c *Ctx) error { return c.String("ok") }
+	q.Get("/users/:id", handler)
+	q.Delete("/users/:id", handler)
+	q.Post("/users", handler)
+
+	tests := []struct {
+		method string
+		path   string
+		code   int
+		allow  string
+	}{
+		{MethodPut, "/users/10", StatusMethodNotAllowed, "GET, DELETE, OPTIONS"},
+		{MethodGet, "/users", StatusMethodNotAllowed, "POST, OPTIONS"},
+		{MethodPut, "/missing", StatusNotFound, ""},
+		{MethodGet, "/users/10", StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		q.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+		if rec.Code != tt.code {
+			t.Errorf("%s %s: expected status %d, got %d", tt.method, tt.path, tt.code, rec.Code)
+		}
+		if got := rec.Header().Get("Allow"); got != tt.allow {
+			t.Errorf("%s %s: expected Allow %q, got %q", tt.method, tt.path, tt.allow, got)
+		}
+	}
+}
+
+// TestHandleOptionsAllow verifies that OPTIONS requests without Origin
+// receive the methods registered for the requested path.
+//
+// Run with:
+//
+//	go test -v -run ^TestHandleOptionsAllow
+func TestHandleOptionsAllow(t *testing.T) {
+	q := New()
+	handler := func(c *Ctx) error { return nil }
+	q.Get("/items", handler)
+	q.Post("/items", handler)
+	q.Patch("/items/:id", handler)
+
+	tests := []struct {
+		path  string
+		code  int
+		allow string
+	}{
+		{"/items", StatusNoContent, "GET, POST, OPTIONS"},
+		{"/items/3", StatusNoContent, "PATCH, OPTIONS"},
+		{"*", StatusNoContent, "GET, POST, PATCH, OPTIONS"},
+		{"/nothing", StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(MethodOptions, "/", nil)
+		req.URL.Path = tt.path
+		rec := httptest.NewRecorder()
+		q.ServeHTTP(rec, req)
+		if rec.Code != tt.code {
+			t.Errorf("OPTIONS %s: expected status %d, got %d", tt.path, tt.code, rec.Code)
+		}
+		if got := rec.Header().Get("Allow"); got != tt.allow {
+			t.Errorf("OPTIONS %s: expected Allow %q, got %q", tt.path, tt.allow, got)
+		}
+	}
+}
@@ -979,3 +979,122 @@ func TestExtractParamsBind_BodyReadError(t *testing.T) {
 		t.Fatal("Expected an error due to body read failure, got nil")
 	}
 }
+
+// TestConfigErrorHandler verifies that handler errors are rendered by the configured ErrorHandler,
+// and that the default rendering is kept when no handler is configured.
+//
+// To run:
+//
+//	$ go test -v -run ^TestConfigErrorHandler
+func TestConfigErrorHandler(t *testing.T) {
+	t.Run("default error handler", func(t *testing.T) {
+		q := New()
+		q.Get("/quick", func(c *Ctx) error { return NewError(StatusConflict, "conflict") })
+		q.Get("/plain", func(c *Ctx) error { return errors.New("db down") })
+
+		rec := httptest.NewRecorder()
+		q.ServeHTTP(rec, httptest.NewRequest(MethodGet, "/quick", nil))
+		if rec.Code != StatusConflict || rec.Body.String() != `{"message":"conflict","code":409}` {
+			t.Errorf("unexpected response: %d %q", rec.Code, rec.Body.String())
+		}
+
+		rec = httptest.NewRecorder()
+		q.ServeHTTP(rec, httptest.NewRequest(MethodGet, "/plain", nil))
+		if rec.Code != StatusInternalServerError || rec.Body.String() != "db down" {
+			t.Errorf("unexpected response: %d %q", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("custom error handler", func(t *testing.T) {
+		var logged error
+		q := New()
+		q.SetErrorHandler(func(c *Ctx, err error) error {
+			logged = err
+			return c.Status(StatusInternalServerError).JSON(M{"error": "internal"})
+		})
+		q.Post("/fail", func(c *Ctx) error { return errors.New("secret detail") })
+
+		rec := httptest.NewRecorder()
+		q.ServeHTTP(rec, httptest.NewRequest(MethodPost, "/fail", nil))
+		if rec.Code != StatusInternalServerError || rec.Body.String() != `{"error":"internal"}` {
+			t.Errorf("unexpected response: %d %q", rec.Code, rec.Body.String())
+		}
+		if logged == nil || logged.Error() != "secret detail" {
+			t.Errorf("expected error to reach the handler, got %v", logged)
+		}
+	})
+
+	t.Run("HandleError from a net/http middleware", func(t *testing.T) {
+		q := New()
+		q.SetErrorHandler(func(c *Ctx, err error) error {
+			return c.Status(StatusForbidden).String("custom: " + err.Error())
+		})
+		q.Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				HandleError(&Ctx{Response: w, Request: r}, NewError(StatusForbidden, "denied"))
+			})
+		})
+		q.Get("/private", func(c *Ctx) error { return nil })
+
+		rec := httptest.NewRecorder()
+		q.ServeHTTP(rec, httptest.NewRequest(MethodGet, "/private", nil))
+		if rec.Code != StatusForbidden || rec.Body.String() != "custom: denied" {
+			t.Errorf("expected the app error handler, got %d %q", rec.Code, rec.Body.String())
+		}
+
+		// Outside an app, the default handler renders the error
+		rec = httptest.NewRecorder()
+		HandleError(&Ctx{Response: rec, Request: httptest.NewRequest(MethodGet, "/", nil)}, NewError(StatusForbidden, "denied"))
+		if rec.Code != StatusForbidden || rec.Body.String() != `{"message":"denied","code":403}` {
+			t.Errorf("expected the default error handler, got %d %q", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("failing error handler hides the error", func(t *testing.T) {
+		q := New()
+		q.SetErrorHandler(func(c *Ctx, err error) error { return err })
+		q.Get("/fail", func(c *Ctx) error { return errors.New("secret detail") })
+
+		rec := httptest.NewRecorder()
+		q.ServeHTTP(rec, httptest.NewRequest(MethodGet, "/fail", nil))
+		if rec.Code != StatusInternalServerError || strings.Contains(rec.Body.String(), "secret") {
+			t.Errorf("unexpected response: %d %q", rec.Code, rec.Body.String())
+		}
+	})
+}
+
+// TestConfigNotFoundHandlers verifies the configurable NotFound and MethodNotAllowed handlers.
+//
+// To run:
+//
+//	$ go test -v -run ^TestConfigNotFoundHandlers
+func TestConfigNotFoundHandlers(t *testing.T) {
+	q := New()
+	q.SetNotFoundHandler(func(c *Ctx) error {
+		return c.JSON(M{"error": "not found", "path": c.Path()})
+	})
+	q.SetMethodNotAllowedHandler(func(c *Ctx) error {
+		return NewError(StatusMethodNotAllowed, "use "+c.Response.Header().Get("Allow"))
+	})
+	q.Get("/users", func(c *Ctx) error { return c.String("ok") })
+
+	rec := httptest.NewRecorder()
+	q.ServeHTTP(rec, httptest.NewRequest(MethodGet, "/nothing", nil))
+	if rec.Code != StatusNotFound || rec.Body.String() != `{"error":"not found","path":"/nothing"}` {
+		t.Errorf("unexpected 404 response: %d %q", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	q.ServeHTTP(rec, httptest.NewRequest(MethodDelete, "/users", nil))
+	if rec.Code != StatusMethodNotAllowed || rec.Body.String() != `{"message":"use GET, OPTIONS","code":405}` {
+		t.Errorf("unexpected 405 response: %d %q", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Allow"); got != "GET, OPTIONS" {
+		t.Errorf("expected Allow header, got %q", got)
+	}
+	rec = httptest.NewRecorder()
+	q.ServeHTTP(rec, httptest.NewRequest(MethodOptions, "/nothing", nil))
+	if rec.Code != StatusNotFound || rec.Body.String() != `{"error":"not found","path":"/nothing"}` {
+		t.Errorf("unexpected OPTIONS 404 response: %d %q", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,416 @@
+// Package quick provides a high-performance HTTP framework for building web applications in Go.
+//
+// This file implements the built-in struct validation engine used by `Ctx.Bind`
+// and `Ctx.BodyParser`, driven by the `validate` struct tag. It has no external
+// dependencies.
+//
+// Supported rules:
+//   - required: the value must not be the zero value (non-nil for pointers, non-empty for strings/slices/maps).
+//   - omitempty: skip the remaining rules when the value is the zero value.
+//   - min=N, max=N, len=N: length for strings (in runes), slices and maps; value for numbers.
+//   - gt=N, gte=N, lt=N, lte=N: same as above, with strict/inclusive comparisons.
+//   - oneof=a b c: the value must be one of the space-separated options.
+//   - email, url, uuid: the string must be a valid e-mail address, absolute URL or UUID.
+//   - alpha, alphanum, numeric: the string must contain only letters, letters and digits, or digits.
+//
+// Nested structs, pointers to structs and slices, arrays or maps of structs
+// are validated recursively. Errors carry the path of the field, built from
+// the `json` tag or a binding tag when present (e.g. "address.zip" or "items[0].name").
+//
+// Example:
+//
+//	type User struct {
+//	    Name  string `json:"name" validate:"required,min=3,max=50"`
+//	    Email string `json:"email" validate:"required,email"`
+//	    Role  string `json:"role" validate:"oneof=admin user"`
+//	}
+package quick
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// FieldError describes a single failed validation rule.
+//
+// Fields:
+//   - Field: The path of the field (e.g. "address.zip", "items[0].name").
+//   - Tag: The rule that failed (e.g. "required", "min").
+//   - Param: The rule parameter, if any (e.g. "3" for min=3).
+//   - Message: A human-readable message.
+type FieldError struct {
+	Field   string `json:"field" xml:"field"`
+	Tag     string `json:"tag" xml:"tag"`
+	Param   string `json:"param,omitempty" xml:"param,omitempty"`
+	Message string `json:"message" xml:"message"`
+}
+
+// Error implements the error interface.
+func (fe FieldError) Error() string {
+	return fe.Message
+}
+
+// ValidationErrors is the list of rules that failed while validating a struct.
+//
+// The default error handler renders it as a 422 Unprocessable Entity problem
+// with the list of fields in the "errors" member.
+type ValidationErrors []FieldError
+
+// Error implements the error interface, joining all messages.
+func (ve ValidationErrors) Error() string {
+	msgs := make([]string, len(ve))
+	for i, fe := range ve {
+		msgs[i] = fe.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Problem converts the validation errors into a 422 Unprocessable Entity problem.
+//
+// Example Usage:
+//
+//	if verrs, ok := err.(quick.ValidationErrors); ok {
+//	    return c.Problem(verrs.Problem())
+//	}
+func (ve ValidationErrors) Problem() *Problem {
+	return ProblemUnprocessableEntity("request validation failed").With("errors", []FieldError(ve))
+}
+
+// Validate checks v against the `validate` tags of its fields.
+//
+// v must be a struct or a pointer to a struct; other values are ignored.
+//
+// Returns:
+//   - error: nil when every rule passes, ValidationErrors listing each failure,
+//     or a plain error when a tag is malformed.
+//
+// Example Usage:
+//
+//	var u User
+//	if err := quick.Validate(&u); err != nil {
+//	    return err // rendered as 422 by the default error handler
+//	}
+func Validate(v any) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var errs ValidationErrors
+	if err := validateStruct(rv, "", &errs); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validationRule is a compiled rule of a `validate` tag.
+type validationRule struct {
+	tag     string
+	param   string
+	num     float64  // numeric parameter of min/max/len/gt/gte/lt/lte
+	options []string // options of oneof
+}
+
+// validationField is a compiled struct field.
+type validationField struct {
+	index     int
+	name      string
+	required  bool
+	omitempty bool
+	rules     []validationRule
+}
+
+// validationStruct is the compiled validation plan of a struct type.
+type validationStruct struct {
+	fields []validationField
+	err    error
+}
+
+// validationCache stores one plan per struct type, so tags are parsed only once.
+var validationCache sync.Map // map[reflect.Type]*validationStruct
+
+// validationPlan returns the compiled plan of t, compiling it on first use.
+func validationPlan(t reflect.Type) *validationStruct {
+	if plan, ok := validationCache.Load(t); ok {
+		return plan.(*validationStruct)
+	}
+
+	plan := &validationStruct{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		field := validationField{index: i, name: fieldPathName(sf)}
+		tag := sf.Tag.Get("validate")
+		if tag == "-" {
+			continue
+		}
+		for _, part := range strings.Split(tag, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			rule, err := parseValidationRule(part)
+			if err != nil {
+				plan.err = fmt.Errorf("quick: invalid validate tag on %s.%s: %w", t.Name(), sf.Name, err)
+				break
+			}
+			switch rule.tag {
+			case "required":
+				field.required = true
+			case "omitempty":
+				field.omitempty = true
+			default:
+				field.rules = append(field.rules, rule)
+			}
+		}
+		plan.fields = append(plan.fields, field)
+	}
+
+	actual, _ := validationCache.LoadOrStore(t, plan)
+	return actual.(*validationStruct)
+}
+
+// fieldPathName returns the name used for sf in error paths: the json tag,
+// then the first binding tag (see BindAll), or the Go name.
+func fieldPathName(sf reflect.StructField) string {
+	for _, tag := range []string{"json", bindTagForm, bindTagQuery, bindTagParam, bindTagHeader, bindTagCookie} {
+		if name, _, _ := strings.Cut(sf.Tag.Get(tag), ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return sf.Name
+}
+
+// parseValidationRule compiles a single "name=param" rule.
+func parseValidationRule(part string) (validationRule, error) {
+	tag, param, _ := strings.Cut(part, "=")
+	rule := validationRule{tag: tag, param: param}
+
+	switch tag {
+	case "required", "omitempty", "email", "url", "uuid", "alpha", "alphanum", "numeric":
+		if param != "" {
+			return rule, fmt.Errorf("rule %q does not take a parameter", tag)
+		}
+	case "min", "max", "len", "gt", "gte", "lt", "lte":
+		n, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return rule, fmt.Errorf("rule %q needs a numeric parameter", tag)
+		}
+		rule.num = n
+	case "oneof":
+		rule.options = strings.Fields(param)
+		if len(rule.options) == 0 {
+			return rule, errors.New(`rule "oneof" needs at least one option`)
+		}
+	default:
+		return rule, fmt.Errorf("unknown rule %q", tag)
+	}
+	return rule, nil
+}
+
+// validateStruct applies the plan of rv's type and recurses into nested values.
+func validateStruct(rv reflect.Value, prefix string, errs *ValidationErrors) error {
+	plan := validationPlan(rv.Type())
+	if plan.err != nil {
+		return plan.err
+	}
+
+	for _, f := range plan.fields {
+		fv := rv.Field(f.index)
+		path := f.name
+		if prefix != "" {
+			path = prefix + "." + f.name
+		}
+
+		if fv.IsZero() {
+			if f.required {
+				*errs = append(*errs, FieldError{Field: path, Tag: "required", Message: path + " is required"})
+				continue
+			}
+			if f.omitempty {
+				continue
+			}
+		}
+
+		// Rules apply to the value pointed to; nil pointers have nothing else to check.
+		for fv.Kind() == reflect.Pointer || fv.Kind() == reflect.Interface {
+			if fv.IsNil() {
+				break
+			}
+			fv = fv.Elem()
+		}
+		if (fv.Kind() == reflect.Pointer || fv.Kind() == reflect.Interface) && fv.IsNil() {
+			continue
+		}
+
+		for _, rule := range f.rules {
+			if msg, ok := checkRule(rule, fv); !ok {
+				*errs = append(*errs, FieldError{Field: path, Tag: rule.tag, Param: rule.param, Message: path + " " + msg})
+			}
+		}
+
+		if err := validateNested(fv, path, errs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateNested recurses into structs and collections of structs.
+func validateNested(fv reflect.Value, path string, errs *ValidationErrors) error {
+	switch fv.Kind() {
+	case reflect.Struct:
+		return validateStruct(fv, path, errs)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < fv.Len(); i++ {
+			if err := validateElem(fv.Index(i), path+"["+strconv.Itoa(i)+"]", errs); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := fv.MapRange()
+		for iter.Next() {
+			if err := validateElem(iter.Value(), path+"["+fmt.Sprint(iter.Key().Interface())+"]", errs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateElem validates an element of a collection when it is a struct (or a pointer to one).
+func validateElem(ev reflect.Value, path string, errs *ValidationErrors) error {
+	for ev.Kind() == reflect.Pointer || ev.Kind() == reflect.Interface {
+		if ev.IsNil() {
+			return nil
+		}
+		ev = ev.Elem()
+	}
+	if ev.Kind() == reflect.Struct {
+		return validateStruct(ev, path, errs)
+	}
+	return nil
+}
+
+var uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// checkRule applies rule to v and returns the failure message (without the field path).
+func checkRule(rule validationRule, v reflect.Value) (string, bool) {
+	switch rule.tag {
+	case "min", "max", "len", "gt", "gte", "lt", "lte":
+		size, unit, ok := validationSize(v)
+		if !ok {
+			return "cannot be checked with " + rule.tag, false
+		}
+		return compareSize(rule, size, unit)
+
+	case "oneof":
+		s := fmt.Sprint(v.Interface())
+		for _, opt := range rule.options {
+			if s == opt {
+				return "", true
+			}
+		}
+		return "must be one of [" + strings.Join(rule.options, " ") + "]", false
+	}
+
+	if v.Kind() != reflect.String {
+		return "must be a string to be checked with " + rule.tag, false
+	}
+	s := v.String()
+
+	switch rule.tag {
+	case "email":
+		addr, err := mail.ParseAddress(s)
+		if err != nil || addr.Address != s {
+			return "must be a valid email address", false
+		}
+	case "url":
+		u, err := url.ParseRequestURI(s)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return "must be a valid URL", false
+		}
+	case "uuid":
+		if !uuidRegex.MatchString(s) {
+			return "must be a valid UUID", false
+		}
+	case "alpha":
+		if s == "" || strings.IndexFunc(s, func(r rune) bool { return !unicode.IsLetter(r) }) >= 0 {
+			return "must contain only letters", false
+		}
+	case "alphanum":
+		if s == "" || strings.IndexFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) >= 0 {
+			return "must contain only letters and digits", false
+		}
+	case "numeric":
+		if s == "" || strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' }) >= 0 {
+			return "must contain only digits", false
+		}
+	}
+	return "", true
+}
+
+// validationSize returns the value compared by size rules and the unit used in messages.
+func validationSize(v reflect.Value) (float64, string, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), " characters", true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), " items", true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), "", true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), "", true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), "", true
+	}
+	return 0, "", false
+}
+
+// compareSize checks size against a min/max/len/gt/gte/lt/lte rule.
+func compareSize(rule validationRule, size float64, unit string) (string, bool) {
+	switch rule.tag {
+	case "min", "gte":
+		if size < rule.num {
+			return "must be at least " + rule.param + unit, false
+		}
+	case "max", "lte":
+		if size > rule.num {
+			return "must be at most " + rule.param + unit, false
+		}
+	case "len":
+		if size != rule.num {
+			return "must be exactly " + rule.param + unit, false
+		}
+	case "gt":
+		if size <= rule.num {
+			return "must be greater than " + rule.param + unit, false
+		}
+	case "lt":
+		if size >= rule.num {
+			return "must be less than " + rule.param + unit, false
+		}
+	}
+	return "", true
+}
@@ -0,0 +1,198 @@
+// Package quick provides a high-performance, minimalistic web framework for Go.
+//
+// This file contains **unit tests** for struct validation driven by `validate` tags.
+//
+// 📌 To run all unit tests, use:
+//
+//	$ go test -v ./...
+//	$ go test -v -run ^TestValidate
+package quick
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type validateAddress struct {
+	Zip string `json:"zip" validate:"required,numeric,len=5"`
+}
+
+type validateItem struct {
+	Name string `json:"name" validate:"required"`
+	Qty  int    `json:"qty" validate:"gte=1,lte=10"`
+}
+
+type validateUser struct {
+	Name    string           `json:"name" validate:"required,min=3,max=10"`
+	Email   string           `json:"email" validate:"required,email"`
+	Site    string           `json:"site,omitempty" validate:"omitempty,url"`
+	Role    string           `json:"role" validate:"oneof=admin user"`
+	ID      string           `json:"id" validate:"omitempty,uuid"`
+	Age     int              `json:"age" validate:"gt=0,lt=150"`
+	Tags    []string         `json:"tags" validate:"max=2"`
+	Address *validateAddress `json:"address" validate:"required"`
+	Items   []validateItem   `json:"items"`
+	secret  string           `validate:"required"`
+}
+
+// TestValidate verifies rule evaluation and field paths of nested values.
+//
+// Run with:
+//
+//	go test -v -run ^TestValidate$
+func TestValidate(t *testing.T) {
+	valid := validateUser{
+		Name:    "Jeff",
+		Email:   "jeff@example.com",
+		Site:    "https://example.com",
+		Role:    "admin",
+		ID:      "6f1c2d3e-4a5b-4c6d-8e9f-0a1b2c3d4e5f",
+		Age:     30,
+		Tags:    []string{"a"},
+		Address: &validateAddress{Zip: "12345"},
+		Items:   []validateItem{{Name: "book", Qty: 1}},
+	}
+	if err := Validate(&valid); err != nil {
+		t.Fatalf("expected valid struct, got %v", err)
+	}
+
+	invalid := validateUser{
+		Name:  "Jo",
+		Email: "not-an-email",
+		Site:  "example",
+		Role:  "root",
+		ID:    "123",
+		Tags:  []string{"a", "b", "c"},
+		Items: []validateItem{{Name: "pen", Qty: 1}, {Qty: 11}},
+	}
+	err := Validate(invalid)
+
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+
+	want := map[string]string{
+		"name":          "min",
+		"email":         "email",
+		"site":          "url",
+		"role":          "oneof",
+		"id":            "uuid",
+		"age":           "gt",
+		"tags":          "max",
+		"address":       "required",
+		"items[1].name": "required",
+		"items[1].qty":  "lte",
+	}
+	if len(verrs) != len(want) {
+		t.Fatalf("expected %d errors, got %d: %v", len(want), len(verrs), verrs)
+	}
+	for _, fe := range verrs {
+		if want[fe.Field] != fe.Tag {
+			t.Errorf("field %q: expected tag %q, got %q (%s)", fe.Field, want[fe.Field], fe.Tag, fe.Message)
+		}
+	}
+	if verrs[0].Message != "name must be at least 3 characters" {
+		t.Errorf("unexpected message: %q", verrs[0].Message)
+	}
+
+	nested := validateUser{Name: "Jeff", Email: "a@b.co", Role: "user", Age: 1, Address: &validateAddress{Zip: "12a"}}
+	err = Validate(&nested)
+	if err == nil || err.Error() != "address.zip must contain only digits; address.zip must be exactly 5 characters" {
+		t.Errorf("unexpected nested errors: %v", err)
+	}
+}
+
+// TestValidateInvalidTag verifies that malformed tags are reported as plain errors.
+//
+// Run with:
+//
+//	go test -v -run ^TestValidateInvalidTag
+func TestValidateInvalidTag(t *testing.T) {
+	type bad struct {
+		Name string `validate:"min=abc"`
+	}
+	err := Validate(bad{})
+	var verrs ValidationErrors
+	if err == nil || errors.As(err, &verrs) {
+		t.Fatalf("expected a tag error, got %v", err)
+	}
+
+	type unknown struct {
+		Name string `validate:"shiny"`
+	}
+	if err := Validate(&unknown{}); err == nil || !strings.Contains(err.Error(), `unknown rule "shiny"`) {
+		t.Errorf("expected unknown rule error, got %v", err)
+	}
+}
+
+// TestValidateBind verifies that Bind and BodyParser validate the decoded body and
+// that the default error handler answers 400, 415 and 422 problems.
+//
+// Run with:
+//
+//	go test -v -run ^TestValidateBind
+func TestValidateBind(t *testing.T) {
+	type payload struct {
+		Name  string `json:"name" validate:"required"`
+		Email string `json:"email" validate:"required,email"`
+	}
+
+	q := New()
+	q.Post("/bind", func(c *Ctx) error {
+		var p payload
+		if err := c.Bind(&p); err != nil {
+			return err
+		}
+		return c.Status(StatusCreated).JSON(p)
+	})
+	q.Post("/parser", func(c *Ctx) error {
+		var p payload
+		if err := c.BodyParser(&p); err != nil {
+			return err
+		}
+		return c.Status(StatusCreated).JSON(p)
+	})
+
+	tests := []struct {
+		contentType string
+		body        string
+		code        int
+	}{
+		{ContentTypeAppJSON, `{"name":"Jeff","email":"jeff@example.com"}`, StatusCreated},
+		{ContentTypeAppJSON, `{"name":"","email":"nope"}`, StatusUnprocessableEntity},
+		{ContentTypeAppJSON, `{"name":`, StatusBadRequest},
+		{"text/plain", `name=Jeff`, StatusUnsupportedMediaType},
+	}
+
+	for _, path := range []string{"/bind", "/parser"} {
+		for _, tt := range tests {
+			req := httptest.NewRequest(MethodPost, path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			rec := httptest.NewRecorder()
+			q.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Errorf("%s %s: expected %d, got %d (%s)", path, tt.body, tt.code, rec.Code, rec.Body.String())
+				continue
+			}
+			if tt.code != StatusUnprocessableEntity {
+				continue
+			}
+
+			var problem struct {
+				Status int          `json:"status"`
+				Errors []FieldError `json:"errors"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("%s: invalid problem body: %v", path, err)
+			}
+			if len(problem.Errors) != 2 || problem.Errors[0].Field != "name" || problem.Errors[1].Tag != "email" {
+				t.Errorf("%s: unexpected field errors: %+v", path, problem.Errors)
+			}
+		}
+	}
+}